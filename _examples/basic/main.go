@@ -40,6 +40,7 @@ func main() {
 	m.Get("/list", http.HandlerFunc(listHandler))
 	m.Get("/detail", http.HandlerFunc(detailHandler))
 	m.Get("/log", http.HandlerFunc(logHandler))
+	m.Get("/stats", http.HandlerFunc(statsHandler))
 	m.Post("/add", http.HandlerFunc(jobHandler))
 	m.Post("/sched", http.HandlerFunc(schedHandler))
 	<-m.Run(*port)
@@ -53,8 +54,9 @@ func listHandler(w http.ResponseWriter, r *http.Request) {
 		log.Printf("listHandler : err [%s]", err)
 	}
 
-	before := time.Now().UTC()
-	after := before.AddDate(0, 0, 1)
+	// jobs of the last day.
+	after := time.Now().UTC()
+	before := after.AddDate(0, 0, -1)
 
 	list, err := worm.Query(before, after, 100)
 	if err != nil {
@@ -141,6 +143,17 @@ func logHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func statsHandler(w http.ResponseWriter, r *http.Request) {
+	st, err := worm.Stats()
+	if err != nil {
+		http.Error(w, "can't retrieve stats", http.StatusInternalServerError)
+		return
+	}
+	if err := srest.JSON(w, st); err != nil {
+		log.Printf("statsHandler : render : err [%s]", err)
+	}
+}
+
 // ResStatus struct.
 type ResStatus struct {
 	ID     string `json:"id"`
@@ -0,0 +1,85 @@
+package worm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+)
+
+// ErrNotRunning is returned by Ack outside a running job or when the job
+// was already acked.
+var ErrNotRunning = errors.New("worm: no running job to ack")
+
+// ackKey is the context key of the ackState of the running job.
+type ackKey struct{}
+
+// ackState tracks the transactional ack of a run.
+type ackState struct {
+	jobID   string
+	logFile string
+	// tx is the transaction of the last ack, which may roll back.
+	tx *sql.Tx
+}
+
+// withAck adds to ctx the ack state of the run of the job.
+func withAck(ctx context.Context, jobID, logFile string) (context.Context, *ackState) {
+	ack := &ackState{jobID: jobID, logFile: logFile}
+	return context.WithValue(ctx, ackKey{}, ack), ack
+}
+
+// Ack records the success of the job running with ctx, the context a
+// ContextDoer receives, inside tx, so the writes of the Doer to the worm
+// database and the job completion commit or roll back together. tx must
+// be a transaction of the hub database, ended before RunContext returns.
+// The status RunContext returns is ignored once tx commits; when tx rolls
+// back the job finishes with it as usual, or the Doer acks again in a new
+// transaction. Returns ErrConflict when the hub lost the claim of the job.
+func (h *Worm) Ack(ctx context.Context, tx *sql.Tx) error {
+	ack, ok := ctx.Value(ackKey{}).(*ackState)
+	if !ok || ack.tx == tx || h.committed(ack) {
+		return ErrNotRunning
+	}
+	now := h.now()
+	res, err := tx.ExecContext(ctx, `
+		UPDATE worm
+		SET status=?,error='',log_file=?,finished_at=?,updated_at=?,claimed_by='',claimed_until=NULL
+		WHERE id=? AND claimed_by=? AND (cron<>'' OR finished_at IS NULL);
+	`, StatusOK, ack.logFile, now, now, ack.jobID, h.instanceID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n < 1 {
+		return ErrConflict
+	}
+	ack.tx = tx
+	return nil
+}
+
+// committed reports if the ack of the run committed: the ack transaction
+// may roll back after Ack succeeds. The log file identifies the run.
+func (h *Worm) committed(ack *ackState) bool {
+	if ack.tx == nil {
+		return false
+	}
+	var n int
+	o := <-h.waitc
+	err := h.dbGet(&n, `
+		SELECT COUNT(*) FROM worm WHERE id=? AND log_file=? AND status=? AND claimed_by='';
+	`, ack.jobID, ack.logFile, StatusOK)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("committed : select : err [%s] job id [%s]", err, ack.jobID)
+		return false
+	}
+	return n > 0
+}
+
+// Ack _
+func Ack(ctx context.Context, tx *sql.Tx) error {
+	return defaultWorm.Ack(ctx, tx)
+}
@@ -0,0 +1,184 @@
+package worm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// ledgerDoer writes its data to the ledger table and acks the job in the
+// same transaction, committed unless the data is "rollback".
+type ledgerDoer struct {
+	h *Worm
+}
+
+func (d *ledgerDoer) Name() string {
+	return "ledger"
+}
+
+func (d *ledgerDoer) Run(data []byte, w io.Writer) (int, error) {
+	return d.RunContext(context.Background(), data, w)
+}
+
+func (d *ledgerDoer) RunContext(ctx context.Context, data []byte, w io.Writer) (int, error) {
+	tx, err := d.h.Db.Begin()
+	if err != nil {
+		return 2, err
+	}
+	if _, err := tx.Exec(`INSERT INTO ledger (entry) VALUES (?);`, string(data)); err != nil {
+		tx.Rollback()
+		return 2, err
+	}
+	if err := d.h.Ack(ctx, tx); err != nil {
+		tx.Rollback()
+		return 2, err
+	}
+	if string(data) == "rollback" {
+		tx.Rollback()
+		return 3, errors.New("rolled back")
+	}
+	if err := tx.Commit(); err != nil {
+		return 2, err
+	}
+	// ignored, the job is acked.
+	return 3, errors.New("after ack")
+}
+
+func TestAck(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	h.Db.MustExec(`CREATE TABLE ledger (entry TEXT);`)
+	h.MustRegister("ledger", &ledgerDoer{h: h})
+
+	committed, err := h.Queue("ledger", []byte("commit"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rolled, err := h.Queue("ledger", []byte("rollback"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(time.Minute))
+
+	var entries []string
+	if err := h.Db.Select(&entries, `SELECT entry FROM ledger;`); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0] != "commit" {
+		t.Fatalf("ledger : got [%v]", entries)
+	}
+	job, err := h.Detail(committed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != StatusOK || job.Error != "" || job.Attempts != 1 || len(job.LogFile) < 1 {
+		t.Fatalf("committed : got [%+v]", job)
+	}
+	job, err = h.Detail(rolled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != 3 || job.Error != "rolled back" {
+		t.Fatalf("rolled back : got status [%d] error [%s]", job.Status, job.Error)
+	}
+
+	tx, err := h.Db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if err := h.Ack(context.Background(), tx); err != ErrNotRunning {
+		t.Fatalf("outside a job : expected ErrNotRunning got [%v]", err)
+	}
+}
+
+// rollbackDoer acks the job and rolls the ack back. It then acks again in
+// a new transaction when the data is "again", or else loses the claim of
+// the job to another hub when the data is "stolen".
+type rollbackDoer struct {
+	h *Worm
+}
+
+func (d *rollbackDoer) Name() string {
+	return "rollback"
+}
+
+func (d *rollbackDoer) Run(data []byte, w io.Writer) (int, error) {
+	return d.RunContext(context.Background(), data, w)
+}
+
+func (d *rollbackDoer) RunContext(ctx context.Context, data []byte, w io.Writer) (int, error) {
+	tx, err := d.h.Db.Begin()
+	if err != nil {
+		return 2, err
+	}
+	if err := d.h.Ack(ctx, tx); err != nil {
+		tx.Rollback()
+		return 2, err
+	}
+	tx.Rollback()
+	switch string(data) {
+	case "again":
+		tx, err := d.h.Db.Begin()
+		if err != nil {
+			return 2, err
+		}
+		if err := d.h.Ack(ctx, tx); err != nil {
+			tx.Rollback()
+			return 2, err
+		}
+		if err := tx.Commit(); err != nil {
+			return 2, err
+		}
+	case "stolen":
+		info, _ := FromContext(ctx)
+		if _, err := d.h.Db.Exec(`UPDATE worm SET claimed_by='other' WHERE id=?;`, info.ID); err != nil {
+			return 2, err
+		}
+	}
+	return 3, errors.New("rolled back")
+}
+
+func TestAckRollback(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	var events []Event
+	h := newTestWorm(t, WithManualTick(start), WithEventHandler(func(e Event) {
+		events = append(events, e)
+	}))
+	defer closeTestWorm(t, h)
+	h.MustRegister("rollback", &rollbackDoer{h: h})
+
+	again, err := h.Queue("rollback", []byte("again"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stolen, err := h.Queue("rollback", []byte("stolen"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(time.Minute))
+
+	job, err := h.Detail(again)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != StatusOK || job.Error != "" {
+		t.Fatalf("acked again : got status [%d] error [%s]", job.Status, job.Error)
+	}
+	// the rolled back ack doesn't finish the job of the other hub.
+	for _, e := range events {
+		if e.JobID == stolen && (e.Type == EventSucceeded || e.Type == EventFailed) {
+			t.Fatalf("stolen : got event [%+v]", e)
+		}
+	}
+	job, err = h.Detail(stolen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != StatusStart {
+		t.Fatalf("stolen : got status [%d]", job.Status)
+	}
+}
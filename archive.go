@@ -0,0 +1,84 @@
+package worm
+
+import (
+	"archive/zip"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// archivedRun is a run log included by ArchiveLogs.
+type archivedRun struct {
+	ID         int64      `db:"id"`
+	JobID      string     `db:"job_id"`
+	Worker     string     `db:"worker_name"`
+	FinishedAt *time.Time `db:"finished_at"`
+	LogFile    string     `db:"log_file"`
+}
+
+// ArchiveLogs writes to w a zip archive of the logs of the latest limit runs
+// of the jobs matching filter, one entry per run named
+// worker/jobID/runID.log. Logs already removed by retention are skipped.
+// Filter sorts don't apply. It returns the number of logs archived.
+func (h *Worm) ArchiveLogs(w io.Writer, filter JobFilter, limit int) (int, error) {
+	where, args := filter.where()
+	var runs []*archivedRun
+	o := <-h.waitc
+	err := h.dbSelect(&runs, `
+		SELECT r.id,r.job_id,w.worker_name,r.finished_at,r.log_file
+		FROM worm_run AS r
+		JOIN worm AS w ON w.id=r.job_id
+		WHERE IFNULL(r.log_file,'')<>'' AND r.job_id IN (SELECT id FROM worm WHERE `+where+`)
+		ORDER BY r.id DESC LIMIT ?;
+	`, append(args, limit)...)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("ArchiveLogs : select : err [%s]", err)
+		return 0, err
+	}
+	zw := zip.NewWriter(w)
+	var n int
+	for _, run := range runs {
+		ok, err := archiveLog(zw, run)
+		if err != nil {
+			log.Printf("ArchiveLogs : write : err [%s] job id [%s]", err, run.JobID)
+			return n, err
+		}
+		if ok {
+			n++
+		}
+	}
+	return n, zw.Close()
+}
+
+// archiveLog adds the log of the run to zw, false when the file is gone.
+func archiveLog(zw *zip.Writer, run *archivedRun) (bool, error) {
+	f, err := os.Open(run.LogFile)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	fh := &zip.FileHeader{
+		Name:   run.Worker + "/" + run.JobID + "/" + strconv.FormatInt(run.ID, 10) + ".log",
+		Method: zip.Deflate,
+	}
+	if run.FinishedAt != nil {
+		fh.Modified = *run.FinishedAt
+	}
+	fw, err := zw.CreateHeader(fh)
+	if err != nil {
+		return false, err
+	}
+	_, err = io.Copy(fw, f)
+	return err == nil, err
+}
+
+// ArchiveLogs _
+func ArchiveLogs(w io.Writer, filter JobFilter, limit int) (int, error) {
+	return defaultWorm.ArchiveLogs(w, filter, limit)
+}
@@ -0,0 +1,77 @@
+package worm
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestArchiveLogs(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	h.MustRegister("echo", &echoDoer{})
+
+	var ids []string
+	for _, data := range []string{"first", "second", "gone"} {
+		jobID, err := h.Queue("echo", []byte(data), Group("incident"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, jobID)
+	}
+	if _, err := h.Queue("echo", []byte("other")); err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start)
+	runs, err := h.Runs(ids[2])
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("runs : got [%v] err [%v]", runs, err)
+	}
+	if err := os.Remove(runs[0].LogFile); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	n, err := h.ArchiveLogs(&buf, JobFilter{Group: "incident"}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 logs got [%d]", n)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[f.Name] = string(b)
+	}
+	for i, data := range []string{"first", "second"} {
+		runs, err := h.Runs(ids[i])
+		if err != nil || len(runs) != 1 {
+			t.Fatalf("runs : got [%v] err [%v]", runs, err)
+		}
+		name := "echo/" + ids[i] + "/" + strconv.FormatInt(runs[0].ID, 10) + ".log"
+		if got[name] != traceHeader(t, h, ids[i])+data {
+			t.Errorf("%s : expected [%s] got [%s]", name, data, got[name])
+		}
+	}
+	if len(got) != 2 {
+		t.Errorf("expected 2 entries got [%v]", got)
+	}
+}
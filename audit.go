@@ -0,0 +1,39 @@
+package worm
+
+import (
+	"log"
+	"time"
+)
+
+// AuditEntry is a manual status change of a job.
+type AuditEntry struct {
+	Status int `db:"status" json:"status"`
+	// Reason says who changed the status and why.
+	Reason    string    `db:"reason" json:"reason"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// SetStatus overrides the status of the job, e.g. to mark a stuck job as
+// resolved after fixing things out of band. reason should say who and why,
+// it is kept in the job audit. StatusStart makes the job pending again, other
+// statuses finish it and run its completion hooks.
+func (h *Worm) SetStatus(jobID string, status int, reason string) error {
+	updated, err := h.updateStatuses([]StatusUpdate{{JobID: jobID, Status: status, Reason: reason}})
+	if err == nil && !updated[0] {
+		err = ErrNotFound
+	}
+	if err != nil {
+		log.Printf("SetStatus : err [%s] job id [%s]", err, jobID)
+		return err
+	}
+	log.Printf("SetStatus : job id [%s] status [%d] reason [%s]", jobID, status, reason)
+	if status != StatusStart {
+		h.finish(jobID, status)
+	}
+	return nil
+}
+
+// SetStatus _
+func SetStatus(jobID string, status int, reason string) error {
+	return defaultWorm.SetStatus(jobID, status, reason)
+}
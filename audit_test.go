@@ -0,0 +1,42 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetStatus(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	h.MustRegister("ok", &testDoer{name: "ok"})
+	notify := &dataDoer{}
+	h.MustRegister("notify", notify)
+
+	jobID, err := h.Queue("ok", nil, OnSuccess("notify", []byte("done")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.SetStatus(jobID, StatusOK, "ana: imported by hand"); err != nil {
+		t.Fatal(err)
+	}
+	job, err := h.Detail(jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != StatusOK || len(job.Audit) != 1 || job.Audit[0].Reason != "ana: imported by hand" {
+		t.Fatalf("detail : got [%+v]", job)
+	}
+	// completion hooks run on manual success too.
+	h.Tick(start.Add(time.Minute))
+	if string(notify.data) != "done" {
+		t.Fatalf("continuation : got [%s]", notify.data)
+	}
+
+	if err := h.SetStatus("none", StatusOK, "x"); err == nil {
+		t.Errorf("unknown job : expected error")
+	}
+	if err := h.SetStatus(jobID, StatusWaiting, "x"); err != ErrConflict {
+		t.Errorf("waiting : expected ErrConflict got [%v]", err)
+	}
+}
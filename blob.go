@@ -0,0 +1,103 @@
+package worm
+
+import (
+	"database/sql"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// BlobStore stores job payloads bigger than the hub blob threshold outside
+// the database.
+type BlobStore interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+}
+
+// FileBlobStore is a BlobStore keeping one file per payload in a directory.
+type FileBlobStore struct {
+	dir string
+}
+
+// NewFileBlobStore returns a FileBlobStore on dir, creating it if needed.
+func NewFileBlobStore(dir string) (*FileBlobStore, error) {
+	if len(dir) < 1 {
+		return nil, errors.New("blob directory not set")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileBlobStore{dir: dir}, nil
+}
+
+// path returns the file of key.
+func (s *FileBlobStore) path(key string) string {
+	return filepath.Join(s.dir, filepath.Base(key)+".blob")
+}
+
+// Put implements BlobStore.
+func (s *FileBlobStore) Put(key string, data []byte) error {
+	return ioutil.WriteFile(s.path(key), data, 0600)
+}
+
+// Get implements BlobStore.
+func (s *FileBlobStore) Get(key string) ([]byte, error) {
+	return ioutil.ReadFile(s.path(key))
+}
+
+// Delete implements BlobStore.
+func (s *FileBlobStore) Delete(key string) error {
+	err := os.Remove(s.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// newBlobKey returns a blob store key for a payload of the job. Keys are
+// never reused, so a failed write never overwrites a stored payload.
+func newBlobKey(jobID string) string {
+	return jobID + "-" + uuid.NewV4().String()
+}
+
+// blobbed reports whether data goes to the blob store.
+func (h *Worm) blobbed(data []byte) bool {
+	return h.blobs != nil && len(data) > h.blobThreshold
+}
+
+// payload loads the job data from the database or the blob store. With a
+// signing key it returns ErrTampered for data failing the signature check.
+func (h *Worm) payload(jobID string) ([]byte, error) {
+	var row struct {
+		Data      []byte         `db:"data"`
+		BlobKey   sql.NullString `db:"blob_key"`
+		Worker    string         `db:"worker_name"`
+		Signature sql.NullString `db:"signature"`
+	}
+	o := <-h.waitc
+	err := h.dbGet(&row, `SELECT data, blob_key, worker_name, signature FROM worm WHERE id=?;`, jobID)
+	h.waitc <- o
+	if err != nil {
+		return nil, err
+	}
+	data := row.Data
+	if len(row.BlobKey.String) > 0 {
+		if h.blobs == nil {
+			return nil, errors.New("worm: job payload in blob store but no blob store set")
+		}
+		data, err = h.blobs.Get(row.BlobKey.String)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(h.signingKey) > 0 {
+		if err := h.verify(jobID, row.Worker, data, row.Signature.String); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,89 @@
+package worm
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBlobStore(t *testing.T) {
+	h := newTestWorm(t)
+	defer closeTestWorm(t, h)
+	store, err := NewFileBlobStore(filepath.Join(h.logDir, "blobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	WithBlobStore(store, 4)(h)
+	h.MustRegister("a", &testDoer{name: "a"})
+
+	for _, data := range []string{"tiny", "a payload over the threshold"} {
+		_, jobID, err := h.store("a", []byte(data), &jobOptions{})
+		if err != nil {
+			t.Fatalf("store : err [%s]", err)
+		}
+		job, err := h.Detail(jobID)
+		if err != nil {
+			t.Fatalf("detail : err [%s]", err)
+		}
+		if blobbed := len(job.BlobKey) > 0; blobbed != h.blobbed([]byte(data)) {
+			t.Errorf("%q : blob key [%s]", data, job.BlobKey)
+		}
+		b, err := h.payload(jobID)
+		if err != nil || !bytes.Equal(b, []byte(data)) {
+			t.Errorf("%q : payload got [%q] err [%v]", data, b, err)
+		}
+	}
+}
+
+func TestBlobKeys(t *testing.T) {
+	h := newTestWorm(t)
+	defer closeTestWorm(t, h)
+	store, err := NewFileBlobStore(filepath.Join(h.logDir, "blobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	WithBlobStore(store, 4)(h)
+	h.MustRegister("a", &testDoer{name: "a"})
+
+	first := []byte("the first payload")
+	_, jobID, err := h.store("a", first, &jobOptions{id: "taken"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a taken job ID fails without touching the stored payload.
+	if _, _, err := h.store("a", []byte("the second payload"), &jobOptions{id: "taken"}); err == nil {
+		t.Fatal("expected taken job ID error")
+	}
+	if b, err := h.payload(jobID); err != nil || !bytes.Equal(b, first) {
+		t.Fatalf("taken : payload got [%q] err [%v]", b, err)
+	}
+
+	// a schedule update stores the payload under a new key and removes the
+	// previous one.
+	schedID, err := h.Sched("a", first, "@hourly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := h.Detail(schedID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := []byte("the updated payload")
+	if err := h.UpdateSchedule(schedID, next, "@daily"); err != nil {
+		t.Fatal(err)
+	}
+	after, err := h.Detail(schedID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.BlobKey == before.BlobKey {
+		t.Fatalf("update : expected new blob key got [%s]", after.BlobKey)
+	}
+	if b, err := h.payload(schedID); err != nil || !bytes.Equal(b, next) {
+		t.Fatalf("update : payload got [%q] err [%v]", b, err)
+	}
+	if _, err := store.Get(before.BlobKey); !os.IsNotExist(err) {
+		t.Fatalf("update : expected previous blob removed got err [%v]", err)
+	}
+}
@@ -0,0 +1,132 @@
+package worm
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+// dateLayout is the layout of the Calendar days.
+const dateLayout = "2006-01-02"
+
+// maxSkipped bounds the consecutive firings a calendar skips before the
+// schedule is considered ended.
+const maxSkipped = 1000
+
+// Calendar is a set of holidays. Schedules with SkipHolidays don't fire on
+// them.
+type Calendar struct {
+	loc  *time.Location
+	days map[string]bool
+}
+
+// NewCalendar returns a calendar with the days of dates as holidays. Firings
+// are matched by their UTC date unless In sets a location.
+func NewCalendar(dates ...time.Time) *Calendar {
+	c := &Calendar{
+		loc:  time.UTC,
+		days: make(map[string]bool),
+	}
+	for _, d := range dates {
+		c.Add(d)
+	}
+	return c
+}
+
+// ParseICal returns a calendar with the days of the VEVENTs of an iCalendar
+// feed, from DTSTART until DTEND exclusive. Fetch public holiday feeds with
+// http.Get and pass the body.
+func ParseICal(r io.Reader) (*Calendar, error) {
+	c := NewCalendar()
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		// folded lines continue the previous one.
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var start, end time.Time
+	var inEvent bool
+	for _, line := range lines {
+		name, value := splitProp(line)
+		upper := strings.ToUpper(name)
+		switch {
+		case upper == "BEGIN" && strings.ToUpper(value) == "VEVENT":
+			inEvent = true
+			start, end = time.Time{}, time.Time{}
+		case upper == "END" && strings.ToUpper(value) == "VEVENT":
+			inEvent = false
+			if start.IsZero() {
+				continue
+			}
+			c.Add(start)
+			for d := start.AddDate(0, 0, 1); d.Before(end); d = d.AddDate(0, 0, 1) {
+				c.Add(d)
+			}
+		case inEvent && strings.HasPrefix(upper, "DTSTART"):
+			t, err := parseICalTime(name, value)
+			if err != nil {
+				return nil, err
+			}
+			start = t
+		case inEvent && strings.HasPrefix(upper, "DTEND"):
+			t, err := parseICalTime(name, value)
+			if err != nil {
+				return nil, err
+			}
+			end = t
+		}
+	}
+	return c, nil
+}
+
+// Add marks the day of t as holiday.
+func (c *Calendar) Add(t time.Time) {
+	c.days[t.Format(dateLayout)] = true
+}
+
+// In matches firings by their date in loc, like the local business day.
+func (c *Calendar) In(loc *time.Location) *Calendar {
+	c.loc = loc
+	return c
+}
+
+// Holiday reports if t falls on a holiday.
+func (c *Calendar) Holiday(t time.Time) bool {
+	return c.days[t.In(c.loc).Format(dateLayout)]
+}
+
+// SkipHolidays makes the schedule skip its firings on the holidays of c.
+func SkipHolidays(c *Calendar) JobOption {
+	return func(o *jobOptions) {
+		o.calendar = c
+	}
+}
+
+// calendared is a cron.Schedule skipping the holidays of a calendar.
+type calendared struct {
+	schedule cron.Schedule
+	calendar *Calendar
+}
+
+// Next implements cron.Schedule.
+func (s calendared) Next(t time.Time) time.Time {
+	for i := 0; i < maxSkipped; i++ {
+		t = s.schedule.Next(t)
+		if t.IsZero() || !s.calendar.Holiday(t) {
+			return t
+		}
+	}
+	return time.Time{}
+}
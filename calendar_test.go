@@ -0,0 +1,65 @@
+package worm
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const holidayFeed = "BEGIN:VCALENDAR\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Christmas\r\n" +
+	"DTSTART;VALUE=DATE:20161225\r\n" +
+	"DTEND;VALUE=DATE:20161227\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:New Year\r\n" +
+	"DTSTART;VALUE=DATE:2017\r\n" +
+	" 0101\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICal(t *testing.T) {
+	c, err := ParseICal(strings.NewReader(holidayFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		day     time.Time
+		holiday bool
+	}{
+		{time.Date(2016, 12, 24, 12, 0, 0, 0, time.UTC), false},
+		{time.Date(2016, 12, 25, 12, 0, 0, 0, time.UTC), true},
+		{time.Date(2016, 12, 26, 12, 0, 0, 0, time.UTC), true},
+		{time.Date(2016, 12, 27, 12, 0, 0, 0, time.UTC), false},
+		{time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC), true},
+	} {
+		if got := c.Holiday(tc.day); got != tc.holiday {
+			t.Fatalf("holiday [%s] : expected [%v] got [%v]", tc.day, tc.holiday, got)
+		}
+	}
+}
+
+func TestSkipHolidays(t *testing.T) {
+	start := time.Date(2016, 12, 22, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+
+	d := &countDoer{}
+	h.MustRegister("count", d)
+	cal := NewCalendar(time.Date(2016, 12, 23, 0, 0, 0, 0, time.UTC))
+	if _, err := h.Sched("count", nil, "0 0 6 * * *", SkipHolidays(cal)); err != nil {
+		t.Fatal(err)
+	}
+	// 22 and 24 fire, 23 is skipped.
+	if n, _ := h.Tick(start.Add(3 * 24 * time.Hour)); n != 2 {
+		t.Fatalf("tick : expected 2 firings got [%d]", n)
+	}
+
+	// holidays are matched by their date in the calendar location.
+	loc := time.FixedZone("UTC-8", -8*3600)
+	cal = NewCalendar(time.Date(2016, 12, 23, 0, 0, 0, 0, loc)).In(loc)
+	if !cal.Holiday(time.Date(2016, 12, 24, 6, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected 24 06:00 UTC on the 23 holiday in UTC-8")
+	}
+}
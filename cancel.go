@@ -0,0 +1,36 @@
+package worm
+
+import "log"
+
+// CancelWhere cancels the pending single execution jobs matching filter in
+// one statement. Running jobs are not canceled. Canceled jobs finish with
+// StatusCanceled and no completion hooks run. Returns the number of canceled
+// jobs.
+func (h *Worm) CancelWhere(filter JobFilter) (int, error) {
+	where, args := filter.where()
+	now := h.now()
+	args = append([]interface{}{StatusCanceled, now, now, StatusWaiting, StatusStart, now}, args...)
+	o := <-h.waitc
+	res, err := h.dbExec(`
+		UPDATE worm SET status=?,error='canceled',finished_at=?,updated_at=?
+		WHERE cron='' AND finished_at IS NULL AND status IN (?,?)
+		AND (claimed_until IS NULL OR claimed_until<?)
+		AND `+where+`;
+	`, args...)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("CancelWhere : err [%s]", err)
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("CancelWhere : canceled [%d] jobs filter [%+v]", n, filter)
+	return int(n), nil
+}
+
+// CancelWhere _
+func CancelWhere(filter JobFilter) (int, error) {
+	return defaultWorm.CancelWhere(filter)
+}
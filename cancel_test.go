@@ -0,0 +1,45 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCancelWhere(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	d := &countDoer{}
+	h.MustRegister("count", d)
+	h.MustRegister("other", &testDoer{name: "other"})
+
+	for i := 0; i < 3; i++ {
+		h.Queue("count", nil, Group("bad"))
+	}
+	keep, _ := h.Queue("count", nil)
+	h.Queue("other", nil, Group("bad"))
+
+	n, err := h.CancelWhere(JobFilter{Worker: "count", Group: "bad"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Fatalf("expected 3 canceled got [%d]", n)
+	}
+	h.Tick(start.Add(time.Minute))
+	if d.runs != 1 {
+		t.Fatalf("runs : expected 1 got [%d]", d.runs)
+	}
+	if job, _ := h.Detail(keep); job.Status != StatusOK {
+		t.Fatalf("kept job : got [%+v]", job)
+	}
+	g, _ := h.GroupStatus("bad")
+	if g.Succeeded != 1 || g.Failed != 3 {
+		t.Fatalf("group : got [%+v]", g)
+	}
+
+	// finished jobs are not canceled.
+	if n, _ := h.CancelWhere(JobFilter{Worker: "count"}); n != 0 {
+		t.Fatalf("finished : expected 0 canceled got [%d]", n)
+	}
+}
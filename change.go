@@ -0,0 +1,82 @@
+package worm
+
+import (
+	"log"
+	"time"
+)
+
+// Change kinds.
+const (
+	// ChangeQueued is recorded when a job is stored.
+	ChangeQueued = "queued"
+	// ChangeStatus is recorded when the status or result of a job changes:
+	// runs, retries, cancels and manual status changes.
+	ChangeStatus = "status"
+	// ChangeDeleted is recorded when a job is deleted.
+	ChangeDeleted = "deleted"
+	// ChangeDisabled and ChangeEnabled are recorded when a recurring job is
+	// disabled or enabled.
+	ChangeDisabled = "disabled"
+	ChangeEnabled  = "enabled"
+)
+
+// Change is a job state transition of the change feed.
+type Change struct {
+	// Seq orders the changes. It only grows, even across TrimChanges.
+	Seq    int64  `db:"seq" json:"seq"`
+	JobID  string `db:"job_id" json:"job_id"`
+	Worker string `db:"worker_name" json:"worker_name"`
+	Kind   string `db:"kind" json:"kind"`
+	// Status and State are the ones of the job after the change.
+	Status     int        `db:"status" json:"status"`
+	State      string     `db:"-" json:"state"`
+	Error      string     `db:"error" json:"error,omitempty"`
+	FinishedAt *time.Time `db:"finished_at" json:"finished_at,omitempty"`
+	ChangedAt  *time.Time `db:"changed_at" json:"changed_at"`
+}
+
+// ReadChanges returns up to limit job state transitions recorded after the
+// change seq since, oldest first. The feed is recorded by the database, in
+// the transaction of every change, so replicas of the job states can follow
+// it passing the Seq of the last change read. Purged jobs are not recorded.
+func (h *Worm) ReadChanges(since int64, limit int) ([]*Change, error) {
+	var list []*Change
+	o := <-h.waitc
+	err := h.dbSelect(&list, `
+		SELECT seq,job_id,worker_name,kind,status,error,finished_at,changed_at
+		FROM worm_change WHERE seq>? ORDER BY seq LIMIT ?;
+	`, since, limit)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("ReadChanges : err [%s]", err)
+		return nil, err
+	}
+	for _, c := range list {
+		c.State = jobState(c.Status, c.FinishedAt)
+	}
+	return list, nil
+}
+
+// TrimChanges removes the changes up to seq, once every reader followed
+// them. Returns the number of changes removed.
+func (h *Worm) TrimChanges(seq int64) (int, error) {
+	o := <-h.waitc
+	res, err := h.dbExec(`DELETE FROM worm_change WHERE seq<=?;`, seq)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("TrimChanges : err [%s]", err)
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// ReadChanges _
+func ReadChanges(since int64, limit int) ([]*Change, error) {
+	return defaultWorm.ReadChanges(since, limit)
+}
+
+// TrimChanges _
+func TrimChanges(seq int64) (int, error) {
+	return defaultWorm.TrimChanges(seq)
+}
@@ -0,0 +1,84 @@
+package worm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReadChanges(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	h.MustRegister("ok", &testDoer{name: "ok", status: StatusOK})
+	h.MustRegister("fail", &testDoer{name: "fail", status: 2, err: errors.New("boom")})
+
+	okID, err := h.Queue("ok", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failID, err := h.Queue("fail", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start)
+	if err := h.Retry(failID); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Delete(okID); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := h.ReadChanges(0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for i, c := range list {
+		if i > 0 && c.Seq <= list[i-1].Seq {
+			t.Fatalf("unordered : got [%d] after [%d]", c.Seq, list[i-1].Seq)
+		}
+		if c.ChangedAt == nil {
+			t.Fatalf("change time : got [%+v]", c)
+		}
+		who := "ok"
+		if c.JobID == failID {
+			who = "fail"
+		}
+		got = append(got, who+" "+c.Kind+" "+c.State)
+	}
+	expected := []string{
+		"ok queued pending",
+		"fail queued pending",
+		"ok status succeeded",
+		"fail status failed",
+		"fail status pending",
+		"ok deleted succeeded",
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected [%v] got [%v]", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expected [%v] got [%v]", expected, got)
+		}
+	}
+	if list[3].Error != "boom" {
+		t.Errorf("error : got [%s]", list[3].Error)
+	}
+
+	since := list[3].Seq
+	if more, err := h.ReadChanges(since, 100); err != nil || len(more) != 2 {
+		t.Fatalf("since : got [%v] err [%v]", more, err)
+	}
+	if n, err := h.TrimChanges(since); err != nil || n != 4 {
+		t.Fatalf("trim : got [%d] err [%v]", n, err)
+	}
+	if _, err := h.CancelWhere(JobFilter{ID: failID}); err != nil {
+		t.Fatal(err)
+	}
+	more, err := h.ReadChanges(since, 100)
+	if err != nil || len(more) != 3 || more[2].Seq <= list[5].Seq {
+		t.Fatalf("after trim : got [%v] err [%v]", more, err)
+	}
+}
@@ -0,0 +1,106 @@
+package worm
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// defaultInstanceID returns hostname:pid:random so hubs sharing a database
+// never collide.
+func defaultInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "worm"
+	}
+	return fmt.Sprintf("%s:%d:%s", host, os.Getpid(), uuid.NewV4().String()[:8])
+}
+
+// claim takes the lease of the job for this hub. Returns false when the job
+// is already claimed by another hub, deleted, disabled, expired, its worker
+// is paused, its semaphore is full or, for single executions, finished.
+func (h *Worm) claim(jobID string) (bool, error) {
+	now := h.now()
+	o := <-h.waitc
+	res, err := h.dbExec(`
+		UPDATE worm SET claimed_by=?,claimed_until=?
+		WHERE id=? AND (cron<>'' OR finished_at IS NULL) AND status<>?
+		AND deleted_at IS NULL AND disabled_at IS NULL
+		AND (expires_at IS NULL OR expires_at>?)
+		AND (claimed_until IS NULL OR claimed_until<?)
+		AND worker_name NOT IN (
+			SELECT worker_name FROM worm_paused
+			WHERE paused_until IS NULL OR paused_until>?
+		)
+		AND (IFNULL(semaphore,'')='' OR semaphore_max>(
+			SELECT COUNT(*) FROM worm AS holder
+			WHERE holder.semaphore=worm.semaphore AND holder.claimed_until>=?
+		));
+	`, h.instanceID, now.Add(h.claimFor()), jobID, StatusWaiting, now, now, now, now)
+	h.waitc <- o
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// claimFor returns how long a claim lasts without renewal.
+func (h *Worm) claimFor() time.Duration {
+	if h.visibility > 0 {
+		return h.visibility
+	}
+	return h.lease
+}
+
+// release drops the lease of the job so other hubs can claim it.
+func (h *Worm) release(jobID string) {
+	o := <-h.waitc
+	_, err := h.dbExec(`
+		UPDATE worm SET claimed_by='',claimed_until=NULL
+		WHERE id=? AND claimed_by=?;
+	`, jobID, h.instanceID)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("release : err [%s] job id [%s]", err, jobID)
+	}
+}
+
+// keepLease extends the lease of the job while it runs. The returned func
+// stops the renewals. In visibility timeout mode leases are never extended.
+func (h *Worm) keepLease(jobID string) func() {
+	if h.visibility > 0 {
+		return func() {}
+	}
+	donec := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(h.lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-donec:
+				return
+			case <-ticker.C:
+			}
+			now := h.now()
+			o := <-h.waitc
+			_, err := h.dbExec(`
+				UPDATE worm SET claimed_until=?
+				WHERE id=? AND claimed_by=?;
+			`, now.Add(h.lease), jobID, h.instanceID)
+			h.waitc <- o
+			if err != nil {
+				log.Printf("keepLease : err [%s] job id [%s]", err, jobID)
+			}
+		}
+	}()
+	return func() {
+		close(donec)
+	}
+}
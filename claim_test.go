@@ -0,0 +1,64 @@
+package worm
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClaim(t *testing.T) {
+	h := newTestWorm(t)
+	defer closeTestWorm(t, h)
+	h.lease = 50 * time.Millisecond
+
+	other, err := New(filepath.Join(h.logDir, "worm.db"), h.logDir, WithInstanceID("other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+
+	h.MustRegister("a", &testDoer{name: "a"})
+	_, jobID, err := h.store("a", []byte("{}"), &jobOptions{})
+	if err != nil {
+		t.Fatalf("store : err [%s]", err)
+	}
+
+	ok, err := h.claim(jobID)
+	if err != nil || !ok {
+		t.Fatalf("claim : expected ok got [%v] err [%v]", ok, err)
+	}
+	ok, err = other.claim(jobID)
+	if err != nil || ok {
+		t.Fatalf("claim other : expected claimed got [%v] err [%v]", ok, err)
+	}
+
+	time.Sleep(2 * h.lease)
+	ok, err = other.claim(jobID)
+	if err != nil || !ok {
+		t.Fatalf("claim expired : expected ok got [%v] err [%v]", ok, err)
+	}
+}
+
+func TestVisibilityTimeout(t *testing.T) {
+	h := newTestWorm(t)
+	defer closeTestWorm(t, h)
+	h.visibility = 50 * time.Millisecond
+	h.MustRegister("a", &testDoer{name: "a"})
+	_, jobID, err := h.store("a", []byte("{}"), &jobOptions{})
+	if err != nil {
+		t.Fatalf("store : err [%s]", err)
+	}
+
+	if ok, err := h.claim(jobID); err != nil || !ok {
+		t.Fatalf("claim : expected ok got [%v] err [%v]", ok, err)
+	}
+	stop := h.keepLease(jobID)
+	defer stop()
+	if ok, _ := h.claim(jobID); ok {
+		t.Fatalf("claim : expected invisible job")
+	}
+	time.Sleep(2 * h.visibility)
+	if ok, err := h.claim(jobID); err != nil || !ok {
+		t.Fatalf("claim : expected redelivery got [%v] err [%v]", ok, err)
+	}
+}
@@ -0,0 +1,55 @@
+package worm
+
+import "time"
+
+// WithClock makes the hub take the current time from now instead of the
+// system clock for every time it stores and schedules against, e.g. to
+// share a time source with the application. WithManualTick takes precedence.
+//
+// Whatever the clock location, times are stored in UTC: created_at,
+// updated_at, run_at and the other columns sort and compare as the times
+// they are. Times passed to the hub are converted to UTC and the ones it
+// returns are UTC; Local converts them for display.
+func WithClock(now func() time.Time) Option {
+	return func(h *Worm) {
+		h.clock = now
+	}
+}
+
+// WithLocation sets the location Local converts times to. Default
+// time.Local.
+func WithLocation(loc *time.Location) Option {
+	return func(h *Worm) {
+		if loc != nil {
+			h.location = loc
+		}
+	}
+}
+
+// Local returns t, a time stored by worm, in the location of the hub for
+// display.
+func (h *Worm) Local(t time.Time) time.Time {
+	if h.location == nil {
+		return t.Local()
+	}
+	return t.In(h.location)
+}
+
+// FormatLocal formats t in the location of the hub with layout, empty for
+// zero times.
+func (h *Worm) FormatLocal(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return h.Local(t).Format(layout)
+}
+
+// Local _
+func Local(t time.Time) time.Time {
+	return defaultWorm.Local(t)
+}
+
+// FormatLocal _
+func FormatLocal(t time.Time, layout string) string {
+	return defaultWorm.FormatLocal(t, layout)
+}
@@ -0,0 +1,50 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	zone := time.FixedZone("UTC+5", 5*60*60)
+	now := time.Date(2016, 1, 1, 10, 0, 0, 0, zone)
+	h := newTestWorm(t, WithClock(func() time.Time {
+		return now
+	}), WithLocation(zone))
+	defer closeTestWorm(t, h)
+	h.MustRegister("count", &countDoer{})
+
+	jobID, err := h.Queue("count", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, err := h.Detail(jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !job.CreatedAt.Equal(now) || job.CreatedAt.Location() != time.UTC {
+		t.Fatalf("created at : got [%s]", job.CreatedAt)
+	}
+	if got := h.FormatLocal(job.CreatedAt, "15:04 MST"); got != "10:00 UTC+5" {
+		t.Fatalf("local : got [%s]", got)
+	}
+
+	for _, tc := range []struct {
+		from, to time.Time
+		n        int
+	}{
+		{now.Add(-time.Hour), now.Add(time.Hour), 1},
+		{now.Add(-time.Hour).UTC(), now.Add(time.Hour).UTC(), 1},
+		// same day, later hours.
+		{now.Add(time.Hour), now.Add(2 * time.Hour), 0},
+		{now.Add(-2 * time.Hour).UTC(), now.Add(-time.Hour).UTC(), 0},
+	} {
+		jobs, err := h.query(tc.from, tc.to, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(jobs) != tc.n {
+			t.Fatalf("query [%s] [%s] : expected [%d] got [%d]", tc.from, tc.to, tc.n, len(jobs))
+		}
+	}
+}
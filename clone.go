@@ -0,0 +1,43 @@
+package worm
+
+import "log"
+
+// Clone queues a new single execution of the job with the same worker and
+// data, e.g. to run a historical job again against fixed code. Non nil data
+// replaces the original data. The external ID and group are kept unless
+// opts set them, the metadata is kept and merged with the opts one.
+func (h *Worm) Clone(jobID string, data []byte, opts ...JobOption) (string, error) {
+	var job struct {
+		Worker     string      `db:"worker_name"`
+		ExternalID string      `db:"external_id"`
+		GroupID    string      `db:"group_id"`
+		Metadata   JobMetadata `db:"metadata"`
+	}
+	o := <-h.waitc
+	err := h.dbGet(&job, `
+		SELECT
+			worker_name,
+			IFNULL(external_id,'') AS "external_id",
+			IFNULL(group_id,'') AS "group_id",
+			metadata
+		FROM worm WHERE id=? AND deleted_at IS NULL;
+	`, jobID)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("Clone : select : err [%s] job id [%s]", err, jobID)
+		return "", err
+	}
+	if data == nil {
+		data, err = h.payload(jobID)
+		if err != nil {
+			return "", err
+		}
+	}
+	keep := []JobOption{ExternalID(job.ExternalID), Group(job.GroupID), Metadata(job.Metadata)}
+	return h.Queue(job.Worker, data, append(keep, opts...)...)
+}
+
+// Clone _
+func Clone(jobID string, data []byte, opts ...JobOption) (string, error) {
+	return defaultWorm.Clone(jobID, data, opts...)
+}
@@ -0,0 +1,47 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClone(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	d := &dataDoer{}
+	h.MustRegister("data", d)
+
+	jobID, err := h.Queue("data", []byte("v1"), ExternalID("order-1"), Metadata(map[string]string{"tenant": "acme"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(time.Minute))
+
+	cloneID, err := h.Clone(jobID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cloneID == jobID {
+		t.Fatalf("expected a new job")
+	}
+	h.Tick(start.Add(2 * time.Minute))
+	clone, err := h.Detail(cloneID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clone.Status != StatusOK || clone.ExternalID != "order-1" || clone.Metadata["tenant"] != "acme" || string(d.data) != "v1" {
+		t.Fatalf("clone : got [%+v] data [%s]", clone, d.data)
+	}
+
+	if _, err := h.Clone(jobID, []byte("v2")); err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(3 * time.Minute))
+	if string(d.data) != "v2" {
+		t.Fatalf("override : got [%s]", d.data)
+	}
+	if _, err := h.Clone("none", nil); err == nil {
+		t.Errorf("unknown job : expected error")
+	}
+}
@@ -0,0 +1,100 @@
+package worm
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// StatusDecode is the status of a job whose data could not be decoded.
+const StatusDecode = 126
+
+// Codec marshals job values to payloads and back.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec is the default Codec.
+type JSONCodec struct{}
+
+// Marshal implements Codec.
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal implements Codec.
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// QueueValue marshals v with the hub codec and queues it.
+func (h *Worm) QueueValue(workerName string, v interface{}, opts ...JobOption) (string, error) {
+	data, err := h.codec.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return h.Queue(workerName, data, opts...)
+}
+
+// SchedValue marshals v with the hub codec and schedules it on cronformat.
+func (h *Worm) SchedValue(workerName string, v interface{}, cronformat string, opts ...JobOption) (string, error) {
+	data, err := h.codec.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return h.Sched(workerName, data, cronformat, opts...)
+}
+
+// RegisterValue registers run as the worker workerName. Job data is decoded
+// with the hub codec into the value returned by newValue, usually a pointer
+// to a new struct.
+func (h *Worm) RegisterValue(workerName string, newValue func() interface{}, run func(v interface{}, logOutput io.Writer) (int, error), opts ...WorkerOption) error {
+	if newValue == nil || run == nil {
+		return errors.New("nil worker")
+	}
+	doer := &valueDoer{
+		name:     workerName,
+		codec:    h.codec,
+		newValue: newValue,
+		run:      run,
+	}
+	return h.Register(workerName, doer, opts...)
+}
+
+// valueDoer is a Doer decoding job data before calling run.
+type valueDoer struct {
+	name     string
+	codec    Codec
+	newValue func() interface{}
+	run      func(v interface{}, logOutput io.Writer) (int, error)
+}
+
+// Name implements Doer.
+func (d *valueDoer) Name() string {
+	return d.name
+}
+
+// Run implements Doer.
+func (d *valueDoer) Run(data []byte, logOutput io.Writer) (int, error) {
+	v := d.newValue()
+	if err := d.codec.Unmarshal(data, v); err != nil {
+		return StatusDecode, err
+	}
+	return d.run(v, logOutput)
+}
+
+// QueueValue _
+func QueueValue(workerName string, v interface{}, opts ...JobOption) (string, error) {
+	return defaultWorm.QueueValue(workerName, v, opts...)
+}
+
+// SchedValue _
+func SchedValue(workerName string, v interface{}, cronformat string, opts ...JobOption) (string, error) {
+	return defaultWorm.SchedValue(workerName, v, cronformat, opts...)
+}
+
+// RegisterValue _
+func RegisterValue(workerName string, newValue func() interface{}, run func(v interface{}, logOutput io.Writer) (int, error), opts ...WorkerOption) error {
+	return defaultWorm.RegisterValue(workerName, newValue, run, opts...)
+}
@@ -0,0 +1,33 @@
+package worm
+
+import (
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+func TestValueDoer(t *testing.T) {
+	type mail struct {
+		To string `json:"to"`
+	}
+	var got string
+	d := &valueDoer{
+		name:     "mailer",
+		codec:    JSONCodec{},
+		newValue: func() interface{} { return &mail{} },
+		run: func(v interface{}, w io.Writer) (int, error) {
+			got = v.(*mail).To
+			return StatusOK, nil
+		},
+	}
+	data, err := JSONCodec{}.Marshal(&mail{To: "a@b.c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, err := d.Run(data, ioutil.Discard); status != StatusOK || err != nil || got != "a@b.c" {
+		t.Fatalf("run : got [%s] status [%d] err [%v]", got, status, err)
+	}
+	if status, err := d.Run([]byte("{"), ioutil.Discard); status != StatusDecode || err == nil {
+		t.Fatalf("run bad data : status [%d] err [%v]", status, err)
+	}
+}
@@ -0,0 +1,89 @@
+package worm
+
+import (
+	"context"
+	"database/sql"
+	"log"
+)
+
+// DefaultConfig sets the configuration passed to the runs of the worker
+// until SetWorkerConfig replaces it.
+func DefaultConfig(cfg []byte) WorkerOption {
+	return func(w *worker) error {
+		w.config = cfg
+		return nil
+	}
+}
+
+// SetWorkerConfig replaces the configuration of the worker. Runs started
+// from now on, by every hub sharing the database, get it from ConfigFrom.
+// The configuration is kept in the database.
+func (h *Worm) SetWorkerConfig(workerName string, cfg []byte) error {
+	o := <-h.waitc
+	_, err := h.dbExec(`
+		INSERT OR REPLACE INTO worm_config (worker_name,config,updated_at) VALUES (?,?,?);
+	`, workerName, cfg, h.now())
+	h.waitc <- o
+	if err != nil {
+		log.Printf("SetWorkerConfig : err [%s] worker [%s]", err, workerName)
+	}
+	return err
+}
+
+// WorkerConfig returns the configuration of the worker, the one set by
+// SetWorkerConfig or else its DefaultConfig.
+func (h *Worm) WorkerConfig(workerName string) ([]byte, error) {
+	var cfg []byte
+	o := <-h.waitc
+	err := h.dbGet(&cfg, `
+		SELECT config FROM worm_config WHERE worker_name=?;
+	`, workerName)
+	h.waitc <- o
+	if err == sql.ErrNoRows {
+		h.RLock()
+		wk, ok := h.workers[workerName]
+		h.RUnlock()
+		if ok {
+			cfg = wk.config
+		}
+		return cfg, nil
+	}
+	if err != nil {
+		log.Printf("WorkerConfig : err [%s] worker [%s]", err, workerName)
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// configKey is the context key of the worker configuration.
+type configKey struct{}
+
+// ConfigFrom returns the worker configuration of the job running with ctx,
+// the context a ContextDoer receives. Nil outside a running job.
+func ConfigFrom(ctx context.Context) []byte {
+	cfg, _ := ctx.Value(configKey{}).([]byte)
+	return cfg
+}
+
+// withConfig adds to ctx the configuration of the worker running the Doer
+// named name.
+func (h *Worm) withConfig(ctx context.Context, name string) context.Context {
+	if wk, ok := h.workerOf(name); ok {
+		name = wk.name
+	}
+	cfg, err := h.WorkerConfig(name)
+	if err != nil || cfg == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, configKey{}, cfg)
+}
+
+// SetWorkerConfig _
+func SetWorkerConfig(workerName string, cfg []byte) error {
+	return defaultWorm.SetWorkerConfig(workerName, cfg)
+}
+
+// WorkerConfig _
+func WorkerConfig(workerName string) ([]byte, error) {
+	return defaultWorm.WorkerConfig(workerName)
+}
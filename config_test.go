@@ -0,0 +1,63 @@
+package worm
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+// configDoer keeps the worker configuration of its last run.
+type configDoer struct {
+	cfg []byte
+}
+
+func (d *configDoer) Name() string {
+	return "config"
+}
+
+func (d *configDoer) Run(data []byte, w io.Writer) (int, error) {
+	return d.RunContext(context.Background(), data, w)
+}
+
+func (d *configDoer) RunContext(ctx context.Context, data []byte, w io.Writer) (int, error) {
+	d.cfg = ConfigFrom(ctx)
+	return StatusOK, nil
+}
+
+func TestWorkerConfig(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+
+	d := &configDoer{}
+	h.MustRegister("config", d, DefaultConfig([]byte(`{"batch":10}`)))
+	if _, err := h.Queue("config", nil); err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start)
+	if string(d.cfg) != `{"batch":10}` {
+		t.Fatalf("default : got [%s]", d.cfg)
+	}
+
+	if err := h.SetWorkerConfig("config", []byte(`{"batch":50}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Queue("config", nil); err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start)
+	if string(d.cfg) != `{"batch":50}` {
+		t.Fatalf("updated : got [%s]", d.cfg)
+	}
+	cfg, err := h.WorkerConfig("config")
+	if err != nil || string(cfg) != `{"batch":50}` {
+		t.Fatalf("WorkerConfig : got [%s] err [%v]", cfg, err)
+	}
+	if cfg, err := h.WorkerConfig("missing"); err != nil || cfg != nil {
+		t.Fatalf("missing : got [%s] err [%v]", cfg, err)
+	}
+	if cfg := ConfigFrom(context.Background()); cfg != nil {
+		t.Fatalf("outside a job : got [%s]", cfg)
+	}
+}
@@ -0,0 +1,91 @@
+package worm
+
+import (
+	"context"
+	"io"
+	"log"
+	"time"
+)
+
+// ContextDoer is a Doer that receives the context of the running job. Worm
+// calls RunContext instead of Run.
+type ContextDoer interface {
+	Doer
+	RunContext(ctx context.Context, data []byte, logOutput io.Writer) (state int, err error)
+}
+
+// jobKey is the context key of the running job ID.
+type jobKey struct{}
+
+// newJobContext returns the context of a job run.
+func newJobContext(jobID string) context.Context {
+	return context.WithValue(context.Background(), jobKey{}, jobID)
+}
+
+// JobInfo describes the running job to its Doer.
+type JobInfo struct {
+	ID     string `db:"id"`
+	Worker string `db:"worker_name"`
+	// Attempt is the number of this execution, 1 for the first one.
+	Attempt int `db:"attempt"`
+	// EnqueuedAt is when the job was queued.
+	EnqueuedAt time.Time   `db:"created_at"`
+	Metadata   JobMetadata `db:"metadata"`
+	// TraceID correlates the job with the request that queued it.
+	TraceID string `db:"trace_id"`
+}
+
+// infoKey is the context key of the running job JobInfo.
+type infoKey struct{}
+
+// FromContext returns the job running with ctx, the context a ContextDoer
+// receives, so the Doer can log and behave according to its own identity.
+// False outside a running job.
+func FromContext(ctx context.Context) (*JobInfo, bool) {
+	info, ok := ctx.Value(infoKey{}).(*JobInfo)
+	return info, ok
+}
+
+// withJobInfo adds to ctx the JobInfo of the job. Must be called after
+// startRun so the run is counted.
+func (h *Worm) withJobInfo(ctx context.Context, jobID string) context.Context {
+	info := &JobInfo{ID: jobID}
+	o := <-h.waitc
+	err := h.dbGet(info, `
+		SELECT
+			id,
+			worker_name,
+			(SELECT COUNT(*) FROM worm_run WHERE worm_run.job_id=worm.id) AS "attempt",
+			created_at,
+			metadata,
+			IFNULL(trace_id,'') AS "trace_id"
+		FROM worm WHERE id=?;
+	`, jobID)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("withJobInfo : err [%s] job id [%s]", err, jobID)
+	}
+	return context.WithValue(ctx, infoKey{}, info)
+}
+
+// perform runs doer with the job context when supported.
+func perform(ctx context.Context, doer Doer, data []byte, w io.Writer) (int, error) {
+	if d, ok := doer.(ContextDoer); ok {
+		return d.RunContext(ctx, data, w)
+	}
+	return doer.Run(data, w)
+}
+
+// ChildOf attributes the job to the job running with ctx, the context a
+// ContextDoer receives. Detail of the parent lists its children. Outside a
+// running job it does nothing.
+func ChildOf(ctx context.Context) JobOption {
+	return func(o *jobOptions) {
+		if jobID, ok := ctx.Value(jobKey{}).(string); ok {
+			o.parentID = jobID
+		}
+		if info, ok := FromContext(ctx); ok && len(o.traceID) < 1 {
+			o.traceID = info.TraceID
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package worm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// spawnDoer queues a child job of worker for each run.
+type spawnDoer struct {
+	h      *Worm
+	worker string
+}
+
+func (d *spawnDoer) Name() string {
+	return "spawn"
+}
+
+func (d *spawnDoer) Run(data []byte, w io.Writer) (int, error) {
+	return d.RunContext(context.Background(), data, w)
+}
+
+func (d *spawnDoer) RunContext(ctx context.Context, data []byte, w io.Writer) (int, error) {
+	if _, err := d.h.Queue(d.worker, data, ChildOf(ctx)); err != nil {
+		return 2, err
+	}
+	return StatusOK, nil
+}
+
+func TestChildOf(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	h.MustRegister("child", &testDoer{name: "child"})
+	h.MustRegister("spawn", &spawnDoer{h: h, worker: "child"})
+
+	parentID, err := h.Queue("spawn", []byte("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	orphanID, err := h.Queue("child", nil, ChildOf(context.Background()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(time.Minute))
+
+	parent, err := h.Detail(parentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parent.Children) != 1 {
+		t.Fatalf("children : expected 1 got [%v]", parent.Children)
+	}
+	child, err := h.Detail(parent.Children[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if child.ParentID != parentID || child.Status != StatusOK {
+		t.Fatalf("child : got [%+v]", child)
+	}
+	orphan, err := h.Detail(orphanID)
+	if err != nil || orphan.ParentID != "" {
+		t.Fatalf("orphan : got [%+v] err [%v]", orphan, err)
+	}
+}
+
+// infoDoer keeps the JobInfo of its last run.
+type infoDoer struct {
+	info *JobInfo
+}
+
+func (d *infoDoer) Name() string {
+	return "info"
+}
+
+func (d *infoDoer) Run(data []byte, w io.Writer) (int, error) {
+	return d.RunContext(context.Background(), data, w)
+}
+
+func (d *infoDoer) RunContext(ctx context.Context, data []byte, w io.Writer) (int, error) {
+	d.info, _ = FromContext(ctx)
+	return 2, errors.New("boom")
+}
+
+func TestFromContext(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	d := &infoDoer{}
+	h.MustRegister("info", d)
+
+	jobID, err := h.Queue("info", nil, Metadata(map[string]string{"tenant": "acme"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start)
+	if err := h.Retry(jobID); err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(time.Minute))
+	info := d.info
+	if info == nil || info.ID != jobID || info.Worker != "info" || info.Attempt != 2 {
+		t.Fatalf("info : got [%+v]", info)
+	}
+	if !info.EnqueuedAt.Equal(start) || info.Metadata["tenant"] != "acme" {
+		t.Fatalf("info : got enqueued at [%s] metadata [%v]", info.EnqueuedAt, info.Metadata)
+	}
+	if _, ok := FromContext(context.Background()); ok {
+		t.Fatalf("outside a job : expected no info")
+	}
+}
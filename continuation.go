@@ -0,0 +1,68 @@
+package worm
+
+import (
+	"encoding/json"
+	"log"
+)
+
+// continuation is a job queued when another job finishes.
+type continuation struct {
+	// OnFailure queues the job on failure instead of success.
+	OnFailure bool   `json:"on_failure,omitempty"`
+	Worker    string `json:"worker"`
+	Data      []byte `json:"data"`
+}
+
+// OnSuccess queues a job of workerName with data when the job succeeds. The
+// follow-up job has the job as parent.
+func OnSuccess(workerName string, data []byte) JobOption {
+	return func(o *jobOptions) {
+		o.continuations = append(o.continuations, &continuation{
+			Worker: workerName,
+			Data:   data,
+		})
+	}
+}
+
+// OnFailure queues a job of workerName with data when the job fails. The
+// follow-up job has the job as parent.
+func OnFailure(workerName string, data []byte) JobOption {
+	return func(o *jobOptions) {
+		o.continuations = append(o.continuations, &continuation{
+			OnFailure: true,
+			Worker:    workerName,
+			Data:      data,
+		})
+	}
+}
+
+// encodeContinuations returns the stored form of list.
+func encodeContinuations(list []*continuation) (string, error) {
+	if len(list) < 1 {
+		return "", nil
+	}
+	b, err := json.Marshal(list)
+	return string(b), err
+}
+
+// continueWith queues the stored continuations matching the job status.
+func (h *Worm) continueWith(jobID string, status int, stored string) {
+	if len(stored) < 1 {
+		return
+	}
+	var list []*continuation
+	if err := json.Unmarshal([]byte(stored), &list); err != nil {
+		log.Printf("continueWith : decode : err [%s] job id [%s]", err, jobID)
+		return
+	}
+	for _, c := range list {
+		if c.OnFailure != (status != StatusOK) {
+			continue
+		}
+		opts := newJobOptions(once, nil)
+		opts.parentID = jobID
+		if _, err := h.queue(c.Worker, c.Data, opts); err != nil {
+			log.Printf("continueWith : queue [%s] : err [%s] job id [%s]", c.Worker, err, jobID)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package worm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestContinuations(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	notify := &dataDoer{}
+	h.MustRegister("notify", notify)
+	h.MustRegister("ok", &testDoer{name: "ok"})
+	h.MustRegister("fail", &testDoer{name: "fail", status: 2, err: errors.New("boom")})
+
+	okID, err := h.Queue("ok", nil,
+		OnSuccess("notify", []byte("done")),
+		OnFailure("notify", []byte("failed")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(time.Minute))
+	if string(notify.data) != "done" {
+		t.Fatalf("on success : got [%s]", notify.data)
+	}
+	job, err := h.Detail(okID)
+	if err != nil || len(job.Children) != 1 {
+		t.Fatalf("children : got [%+v] err [%v]", job, err)
+	}
+
+	if _, err := h.Queue("fail", nil,
+		OnSuccess("notify", []byte("done")),
+		OnFailure("notify", []byte("failed"))); err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(2 * time.Minute))
+	if string(notify.data) != "failed" {
+		t.Fatalf("on failure : got [%s]", notify.data)
+	}
+}
@@ -0,0 +1,78 @@
+package worm
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+// CronFormat is the layout of the cron specs accepted by a hub. Every format
+// accepts RRULE recurrence rules and ISO 8601 repeating intervals.
+type CronFormat int
+
+const (
+	// CronDefault specs start with seconds and may omit the day of week,
+	// so five fields read as second, minute, hour, dom and month.
+	// Descriptors are accepted.
+	CronDefault CronFormat = iota
+	// CronSeconds specs have exactly six fields, seconds first.
+	// Descriptors are accepted.
+	CronSeconds
+	// CronStandard specs have the five crontab fields, minute first.
+	// Descriptors are accepted.
+	CronStandard
+	// CronDescriptors only accepts descriptors, e.g. @daily or @every 1h.
+	CronDescriptors
+)
+
+// errDescriptor rejects cron fields in the CronDescriptors format.
+var errDescriptor = errors.New("worm: cron format only accepts descriptors, e.g. @daily")
+
+// parsers of the cron formats with fields.
+var (
+	secondsParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour |
+		cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+	standardParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom |
+		cron.Month | cron.Dow | cron.Descriptor)
+)
+
+// WithCronFormat sets the layout of the cron specs of Sched, UpdateSchedule
+// and the hub NextRuns and Explain. Specs in another layout are rejected
+// instead of firing at the wrong cadence. Default CronDefault.
+func WithCronFormat(f CronFormat) Option {
+	return func(h *Worm) {
+		h.cronFormat = f
+	}
+}
+
+// parse parses the cron spec in format f.
+func (f CronFormat) parse(spec string) (cron.Schedule, error) {
+	switch f {
+	case CronSeconds:
+		return secondsParser.Parse(spec)
+	case CronStandard:
+		return standardParser.Parse(spec)
+	case CronDescriptors:
+		if !strings.HasPrefix(strings.TrimSpace(spec), "@") {
+			return nil, errDescriptor
+		}
+	}
+	return cron.Parse(spec)
+}
+
+// parseSpec parses spec in the cron format of the hub.
+func (h *Worm) parseSpec(spec string, now time.Time) (cron.Schedule, error) {
+	return parseSpec(spec, now, h.cronFormat)
+}
+
+// NextRuns is NextRuns in the cron format of the hub.
+func (h *Worm) NextRuns(cronSpec string, n int, from time.Time) ([]time.Time, error) {
+	return nextRunsFormat(cronSpec, n, from, h.cronFormat)
+}
+
+// Explain is Explain in the cron format of the hub.
+func (h *Worm) Explain(cronSpec string) (*CronSpec, error) {
+	return explainFormat(cronSpec, h.cronFormat)
+}
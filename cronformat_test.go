@@ -0,0 +1,64 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronFormat(t *testing.T) {
+	from := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	table := []struct {
+		format CronFormat
+		spec   string
+		// next is zero when the spec is rejected.
+		next time.Time
+	}{
+		{CronDefault, "0 3 * * *", from.Add(3 * time.Minute)},
+		{CronDefault, "0 0 3 * * *", from.Add(3 * time.Hour)},
+		{CronSeconds, "0 3 * * *", time.Time{}},
+		{CronSeconds, "0 0 3 * * *", from.Add(3 * time.Hour)},
+		{CronStandard, "0 3 * * *", from.Add(3 * time.Hour)},
+		{CronStandard, "0 0 3 * * *", time.Time{}},
+		{CronStandard, "@hourly", from.Add(time.Hour)},
+		{CronDescriptors, "@every 2h", from.Add(2 * time.Hour)},
+		{CronDescriptors, "0 3 * * *", time.Time{}},
+		{CronDescriptors, "RRULE:FREQ=DAILY;BYHOUR=3", from.Add(3 * time.Hour)},
+	}
+	for _, x := range table {
+		list, err := nextRunsFormat(x.spec, 1, from, x.format)
+		if x.next.IsZero() {
+			if err == nil {
+				t.Errorf("format [%d] spec [%s] : expected error", x.format, x.spec)
+			}
+			continue
+		}
+		if err != nil || len(list) != 1 || !list[0].Equal(x.next) {
+			t.Errorf("format [%d] spec [%s] : expected [%s] got [%v] err [%v]", x.format, x.spec, x.next, list, err)
+		}
+	}
+}
+
+func TestWithCronFormat(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start), WithCronFormat(CronStandard))
+	defer closeTestWorm(t, h)
+	h.MustRegister("data", &dataDoer{})
+
+	if _, err := h.Sched("data", nil, "0 0 3 * * *"); err == nil {
+		t.Fatalf("sched : expected six fields rejected")
+	}
+	jobID, err := h.Sched("data", nil, "0 3 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := h.Tick(start.Add(4 * time.Hour)); n != 1 {
+		t.Fatalf("tick : expected 1 firing at 3:00 got [%d]", n)
+	}
+	if err := h.UpdateSchedule(jobID, nil, "0 */15 * * * *"); err == nil {
+		t.Fatalf("update : expected six fields rejected")
+	}
+	x, err := h.Explain("30 3 * * 1-5")
+	if err != nil || x.Summary != "every weekday at 03:30" {
+		t.Fatalf("explain : got [%v] err [%v]", x, err)
+	}
+}
@@ -0,0 +1,125 @@
+package worm
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// crontabEnv matches the environment lines of crontabs, like MAILTO=ops.
+var crontabEnv = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*\s*=`)
+
+// ParseCrontab reads a crontab-style file where each line is a schedule, a
+// worker name and an optional inline JSON payload:
+//
+//	# m h dom mon dow  worker  payload
+//	30 2 * * 1-5       report  {"format": "pdf"}
+//	@hourly            cleanup
+//	@every 15m         sync    {"full": false}
+//
+// Comments, blank lines and environment lines are skipped. The five field
+// specs, minute first, are converted to format. Entries are named by their
+// worker and a hash of the line, like report-1a2b3c4d, so repeated entries
+// are rejected.
+func ParseCrontab(r io.Reader, format CronFormat) ([]ScheduleDef, error) {
+	var defs []ScheduleDef
+	lines := make(map[string]int)
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if len(line) < 1 || strings.HasPrefix(line, "#") || crontabEnv.MatchString(line) {
+			continue
+		}
+		def, err := parseCrontabLine(line, format)
+		if err != nil {
+			return nil, fmt.Errorf("worm: crontab line %d: %s", n, err)
+		}
+		if first, ok := lines[def.Name]; ok {
+			return nil, fmt.Errorf("worm: crontab line %d: same entry as line %d", n, first)
+		}
+		lines[def.Name] = n
+		defs = append(defs, def)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return defs, nil
+}
+
+// parseCrontabLine parses an entry of ParseCrontab.
+func parseCrontabLine(line string, format CronFormat) (ScheduleDef, error) {
+	var def ScheduleDef
+	n := 5
+	if strings.HasPrefix(line, "@") {
+		n = 1
+		if strings.HasPrefix(line, "@every") {
+			n = 2
+		}
+	}
+	fields := make([]string, n)
+	rest := line
+	for i := range fields {
+		fields[i], rest = cutField(rest)
+	}
+	def.Worker, rest = cutField(rest)
+	if len(def.Worker) < 1 {
+		return def, errors.New("missing worker")
+	}
+	def.Cron = strings.Join(fields, " ")
+	if n == 5 {
+		switch format {
+		case CronDefault, CronSeconds:
+			def.Cron = "0 " + def.Cron
+		case CronDescriptors:
+			return def, errDescriptor
+		}
+	}
+	if len(rest) > 0 {
+		if !json.Valid([]byte(rest)) {
+			return def, errors.New("invalid JSON payload")
+		}
+		def.Payload = json.RawMessage(rest)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, " ") + "\n" + def.Worker + "\n" + rest))
+	def.Name = fmt.Sprintf("%s-%x", def.Worker, sum[:4])
+	return def, nil
+}
+
+// cutField returns the first whitespace separated field of s and the rest.
+func cutField(s string) (string, string) {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	i := strings.IndexFunc(s, unicode.IsSpace)
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], strings.TrimSpace(s[i:])
+}
+
+// ImportCrontab registers the entries of the crontab-style file at path,
+// see ParseCrontab, with ApplySchedules, path being the source: importing
+// the file again after editing it updates the schedules and deletes the
+// ones of removed lines. Edited lines get new schedule IDs.
+func (h *Worm) ImportCrontab(path string) (*ScheduleSync, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	defs, err := ParseCrontab(f, h.cronFormat)
+	if err != nil {
+		return nil, err
+	}
+	return h.ApplySchedules(path, defs)
+}
+
+// ImportCrontab _
+func ImportCrontab(path string) (*ScheduleSync, error) {
+	return defaultWorm.ImportCrontab(path)
+}
@@ -0,0 +1,102 @@
+package worm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCrontab(t *testing.T) {
+	crontab := `
+# m h dom mon dow  worker  payload
+MAILTO=ops@example.com
+30 2 * * 1-5       report  {"format": "pdf"}
+@hourly            cleanup
+  @every 15m       sync    {"full": false}
+`
+	defs, err := ParseCrontab(strings.NewReader(crontab), CronDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(defs) != 3 {
+		t.Fatalf("expected 3 entries got [%+v]", defs)
+	}
+	for i, x := range []struct {
+		cron, worker, payload string
+	}{
+		{"0 30 2 * * 1-5", "report", `{"format": "pdf"}`},
+		{"@hourly", "cleanup", ""},
+		{"@every 15m", "sync", `{"full": false}`},
+	} {
+		def := defs[i]
+		if def.Cron != x.cron || def.Worker != x.worker || string(def.Payload) != x.payload {
+			t.Fatalf("entry %d : got [%+v]", i, def)
+		}
+		if !strings.HasPrefix(def.Name, x.worker+"-") || len(def.Name) != len(x.worker)+9 {
+			t.Fatalf("entry %d : got name [%s]", i, def.Name)
+		}
+	}
+	// names don't depend on spacing.
+	again, err := ParseCrontab(strings.NewReader(`30 2 * *  1-5 report {"format": "pdf"}`), CronDefault)
+	if err != nil || again[0].Name != defs[0].Name {
+		t.Fatalf("spacing : got [%+v] err [%v]", again, err)
+	}
+	standard, err := ParseCrontab(strings.NewReader(`30 2 * * 1-5 report`), CronStandard)
+	if err != nil || standard[0].Cron != "30 2 * * 1-5" {
+		t.Fatalf("standard : got [%+v] err [%v]", standard, err)
+	}
+
+	for _, line := range []string{
+		"30 2 * * 1-5",
+		"@daily",
+		`@daily report {"format": `,
+	} {
+		if _, err := ParseCrontab(strings.NewReader(line), CronDefault); err == nil {
+			t.Fatalf("expected error for [%s]", line)
+		}
+	}
+	if _, err := ParseCrontab(strings.NewReader("30 2 * * * report"), CronDescriptors); err == nil {
+		t.Fatal("descriptors : expected error")
+	}
+	_, err = ParseCrontab(strings.NewReader("@hourly cleanup\n# again\n@hourly  cleanup\n"), CronDefault)
+	if err == nil || err.Error() != "worm: crontab line 3: same entry as line 1" {
+		t.Fatalf("duplicate : got err [%v]", err)
+	}
+}
+
+func TestImportCrontab(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	d := &dataDoer{}
+	h.MustRegister("data", d)
+	h.MustRegister("count", &countDoer{})
+
+	dir, err := ioutil.TempDir("", "crontab")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "crontab")
+	if err := ioutil.WriteFile(path, []byte("30 * * * * data {\"n\": 1}\n@daily count\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	x, err := h.ImportCrontab(path)
+	if err != nil || len(x.Created) != 2 {
+		t.Fatalf("import : got [%+v] err [%v]", x, err)
+	}
+	if n, _ := h.Tick(start.Add(time.Hour)); n != 1 || string(d.data) != `{"n": 1}` {
+		t.Fatalf("tick : got [%d] firings data [%s]", n, d.data)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("@daily count\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	x, err = h.ImportCrontab(path)
+	if err != nil || len(x.Updated) != 1 || len(x.Deleted) != 1 || !strings.HasPrefix(x.Deleted[0], "data-") {
+		t.Fatalf("reimport : got [%+v] err [%v]", x, err)
+	}
+}
@@ -0,0 +1,60 @@
+package worm
+
+import (
+	"database/sql"
+	"time"
+
+	sqlite3 "github.com/mattn/go-sqlite3"
+)
+
+const (
+	// busyRetries is how many times busy database operations are retried.
+	busyRetries = 5
+	// busyBackoff is the first wait before a retry, doubled on each one.
+	busyBackoff = 10 * time.Millisecond
+)
+
+// isBusy reports if err is a transient SQLITE_BUSY or SQLITE_LOCKED error.
+func isBusy(err error) bool {
+	e, ok := err.(sqlite3.Error)
+	return ok && (e.Code == sqlite3.ErrBusy || e.Code == sqlite3.ErrLocked)
+}
+
+// retryBusy calls fn until it succeeds, fails with a not busy error or the
+// retries run out.
+func retryBusy(fn func() error) error {
+	wait := busyBackoff
+	for i := 0; ; i++ {
+		err := fn()
+		if err == nil || i >= busyRetries || !isBusy(err) {
+			return err
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// dbExec is Db.Exec retried on busy errors.
+func (h *Worm) dbExec(query string, args ...interface{}) (sql.Result, error) {
+	var res sql.Result
+	err := retryBusy(func() error {
+		var err error
+		res, err = h.Db.Exec(query, args...)
+		return err
+	})
+	return res, err
+}
+
+// dbGet is Db.Get retried on busy errors.
+func (h *Worm) dbGet(dest interface{}, query string, args ...interface{}) error {
+	return retryBusy(func() error {
+		return h.Db.Get(dest, query, args...)
+	})
+}
+
+// dbSelect is Db.Select retried on busy errors.
+func (h *Worm) dbSelect(dest interface{}, query string, args ...interface{}) error {
+	return retryBusy(func() error {
+		return h.Db.Select(dest, query, args...)
+	})
+}
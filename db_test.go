@@ -0,0 +1,39 @@
+package worm
+
+import (
+	"errors"
+	"testing"
+
+	sqlite3 "github.com/mattn/go-sqlite3"
+)
+
+func TestRetryBusy(t *testing.T) {
+	var calls int
+	err := retryBusy(func() error {
+		calls++
+		if calls < 3 {
+			return sqlite3.Error{Code: sqlite3.ErrBusy}
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("busy : expected success on 3rd call got [%d] err [%v]", calls, err)
+	}
+
+	calls = 0
+	boom := errors.New("boom")
+	if err := retryBusy(func() error {
+		calls++
+		return boom
+	}); err != boom || calls != 1 {
+		t.Fatalf("not busy : expected no retries got [%d] err [%v]", calls, err)
+	}
+
+	calls = 0
+	if err := retryBusy(func() error {
+		calls++
+		return sqlite3.Error{Code: sqlite3.ErrLocked}
+	}); !isBusy(err) || calls != busyRetries+1 {
+		t.Fatalf("locked : expected %d calls got [%d] err [%v]", busyRetries+1, calls, err)
+	}
+}
@@ -0,0 +1,73 @@
+package worm
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+)
+
+// errScheduleName is returned by SchedUpsert for empty logical names.
+var errScheduleName = errors.New("worm: empty schedule name")
+
+// SchedUpsert crons the job like Sched under the schedule ID logicalName,
+// or replaces the worker, data, spec, window and calendar of the recurring
+// job already stored with it in a single update, so config driven rollouts
+// never accumulate duplicates. The job keeps its ID, run history and
+// disabled state. Returns the job ID.
+func (h *Worm) SchedUpsert(logicalName, workerName string, data []byte, cronformat string, opts ...JobOption) (string, error) {
+	if len(logicalName) < 1 {
+		return "", errScheduleName
+	}
+	o := newJobOptions(cronformat, append(opts, ScheduleID(logicalName)))
+	jobID, err := h.scheduled(logicalName)
+	if err == sql.ErrNoRows {
+		jobID, err = h.sched(workerName, data, cronformat, o)
+		if err == nil {
+			return jobID, nil
+		}
+		// another hub may have stored it first.
+		var lookupErr error
+		if jobID, lookupErr = h.scheduled(logicalName); lookupErr != nil {
+			return "", err
+		}
+	} else if err != nil {
+		log.Printf("SchedUpsert : err [%s] schedule id [%s]", err, logicalName)
+		return "", err
+	}
+	if err := h.updateSchedule(jobID, workerName, data, cronformat, o); err != nil {
+		return "", err
+	}
+	return jobID, nil
+}
+
+// scheduled returns the job ID of the recurring job with the schedule ID.
+func (h *Worm) scheduled(scheduleID string) (string, error) {
+	var jobID string
+	o := <-h.waitc
+	err := h.dbGet(&jobID, `
+		SELECT id FROM worm WHERE schedule_id=? AND cron<>'' AND deleted_at IS NULL;
+	`, scheduleID)
+	h.waitc <- o
+	return jobID, err
+}
+
+// RegisterCron ensures the recurring job with the schedule ID name runs data
+// with the worker on spec, so applications can declare their periodic jobs
+// in code at startup instead of scheduling them after each deploy. The
+// first call, or the first one after DeleteSchedule, stores the job; the
+// next ones, on this hub or after a restart, update it and cron it again,
+// see SchedUpsert.
+func (h *Worm) RegisterCron(name, spec, workerName string, data []byte) error {
+	_, err := h.SchedUpsert(name, workerName, data, spec)
+	return err
+}
+
+// SchedUpsert _
+func SchedUpsert(logicalName, workerName string, data []byte, cronformat string, opts ...JobOption) (string, error) {
+	return defaultWorm.SchedUpsert(logicalName, workerName, data, cronformat, opts...)
+}
+
+// RegisterCron _
+func RegisterCron(name, spec, workerName string, data []byte) error {
+	return defaultWorm.RegisterCron(name, spec, workerName, data)
+}
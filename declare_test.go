@@ -0,0 +1,108 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegisterCron(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	d := &dataDoer{}
+	h.MustRegister("data", d)
+	h.MustRegister("other", &testDoer{name: "other"})
+
+	if err := h.RegisterCron("report", "0 0 * * * *", "data", []byte(`"v1"`)); err != nil {
+		t.Fatal(err)
+	}
+	// a second declaration on the same hub replaces the first one.
+	if err := h.RegisterCron("report", "0 0 * * * *", "data", []byte(`"v2"`)); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := h.Tick(start.Add(time.Hour)); n != 1 || string(d.data) != `"v2"` {
+		t.Fatalf("tick : got [%d] firings data [%s]", n, d.data)
+	}
+
+	report, err := h.Schedule("report")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a restarted hub declares it again with a new spec.
+	h.Lock()
+	h.crons[report.JobID].retire()
+	delete(h.crons, report.JobID)
+	h.Unlock()
+	if err := h.RegisterCron("report", "0 */30 * * * *", "data", []byte(`"v3"`)); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := h.Tick(start.Add(2 * time.Hour)); n != 2 || string(d.data) != `"v3"` {
+		t.Fatalf("restart : got [%d] firings data [%s]", n, d.data)
+	}
+	jobs, err := h.Jobs(JobFilter{Worker: "data"}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != report.JobID || jobs[0].Cron != "0 */30 * * * *" {
+		t.Fatalf("jobs : got [%v]", jobs)
+	}
+	runs, err := h.Runs(report.JobID)
+	if err != nil || len(runs) != 3 {
+		t.Fatalf("runs : expected 3 got [%d] err [%v]", len(runs), err)
+	}
+
+	if err := h.RegisterCron("bad", "bad spec", "data", nil); err == nil {
+		t.Fatal("expected spec error")
+	}
+
+	// a deleted schedule is stored again.
+	if err := h.DeleteSchedule("report"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.RegisterCron("report", "@hourly", "other", nil); err != nil {
+		t.Fatal(err)
+	}
+	again, err := h.Schedule("report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.JobID == report.JobID || again.Worker != "other" {
+		t.Fatalf("deleted : got [%+v]", again)
+	}
+}
+
+func TestSchedUpsert(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	d := &dataDoer{}
+	h.MustRegister("data", d)
+
+	first, err := h.SchedUpsert("sync", "data", []byte(`"v1"`), "0 0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the rollout moves the job to the first hours of the day.
+	second, err := h.SchedUpsert("sync", "data", []byte(`"v2"`), "0 0 * * * *", Window(0, 2*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second != first {
+		t.Fatalf("expected job [%s] got [%s]", first, second)
+	}
+	// only 1:00 is within the window.
+	if n, _ := h.Tick(start.Add(6 * time.Hour)); n != 1 || string(d.data) != `"v2"` {
+		t.Fatalf("tick : got [%d] firings data [%s]", n, d.data)
+	}
+	list, err := h.Schedules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != "sync" || list[0].JobID != first {
+		t.Fatalf("schedules : got [%v]", list)
+	}
+	if _, err := h.SchedUpsert("", "data", nil, "@hourly"); err != errScheduleName {
+		t.Fatalf("empty name : expected errScheduleName got [%v]", err)
+	}
+}
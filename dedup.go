@@ -0,0 +1,38 @@
+package worm
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"time"
+)
+
+// Dedup drops jobs of the worker whose data is identical to a job queued in
+// the last window. Queue and Sched return the ID of the existing job instead.
+func Dedup(window time.Duration) WorkerOption {
+	return func(w *worker) error {
+		w.dedup = window
+		return nil
+	}
+}
+
+// payloadHash returns the hex SHA-256 of data.
+func payloadHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// duplicate returns the ID of a job of the worker with the same payload hash
+// created after since, empty if none. Must be called holding waitc.
+func (h *Worm) duplicate(workerName, hash string, since time.Time) (string, error) {
+	var id string
+	err := h.dbGet(&id, `
+		SELECT id FROM worm
+		WHERE worker_name=? AND payload_hash=? AND created_at>? AND deleted_at IS NULL
+		ORDER BY created_at DESC LIMIT 1;
+	`, workerName, hash, since)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return id, err
+}
@@ -0,0 +1,32 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDedup(t *testing.T) {
+	h := newTestWorm(t)
+	defer closeTestWorm(t, h)
+	h.MustRegister("a", &testDoer{name: "a"}, Dedup(time.Minute))
+	h.MustRegister("b", &testDoer{name: "b"})
+
+	first, err := h.Queue("a", []byte(`{"order":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := h.Queue("a", []byte(`{"order":1}`))
+	if err != nil || again != first {
+		t.Fatalf("duplicate : expected [%s] got [%s] err [%v]", first, again, err)
+	}
+	other, err := h.Queue("a", []byte(`{"order":2}`))
+	if err != nil || other == first {
+		t.Fatalf("other payload : got [%s] err [%v]", other, err)
+	}
+
+	b1, _ := h.Queue("b", []byte(`{"order":1}`))
+	b2, _ := h.Queue("b", []byte(`{"order":1}`))
+	if b1 == b2 {
+		t.Fatalf("no dedup : expected two jobs")
+	}
+}
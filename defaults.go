@@ -0,0 +1,43 @@
+package worm
+
+import "time"
+
+// WithDefaultTimeout bounds the runs of the workers registered without
+// Timeout to d, see Timeout.
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(h *Worm) {
+		if d > 0 {
+			h.timeout = d
+		}
+	}
+}
+
+// WithDefaultRetries runs the failed single execution jobs of the workers
+// registered without MaxRetries again up to n times, see MaxRetries.
+func WithDefaultRetries(n int) Option {
+	return func(h *Worm) {
+		if n > 0 {
+			h.maxRetries = n
+		}
+	}
+}
+
+// inherit sets the policies wk was registered without to the hub defaults.
+// Negative durations turn the policy off.
+func (h *Worm) inherit(wk *worker) {
+	if wk.maxRetries < 0 {
+		wk.maxRetries = h.maxRetries
+	}
+	switch {
+	case wk.timeout == 0:
+		wk.timeout = h.timeout
+	case wk.timeout < 0:
+		wk.timeout = 0
+	}
+	switch {
+	case wk.logRetention == 0:
+		wk.logRetention = h.logRetention
+	case wk.logRetention < 0:
+		wk.logRetention = 0
+	}
+}
@@ -0,0 +1,73 @@
+package worm
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestDefaults(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t,
+		WithManualTick(start),
+		WithDefaultTimeout(10*time.Millisecond),
+		WithDefaultRetries(1),
+		WithLogRetention(time.Hour, 0),
+	)
+	defer closeTestWorm(t, h)
+	bounded, free := &deadlineDoer{name: "bounded"}, &deadlineDoer{name: "free"}
+	h.MustRegister("bounded", bounded)
+	h.MustRegister("free", free, Timeout(0), MaxRetries(0), LogRetention(3*time.Hour))
+
+	got := map[string]WorkerInfo{}
+	for _, wk := range h.Workers() {
+		got[wk.Name] = wk
+	}
+	if x := got["bounded"]; x.Timeout != 10*time.Millisecond || x.MaxRetries != 1 || x.LogRetention != time.Hour {
+		t.Fatalf("inherited : got [%+v]", x)
+	}
+	if x := got["free"]; x.Timeout != 0 || x.MaxRetries != 0 || x.LogRetention != 3*time.Hour {
+		t.Fatalf("overridden : got [%+v]", x)
+	}
+
+	boundedID, err := h.Queue("bounded", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	freeID, err := h.Queue("free", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start)
+	// the bounded job timed out and is retried once.
+	if job, _ := h.Detail(boundedID); !bounded.deadline || job.Status != StatusStart {
+		t.Fatalf("first run : got [%+v]", job)
+	}
+	h.Tick(start.Add(time.Minute))
+	if job, _ := h.Detail(boundedID); job.Status != 2 || job.Attempts != 2 {
+		t.Fatalf("retried : got [%+v]", job)
+	}
+	if job, _ := h.Detail(freeID); free.deadline || job.Status != StatusOK {
+		t.Fatalf("free : got [%+v]", job)
+	}
+
+	h.Tick(start.Add(2 * time.Hour))
+	if pass, _ := h.Sweep(); pass.Logs != 2 {
+		t.Fatalf("hub retention : expected 2 logs removed got [%+v]", pass)
+	}
+	if err := h.CopyLog(ioutil.Discard, freeID); err != nil {
+		t.Fatalf("worker retention : expected log kept got [%v]", err)
+	}
+	h.Tick(start.Add(4 * time.Hour))
+	if pass, _ := h.Sweep(); pass.Logs != 1 {
+		t.Fatalf("worker retention : expected 1 log removed got [%+v]", pass)
+	}
+
+	// options override the defaults one by one.
+	if err := h.Register("strict", &testDoer{name: "strict"}, MaxRetries(3)); err != nil {
+		t.Fatal(err)
+	}
+	if wk, _ := h.workerOf("strict"); wk.maxRetries != 3 || wk.timeout != 10*time.Millisecond {
+		t.Fatalf("strict : got retries [%d] timeout [%s]", wk.maxRetries, wk.timeout)
+	}
+}
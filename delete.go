@@ -0,0 +1,153 @@
+package worm
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrNotFound is returned for unknown or already deleted jobs.
+var ErrNotFound = errors.New("worm: job not found")
+
+// Delete soft deletes the job. Deleted jobs are hidden from queries and
+// never run again, Restore brings them back until purged.
+func (h *Worm) Delete(jobID string) error {
+	return h.setDeleted(jobID, `deleted_at IS NULL`, h.now())
+}
+
+// Restore undoes the Delete of the job.
+func (h *Worm) Restore(jobID string) error {
+	return h.setDeleted(jobID, `deleted_at IS NOT NULL`, nil)
+}
+
+// setDeleted sets deleted_at of the job matching cond.
+func (h *Worm) setDeleted(jobID, cond string, deletedAt interface{}) error {
+	o := <-h.waitc
+	res, err := h.dbExec(`
+		UPDATE worm SET deleted_at=?,updated_at=? WHERE id=? AND `+cond+`;
+	`, deletedAt, h.now(), jobID)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("setDeleted : err [%s] job id [%s]", err, jobID)
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Purge removes for good the jobs deleted before t, with their runs, logs
+// and blobs. Returns the number of purged jobs.
+func (h *Worm) Purge(t time.Time) (int, error) {
+	var jobs []struct {
+		ID      string `db:"id"`
+		LogFile string `db:"log_file"`
+		BlobKey string `db:"blob_key"`
+	}
+	var logs []string
+	o := <-h.waitc
+	err := h.dbSelect(&jobs, `
+		SELECT id, IFNULL(log_file,'') AS "log_file", IFNULL(blob_key,'') AS "blob_key"
+		FROM worm WHERE deleted_at<?;
+	`, t.UTC())
+	if err == nil && len(jobs) > 0 {
+		ids := make([]string, len(jobs))
+		for i := range jobs {
+			ids[i] = jobs[i].ID
+		}
+		logs, err = h.purgeRows(ids)
+	}
+	h.waitc <- o
+	if err != nil {
+		log.Printf("Purge : err [%s]", err)
+		return 0, err
+	}
+
+	for _, job := range jobs {
+		if len(job.BlobKey) > 0 && h.blobs != nil {
+			if err := h.blobs.Delete(job.BlobKey); err != nil {
+				log.Printf("Purge : delete blob : err [%s] job id [%s]", err, job.ID)
+			}
+		}
+		if len(job.LogFile) > 0 {
+			logs = append(logs, job.LogFile)
+		}
+	}
+	// the job log is also the log of its latest run, already removed logs
+	// are skipped.
+	for _, name := range logs {
+		if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+			log.Printf("Purge : remove log : err [%s] log [%s]", err, name)
+		}
+	}
+	return len(jobs), nil
+}
+
+// purgeBatch keeps the purge queries under the SQLite variables limit.
+const purgeBatch = 500
+
+// purgeRows deletes the jobs and their runs. Returns the log files of the
+// deleted runs. Must be called holding waitc.
+func (h *Worm) purgeRows(ids []string) ([]string, error) {
+	var logs []string
+	for len(ids) > 0 {
+		batch := ids
+		if len(batch) > purgeBatch {
+			batch = batch[:purgeBatch]
+		}
+		ids = ids[len(batch):]
+		q, args, err := sqlx.In(`
+			SELECT log_file FROM worm_run WHERE job_id IN (?) AND IFNULL(log_file,'')<>'';
+		`, batch)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		if err := h.dbSelect(&names, h.Db.Rebind(q), args...); err != nil {
+			return nil, err
+		}
+		logs = append(logs, names...)
+		queries := []string{
+			`DELETE FROM worm_run WHERE job_id IN (?);`,
+			`DELETE FROM worm WHERE id IN (?);`,
+		}
+		if len(h.fts) > 0 {
+			queries = append([]string{
+				`DELETE FROM worm_log_fts WHERE rowid IN (SELECT id FROM worm_run WHERE job_id IN (?));`,
+			}, queries...)
+		}
+		for _, q := range queries {
+			q, args, err := sqlx.In(q, batch)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := h.dbExec(h.Db.Rebind(q), args...); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return logs, nil
+}
+
+// Delete _
+func Delete(jobID string) error {
+	return defaultWorm.Delete(jobID)
+}
+
+// Restore _
+func Restore(jobID string) error {
+	return defaultWorm.Restore(jobID)
+}
+
+// Purge _
+func Purge(t time.Time) (int, error) {
+	return defaultWorm.Purge(t)
+}
@@ -0,0 +1,51 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDelete(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	d := &countDoer{}
+	h.MustRegister("count", d)
+
+	jobID, err := h.Queue("count", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Delete(jobID); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Delete(jobID); err == nil {
+		t.Fatalf("deleted twice : expected error")
+	}
+	if _, err := h.Detail(jobID); err == nil {
+		t.Fatalf("detail : expected deleted job hidden")
+	}
+	// deleted jobs never run.
+	h.Tick(start.Add(time.Minute))
+	if d.runs != 0 {
+		t.Fatalf("runs : expected 0 got [%d]", d.runs)
+	}
+
+	if err := h.Restore(jobID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Detail(jobID); err != nil {
+		t.Fatalf("restored : err [%s]", err)
+	}
+
+	h.Delete(jobID)
+	if n, err := h.Purge(start); err != nil || n != 0 {
+		t.Fatalf("purge before delete : got [%d] err [%v]", n, err)
+	}
+	if n, err := h.Purge(start.Add(time.Hour)); err != nil || n != 1 {
+		t.Fatalf("purge : got [%d] err [%v]", n, err)
+	}
+	if err := h.Restore(jobID); err == nil {
+		t.Fatalf("restore purged : expected error")
+	}
+}
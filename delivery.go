@@ -0,0 +1,81 @@
+package worm
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Guarantee is the delivery guarantee of the jobs of a worker.
+type Guarantee int
+
+const (
+	// AtLeastOnce dispatches the jobs whose run was interrupted, by a crash
+	// or an expired lease, again until a run records its result. Doers
+	// must tolerate running a job twice. The default.
+	AtLeastOnce Guarantee = iota
+	// AtMostOnce never runs a single-execution job twice: the jobs whose run
+	// was interrupted finish with StatusLost. Jobs claimed but not started
+	// yet are dispatched again.
+	AtMostOnce
+)
+
+// String returns the name of the guarantee.
+func (g Guarantee) String() string {
+	if g == AtMostOnce {
+		return "at-most-once"
+	}
+	return "at-least-once"
+}
+
+// StatusLost is the status of the at-most-once jobs whose run was
+// interrupted.
+const StatusLost = -4
+
+// Delivery sets the delivery guarantee of the worker jobs.
+func Delivery(g Guarantee) WorkerOption {
+	return func(w *worker) error {
+		if g != AtLeastOnce && g != AtMostOnce {
+			return errors.New("worm: unknown delivery guarantee")
+		}
+		w.delivery = g
+		return nil
+	}
+}
+
+// atMostOnce returns the names of the registered at-most-once workers.
+func (h *Worm) atMostOnce() []string {
+	h.RLock()
+	defer h.RUnlock()
+	var names []string
+	for name, wk := range h.workers {
+		if wk.delivery == AtMostOnce {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// loseInterrupted finishes with StatusLost the single-execution jobs of the
+// at-most-once workers matching cond whose run is still open, before their
+// claims are freed. Must run before the open runs are closed.
+func (h *Worm) loseInterrupted(tx *sqlx.Tx, now time.Time, reason, cond string, args ...interface{}) error {
+	names := h.atMostOnce()
+	if len(names) < 1 {
+		return nil
+	}
+	q, xargs, err := sqlx.In(`
+		UPDATE worm SET status=?,error=?,finished_at=?,updated_at=?,claimed_by='',claimed_until=NULL
+		WHERE cron='' AND finished_at IS NULL AND worker_name IN (?) AND `+cond+`
+		AND EXISTS (
+			SELECT 1 FROM worm_run
+			WHERE worm_run.job_id=worm.id AND worm_run.finished_at IS NULL
+		);
+	`, append([]interface{}{StatusLost, "lost: " + reason, now, now, names}, args...)...)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(tx.Rebind(q), xargs...)
+	return err
+}
@@ -0,0 +1,64 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDelivery(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	h.MustRegister("once", &testDoer{name: "once", status: StatusOK}, Delivery(AtMostOnce))
+	h.MustRegister("twice", &testDoer{name: "twice", status: StatusOK})
+
+	// a hub claimed the jobs and hung, after starting the runs of started.
+	var ids []string
+	for _, x := range []struct {
+		worker  string
+		started bool
+	}{
+		{"once", true},
+		{"once", false},
+		{"twice", true},
+	} {
+		jobID, err := h.Queue(x.worker, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, jobID)
+		h.Db.MustExec(`UPDATE worm SET claimed_by='hung',claimed_until=? WHERE id=?;`, start.Add(30*time.Second), jobID)
+		if x.started {
+			h.Db.MustExec(`INSERT INTO worm_run (job_id,instance_id,status,started_at) VALUES (?,'hung',?,?);`, jobID, StatusStart, start)
+		}
+	}
+
+	h.Pause()
+	h.Tick(start.Add(time.Minute))
+	pass, err := h.Sweep()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pass.Stale != 2 {
+		t.Fatalf("sweep : expected 2 stale got [%+v]", pass)
+	}
+
+	job, err := h.Detail(ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != StatusLost || job.Error != "lost: lease expired" {
+		t.Fatalf("interrupted once : got status [%d] error [%s]", job.Status, job.Error)
+	}
+	if ok, err := h.claim(ids[0]); err != nil || ok {
+		t.Fatalf("claim lost : got [%v] err [%v]", ok, err)
+	}
+	for _, jobID := range ids[1:] {
+		if ok, err := h.claim(jobID); err != nil || !ok {
+			t.Fatalf("claim freed : got [%v] err [%v]", ok, err)
+		}
+	}
+	if err := h.Register("bad", &testDoer{name: "bad"}, Delivery(Guarantee(9))); err == nil {
+		t.Fatal("expected unknown guarantee error")
+	}
+}
@@ -0,0 +1,51 @@
+package worm
+
+import "errors"
+
+// ErrQueueFull is returned by Queue when the hub or the worker reached its
+// pending jobs limit.
+var ErrQueueFull = errors.New("worm: queue full")
+
+// MaxPending limits the jobs of the worker queued for a single execution and
+// not finished yet. Beyond n Queue returns ErrQueueFull.
+func MaxPending(n int) WorkerOption {
+	return func(w *worker) error {
+		if n < 1 {
+			return errors.New("worm: max pending must be positive")
+		}
+		w.maxPending = n
+		return nil
+	}
+}
+
+// full returns ErrQueueFull when the hub or wk reached its pending jobs
+// limit. Must be called holding waitc.
+func (h *Worm) full(wk *worker) error {
+	if h.maxPending > 0 {
+		var n int
+		err := h.dbGet(&n, `
+			SELECT COUNT(*) FROM worm
+			WHERE cron='' AND finished_at IS NULL AND deleted_at IS NULL;
+		`)
+		if err != nil {
+			return err
+		}
+		if n >= h.maxPending {
+			return ErrQueueFull
+		}
+	}
+	if wk.maxPending > 0 {
+		var n int
+		err := h.dbGet(&n, `
+			SELECT COUNT(*) FROM worm
+			WHERE worker_name=? AND cron='' AND finished_at IS NULL AND deleted_at IS NULL;
+		`, wk.name)
+		if err != nil {
+			return err
+		}
+		if n >= wk.maxPending {
+			return ErrQueueFull
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaxPending(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start), WithMaxPending(3))
+	defer closeTestWorm(t, h)
+	h.MustRegister("a", &testDoer{name: "a"}, MaxPending(1))
+	h.MustRegister("b", &testDoer{name: "b"})
+
+	if _, err := h.Queue("a", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Queue("a", nil); err != ErrQueueFull {
+		t.Fatalf("worker limit : expected ErrQueueFull got [%v]", err)
+	}
+	var plan Plan
+	if _, err := h.Queue("a", nil, DryRun(&plan)); err != ErrQueueFull {
+		t.Fatalf("dry run : expected ErrQueueFull got [%v]", err)
+	}
+	h.Queue("b", nil)
+	h.Queue("b", nil)
+	if _, err := h.Queue("b", nil); err != ErrQueueFull {
+		t.Fatalf("hub limit : expected ErrQueueFull got [%v]", err)
+	}
+	// schedules are not pending jobs.
+	if _, err := h.Sched("b", nil, "0 0 * * * *"); err != nil {
+		t.Fatalf("sched : err [%s]", err)
+	}
+
+	h.Tick(start.Add(time.Minute))
+	if _, err := h.Queue("a", nil); err != nil {
+		t.Fatalf("after run : err [%s]", err)
+	}
+}
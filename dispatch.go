@@ -0,0 +1,159 @@
+package worm
+
+import (
+	"log"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// queue stores a single execution job and runs it when due.
+func (h *Worm) queue(workerName string, data []byte, opts *jobOptions) (string, error) {
+	if opts.window != nil {
+		if !opts.window.valid() {
+			return "", errWindow
+		}
+		if opts.runAt.IsZero() {
+			opts.runAt = h.now()
+		}
+		opts.runAt = opts.window.next(opts.runAt)
+	}
+	if opts.plan != nil {
+		next := opts.runAt
+		if next.IsZero() {
+			next = h.now()
+		}
+		return "", h.dryRun(workerName, data, next, opts)
+	}
+	doer, jobID, err := h.store(workerName, data, opts)
+	if err == errDuplicate {
+		log.Printf("queue : worker [%s] duplicated job dropped : job id [%s]", workerName, jobID)
+		return jobID, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	// big payloads are not kept in memory, run loads them.
+	if h.blobbed(data) {
+		data = nil
+	}
+	h.dispatch(doer, jobID, data, opts.runAt)
+	return jobID, nil
+}
+
+// dispatch runs the stored single execution job at once if due at runAt.
+// Later jobs are run by poll, or by Tick in manual tick mode.
+func (h *Worm) dispatch(doer Doer, jobID string, data []byte, runAt time.Time) {
+	if h.manual() || runAt.After(h.now()) {
+		return
+	}
+	h.start(doer, jobID, data)
+}
+
+// dueJob is a single execution job ready to run.
+type dueJob struct {
+	ID     string     `db:"id"`
+	Worker string     `db:"worker_name"`
+	Data   []byte     `db:"data"`
+	RunAt  *time.Time `db:"run_at"`
+}
+
+// due returns up to limit unclaimed single execution jobs of the registered
+// and not paused workers due at t, oldest first.
+func (h *Worm) due(t time.Time, limit int) ([]*dueJob, error) {
+	return h.dueOf(h.workerNames(), t, limit)
+}
+
+// workerNames returns the names of the registered workers this hub runs.
+func (h *Worm) workerNames() []string {
+	h.RLock()
+	defer h.RUnlock()
+	names := make([]string, 0, len(h.workers))
+	for name, wk := range h.workers {
+		if h.serves(wk) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// dueOf is due for the named workers.
+func (h *Worm) dueOf(names []string, t time.Time, limit int) ([]*dueJob, error) {
+	if len(names) < 1 {
+		return nil, nil
+	}
+
+	now := h.now()
+	q, args, err := sqlx.In(`
+		SELECT id, worker_name, data, run_at FROM worm
+		WHERE cron='' AND finished_at IS NULL AND status<>? AND deleted_at IS NULL
+		AND (run_at IS NULL OR run_at<=?)
+		AND (claimed_until IS NULL OR claimed_until<?)
+		AND worker_name IN (?)
+		AND worker_name NOT IN (
+			SELECT worker_name FROM worm_paused
+			WHERE paused_until IS NULL OR paused_until>?
+		)
+		ORDER BY run_at, rowid
+		LIMIT ?;
+	`, StatusWaiting, t, now, names, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []*dueJob
+	o := <-h.waitc
+	err = h.dbSelect(&jobs, h.Db.Rebind(q), args...)
+	h.waitc <- o
+	return jobs, err
+}
+
+// maxClaimed limits the due jobs selected per worker on each poll.
+const maxClaimed = 100
+
+// claimPending runs the due single execution jobs nobody holds.
+func (h *Worm) claimPending() {
+	if h.Paused() {
+		return
+	}
+	jobs, err := h.due(h.now(), maxClaimed)
+	// a worker backlog fills the batch, fair dispatch asks each worker.
+	if h.fair && h.pool != nil && err == nil && len(jobs) == maxClaimed {
+		jobs = nil
+		for _, name := range h.workerNames() {
+			list, err := h.dueOf([]string{name}, h.now(), maxClaimed)
+			if err != nil {
+				log.Printf("claimPending : select : err [%s] worker [%s]", err, name)
+				continue
+			}
+			jobs = append(jobs, list...)
+		}
+	}
+	if err != nil {
+		log.Printf("claimPending : select : err [%s]", err)
+		return
+	}
+	for _, job := range jobs {
+		h.RLock()
+		wk, ok := h.workers[job.Worker]
+		h.RUnlock()
+		if !ok {
+			continue
+		}
+		h.start(wk.doer, job.ID, job.Data)
+	}
+}
+
+// poll runs the due single execution jobs every poll interval: jobs queued
+// for later, by another hub or left behind by an expired lease.
+func (h *Worm) poll() {
+	ticker := time.NewTicker(h.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.quitc:
+			return
+		case <-ticker.C:
+			h.claimPending()
+		}
+	}
+}
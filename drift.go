@@ -0,0 +1,121 @@
+package worm
+
+import (
+	"log"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+// maxDriftFirings limits the firings of a schedule checked by Drift.
+const maxDriftFirings = 1000
+
+// ScheduleDrift compares the expected firings of a schedule with its runs.
+type ScheduleDrift struct {
+	ScheduleID string `json:"schedule_id"`
+	JobID      string `json:"job_id"`
+	// Expected is the number of firings due in the period, Fired the ones
+	// that started a run.
+	Expected int `json:"expected"`
+	Fired    int `json:"fired"`
+	// Missed are the due times without a run: the hub was down, paused,
+	// not the leader or the previous run was still going.
+	Missed []time.Time `json:"missed,omitempty"`
+	// Late is the number of runs started more than the tolerance after
+	// they were due, e.g. waiting for the pool.
+	Late     int           `json:"late"`
+	MaxDelay time.Duration `json:"max_delay"`
+}
+
+// Drift reports, for every enabled schedule, the firings due between since,
+// or its creation, and the tolerance before now, missed or started later
+// than tolerance. Expected firings follow the current spec; the schedules
+// this hub doesn't run are checked without window or calendar. Manual runs
+// don't count.
+func (h *Worm) Drift(since time.Time, tolerance time.Duration) ([]*ScheduleDrift, error) {
+	var list []*Schedule
+	o := <-h.waitc
+	err := h.dbSelect(&list, selectSchedule+` AND disabled_at IS NULL ORDER BY created_at, rowid;`)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("Drift : err [%s]", err)
+		return nil, err
+	}
+	to := h.now().Add(-tolerance)
+	var report []*ScheduleDrift
+	for _, s := range list {
+		from := since.UTC()
+		if s.CreatedAt.After(from) {
+			from = s.CreatedAt
+		}
+		d, err := h.drift(s, from, to, tolerance)
+		if err != nil {
+			log.Printf("Drift : err [%s] schedule id [%s]", err, s.ID)
+			return nil, err
+		}
+		report = append(report, d)
+	}
+	return report, nil
+}
+
+// drift checks the firings of s due between from and to.
+func (h *Worm) drift(s *Schedule, from, to time.Time, tolerance time.Duration) (*ScheduleDrift, error) {
+	d := &ScheduleDrift{ScheduleID: s.ID, JobID: s.JobID}
+	var schedule cron.Schedule
+	h.RLock()
+	e, ok := h.crons[s.JobID]
+	h.RUnlock()
+	if ok {
+		schedule = e.schedule
+	} else {
+		parsed, err := h.parseSpec(s.Spec, from)
+		if err != nil {
+			return nil, err
+		}
+		schedule = h.splayed(parsed)
+	}
+	var runs []struct {
+		DueAt     time.Time `db:"due_at"`
+		StartedAt time.Time `db:"started_at"`
+	}
+	o := <-h.waitc
+	err := h.dbSelect(&runs, `
+		SELECT due_at, started_at FROM worm_run
+		WHERE job_id=? AND IFNULL(manual,0)=0 AND due_at>=? AND due_at<=?;
+	`, s.JobID, from, to)
+	h.waitc <- o
+	if err != nil {
+		return nil, err
+	}
+	started := make(map[int64]time.Time, len(runs))
+	for _, run := range runs {
+		started[run.DueAt.UnixNano()] = run.StartedAt
+	}
+	due := from
+	for i := 0; i < maxDriftFirings; i++ {
+		due = schedule.Next(due)
+		if due.IsZero() || due.After(to) {
+			break
+		}
+		d.Expected++
+		at, ok := started[due.UnixNano()]
+		if !ok {
+			d.Missed = append(d.Missed, due)
+			continue
+		}
+		d.Fired++
+		delay := at.Sub(due)
+		if delay > tolerance {
+			d.Late++
+		}
+		if delay > d.MaxDelay {
+			d.MaxDelay = delay
+		}
+	}
+	return d, nil
+}
+
+// Drift _
+func Drift(since time.Time, tolerance time.Duration) ([]*ScheduleDrift, error) {
+	return defaultWorm.Drift(since, tolerance)
+}
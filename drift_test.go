@@ -0,0 +1,69 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDrift(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	h.MustRegister("ok", &testDoer{name: "ok"})
+
+	jobID, err := h.Sched("ok", nil, "0 0 * * * *", ScheduleID("hourly"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	disabled, err := h.Sched("ok", nil, "0 0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.DisableSchedule(disabled); err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(time.Hour))
+	// the hub is down at 2:00.
+	h.Pause()
+	h.Tick(start.Add(2 * time.Hour))
+	h.Resume()
+	h.Tick(start.Add(3 * time.Hour))
+	if err := h.TriggerSchedule("hourly"); err != nil {
+		t.Fatal(err)
+	}
+	// the 3:00 firing waited 5 minutes.
+	h.Db.MustExec(`UPDATE worm_run SET started_at=? WHERE job_id=? AND due_at=?;`,
+		start.Add(3*time.Hour+5*time.Minute), jobID, start.Add(3*time.Hour))
+
+	report, err := h.Drift(time.Time{}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report) != 1 {
+		t.Fatalf("expected 1 schedule got [%d]", len(report))
+	}
+	d := report[0]
+	if d.ScheduleID != "hourly" || d.JobID != jobID || d.Expected != 2 || d.Fired != 1 || d.Late != 0 {
+		t.Fatalf("within tolerance : got [%+v]", d)
+	}
+	if len(d.Missed) != 1 || !d.Missed[0].Equal(start.Add(2*time.Hour)) {
+		t.Fatalf("missed : got [%v]", d.Missed)
+	}
+
+	h.Tick(start.Add(4 * time.Hour))
+	report, err = h.Drift(start.Add(90*time.Minute), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d = report[0]
+	if d.Expected != 3 || d.Fired != 2 || d.Late != 1 || d.MaxDelay != 5*time.Minute || len(d.Missed) != 1 {
+		t.Fatalf("since : got [%+v]", d)
+	}
+	runs, err := h.Runs(jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs[0].DueAt == nil || !runs[0].DueAt.Equal(start.Add(time.Hour)) {
+		t.Fatalf("due at : got [%v]", runs[0].DueAt)
+	}
+}
@@ -0,0 +1,56 @@
+package worm
+
+import "time"
+
+// Plan describes what Queue or Sched would do for a job.
+type Plan struct {
+	Worker string `json:"worker"`
+	// Cron is empty for jobs queued for a single execution.
+	Cron string `json:"cron,omitempty"`
+	// NextRun is the first execution time.
+	NextRun time.Time `json:"next_run"`
+	// Duplicate is the ID of the job returned instead if the worker dedup
+	// window drops this one.
+	Duplicate string `json:"duplicate,omitempty"`
+	// Blob is true when the data goes to the blob store.
+	Blob bool `json:"blob"`
+}
+
+// DryRun makes Queue and Sched validate the job and fill plan without
+// storing or scheduling anything. They return an empty job ID and the error
+// the real call would fail with.
+func DryRun(plan *Plan) JobOption {
+	return func(o *jobOptions) {
+		o.plan = plan
+	}
+}
+
+// dryRun validates the job and fills opts.plan.
+func (h *Worm) dryRun(workerName string, data []byte, next time.Time, opts *jobOptions) error {
+	wk, err := h.accept(workerName, data)
+	if err != nil {
+		return err
+	}
+	now := h.now()
+	*opts.plan = Plan{
+		Worker:  workerName,
+		Cron:    opts.cron,
+		NextRun: next,
+		Blob:    h.blobbed(data),
+	}
+	o := <-h.waitc
+	defer func() {
+		h.waitc <- o
+	}()
+	if wk.dedup > 0 {
+		dupID, err := h.duplicate(workerName, payloadHash(data), now.Add(-wk.dedup))
+		if err != nil || len(dupID) > 0 {
+			opts.plan.Duplicate = dupID
+			return err
+		}
+	}
+	if opts.cron == once {
+		return h.full(wk)
+	}
+	return nil
+}
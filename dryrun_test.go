@@ -0,0 +1,43 @@
+package worm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	h := newTestWorm(t)
+	defer closeTestWorm(t, h)
+	h.MustRegister("a", &testDoer{name: "a"}, Validate(func(data []byte) error {
+		if string(data) == "bad" {
+			return errors.New("bad data")
+		}
+		return nil
+	}))
+
+	var plan Plan
+	jobID, err := h.Sched("a", []byte("ok"), "0 0 3 * * *", DryRun(&plan))
+	if err != nil || jobID != "" {
+		t.Fatalf("dry run : got [%s] err [%v]", jobID, err)
+	}
+	if plan.Worker != "a" || plan.Cron != "0 0 3 * * *" || plan.NextRun.Hour() != 3 {
+		t.Fatalf("plan : got [%+v]", plan)
+	}
+	if _, err := h.Sched("a", []byte("ok"), "not a cron", DryRun(&plan)); err == nil {
+		t.Fatalf("bad cron : expected error")
+	}
+	if _, err := h.Queue("a", []byte("bad"), DryRun(&plan)); err == nil {
+		t.Fatalf("bad data : expected error")
+	}
+	if _, err := h.Queue("missing", nil, DryRun(&plan)); err == nil {
+		t.Fatalf("missing worker : expected error")
+	}
+
+	st, err := h.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Workers[0].Pending != 0 {
+		t.Fatalf("dry run stored jobs : got [%+v]", st.Workers[0])
+	}
+}
@@ -0,0 +1,48 @@
+package worm
+
+import "time"
+
+// Event types.
+const (
+	// EventQuarantine is emitted when a worker is quarantined.
+	EventQuarantine = "quarantine"
+	// EventQueued is emitted when a job is stored.
+	EventQueued = "queued"
+	// EventStarted is emitted when a job run starts.
+	EventStarted = "started"
+	// EventSucceeded is emitted when a job run succeeds.
+	EventSucceeded = "succeeded"
+	// EventFailed is emitted when a job run fails.
+	EventFailed = "failed"
+	// EventExpired is emitted when a job TTL passes before it runs.
+	EventExpired = "expired"
+)
+
+// Event is a notification of the hub.
+type Event struct {
+	Type   string `json:"type"`
+	Worker string `json:"worker,omitempty"`
+	JobID  string `json:"job_id,omitempty"`
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// TraceID is the trace ID of the job.
+	TraceID string    `json:"trace_id,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// WithEventHandler sets fn to receive the hub events. It is called on the
+// goroutine that caused the event and must not block.
+func WithEventHandler(fn func(Event)) Option {
+	return func(h *Worm) {
+		h.onEvent = fn
+	}
+}
+
+// emit sends e to the event handler.
+func (h *Worm) emit(e Event) {
+	if h.onEvent == nil {
+		return
+	}
+	e.Time = h.now()
+	h.onEvent(e)
+}
@@ -0,0 +1,39 @@
+package worm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJobEvents(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	var events []Event
+	h := newTestWorm(t, WithManualTick(start), WithEventHandler(func(e Event) {
+		events = append(events, e)
+	}))
+	defer closeTestWorm(t, h)
+	h.MustRegister("ok", &testDoer{name: "ok"})
+	h.MustRegister("fail", &testDoer{name: "fail", status: 2, err: errors.New("boom")})
+
+	okID, _ := h.Queue("ok", nil)
+	h.Tick(start.Add(time.Minute))
+	failID, _ := h.Queue("fail", nil)
+	h.Tick(start.Add(2 * time.Minute))
+
+	types := []string{EventQueued, EventStarted, EventSucceeded, EventQueued, EventStarted, EventFailed}
+	if len(events) != len(types) {
+		t.Fatalf("expected [%d] events got [%+v]", len(types), events)
+	}
+	for i, typ := range types {
+		if events[i].Type != typ {
+			t.Errorf("event %d : expected [%s] got [%+v]", i, typ, events[i])
+		}
+	}
+	if e := events[2]; e.JobID != okID || e.Worker != "ok" {
+		t.Errorf("succeeded : got [%+v]", e)
+	}
+	if e := events[5]; e.JobID != failID || e.Status != 2 || e.Error != "boom" {
+		t.Errorf("failed : got [%+v]", e)
+	}
+}
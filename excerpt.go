@@ -0,0 +1,54 @@
+package worm
+
+import (
+	"io"
+	"os"
+)
+
+// DetailOption configures Detail.
+type DetailOption func(*detailOptions)
+
+// detailOptions are the settings of Detail.
+type detailOptions struct {
+	// excerpt is the size of the log head and tail, none when zero.
+	excerpt int64
+}
+
+// LogExcerpt includes the first and last n bytes of the job log in
+// Job.LogHead and Job.LogTail, saving a CopyLog call for short logs. Logs up
+// to 2n bytes are whole in LogHead.
+func LogExcerpt(n int) DetailOption {
+	return func(o *detailOptions) {
+		if n > 0 {
+			o.excerpt = int64(n)
+		}
+	}
+}
+
+// readExcerpt returns the first and last n bytes of the file, the whole
+// file as head when up to 2n bytes.
+func readExcerpt(name string, n int64) (string, string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return "", "", err
+	}
+	if fi.Size() <= 2*n {
+		b := make([]byte, fi.Size())
+		_, err := io.ReadFull(f, b)
+		return string(b), "", err
+	}
+	head := make([]byte, n)
+	if _, err := io.ReadFull(f, head); err != nil {
+		return "", "", err
+	}
+	tail := make([]byte, n)
+	if _, err := f.ReadAt(tail, fi.Size()-n); err != nil {
+		return "", "", err
+	}
+	return string(head), string(tail), nil
+}
@@ -0,0 +1,38 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLogExcerpt(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	h.MustRegister("print", &printDoer{n: 100})
+
+	jobID, err := h.Queue("print", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, err := h.Detail(jobID, LogExcerpt(10))
+	if err != nil || job.LogHead != "" {
+		t.Fatalf("not run : got head [%s] err [%v]", job.LogHead, err)
+	}
+	h.Tick(start)
+	job, err = h.Detail(jobID, LogExcerpt(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(job.LogHead) != 10 || len(job.LogTail) != 10 {
+		t.Fatalf("excerpt : got head [%s] tail [%s]", job.LogHead, job.LogTail)
+	}
+	size := len(traceHeader(t, h, jobID)) + 100
+	job, err = h.Detail(jobID, LogExcerpt(size))
+	if err != nil || len(job.LogHead) != size || job.LogTail != "" {
+		t.Fatalf("whole : got head [%d] tail [%d] err [%v]", len(job.LogHead), len(job.LogTail), err)
+	}
+	if job, _ := h.Detail(jobID); job.LogHead != "" {
+		t.Fatalf("expected no excerpt without option")
+	}
+}
@@ -0,0 +1,80 @@
+package worm
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+const (
+	// ExecNotStarted is the status of an ExecDoer job whose command could
+	// not be started.
+	ExecNotStarted = 127
+	// ExecExited is added to the non-zero exit codes of ExecDoer commands,
+	// so exit 1 becomes status 257 instead of StatusStart.
+	ExecExited = 256
+	// ExecSignaled is the status of an ExecDoer job whose command was
+	// killed by a signal.
+	ExecSignaled = 512
+)
+
+// ExecDoer is a Doer that runs a command with the job data on stdin. Stdout
+// and stderr are written to the job log and the exit code, offset by
+// ExecExited, becomes the job status.
+type ExecDoer struct {
+	// WorkerName is the name returned by Name.
+	WorkerName string
+	// Path is the command to run.
+	Path string
+	// Args are the command arguments.
+	Args []string
+	// Env is added to the environment of the hub process.
+	Env []string
+	// Dir is the working directory, empty for the current one.
+	Dir string
+}
+
+// NewExecDoer returns an ExecDoer named workerName running path with args.
+func NewExecDoer(workerName, path string, args ...string) *ExecDoer {
+	return &ExecDoer{
+		WorkerName: workerName,
+		Path:       path,
+		Args:       args,
+	}
+}
+
+// Name implements Doer.
+func (x *ExecDoer) Name() string {
+	return x.WorkerName
+}
+
+// Run implements Doer.
+func (x *ExecDoer) Run(data []byte, logOutput io.Writer) (int, error) {
+	cmd := exec.Command(x.Path, x.Args...)
+	cmd.Dir = x.Dir
+	if len(x.Env) > 0 {
+		cmd.Env = append(os.Environ(), x.Env...)
+	}
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = logOutput
+	cmd.Stderr = logOutput
+
+	err := cmd.Run()
+	if err == nil {
+		return StatusOK, nil
+	}
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		return ExecNotStarted, err
+	}
+	ws, ok := exitErr.Sys().(syscall.WaitStatus)
+	if !ok {
+		return ExecNotStarted, err
+	}
+	if ws.Signaled() {
+		return ExecSignaled, err
+	}
+	return ExecExited + ws.ExitStatus(), err
+}
@@ -0,0 +1,40 @@
+package worm
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExecDoer(t *testing.T) {
+	var table = []struct {
+		Script string
+		Data   string
+		Status int
+		Output string
+	}{
+		{"cat", "hello", StatusOK, "hello"},
+		{"cat; exit 3", "fail", ExecExited + 3, "fail"},
+		{"echo oops >&2; exit 2", "", ExecExited + 2, "oops\n"},
+		{"exit 1", "", ExecExited + 1, ""},
+		{"kill -9 $$", "", ExecSignaled, ""},
+	}
+	for _, x := range table {
+		var buf bytes.Buffer
+		d := NewExecDoer("sh", "sh", "-c", x.Script)
+		status, err := d.Run([]byte(x.Data), &buf)
+		if status != x.Status {
+			t.Errorf("%q : expected status [%d] got [%d] err [%v]", x.Script, x.Status, status, err)
+		}
+		if x.Status == StatusOK && err != nil {
+			t.Errorf("%q : err [%s]", x.Script, err)
+		}
+		if buf.String() != x.Output {
+			t.Errorf("%q : expected output [%q] got [%q]", x.Script, x.Output, buf.String())
+		}
+	}
+
+	d := NewExecDoer("missing", "/does/not/exist")
+	if status, err := d.Run(nil, &bytes.Buffer{}); status != ExecNotStarted || err == nil {
+		t.Errorf("missing : expected not started got [%d] err [%v]", status, err)
+	}
+}
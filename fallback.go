@@ -0,0 +1,33 @@
+package worm
+
+import "log"
+
+// Fallback routes the data of failed jobs of this worker to the worker
+// workerName, e.g. a manual review queue. The fallback job has the failed
+// job as parent so both attempts show in Detail.
+func Fallback(workerName string) WorkerOption {
+	return func(w *worker) error {
+		w.fallbackTo = workerName
+		return nil
+	}
+}
+
+// fallback queues the data of the failed job on the fallback worker.
+func (h *Worm) fallback(jobID, workerName string) {
+	h.RLock()
+	wk, ok := h.workers[workerName]
+	h.RUnlock()
+	if !ok || len(wk.fallbackTo) < 1 {
+		return
+	}
+	data, err := h.payload(jobID)
+	if err != nil {
+		log.Printf("fallback : payload : err [%s] job id [%s]", err, jobID)
+		return
+	}
+	opts := newJobOptions(once, nil)
+	opts.parentID = jobID
+	if _, err := h.queue(wk.fallbackTo, data, opts); err != nil {
+		log.Printf("fallback : queue [%s] : err [%s] job id [%s]", wk.fallbackTo, err, jobID)
+	}
+}
@@ -0,0 +1,37 @@
+package worm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFallback(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	review := &dataDoer{}
+	h.MustRegister("review", review)
+	h.MustRegister("ocr", &testDoer{name: "ocr", status: 2, err: errors.New("unreadable")}, Fallback("review"))
+
+	jobID, err := h.Queue("ocr", []byte("scan.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(time.Minute))
+
+	if string(review.data) != "scan.png" {
+		t.Fatalf("fallback data : got [%s]", review.data)
+	}
+	job, err := h.Detail(jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(job.Children) != 1 {
+		t.Fatalf("children : expected 1 got [%v]", job.Children)
+	}
+	fb, err := h.Detail(job.Children[0])
+	if err != nil || fb.Worker != "review" || fb.ParentID != jobID || fb.Status != StatusOK {
+		t.Fatalf("fallback job : got [%+v] err [%v]", fb, err)
+	}
+}
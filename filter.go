@@ -0,0 +1,197 @@
+package worm
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// JobFilter selects jobs. Zero fields match every job.
+type JobFilter struct {
+	ID         string
+	Worker     string
+	Group      string
+	ExternalID string
+	// CreatedAfter and CreatedBefore bound the creation time.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// Recurring selects only the jobs with a schedule.
+	Recurring bool
+	// Cursor is the ID of the last job of the previous page. Jobs returns
+	// the ones listed after it.
+	Cursor string
+	// Sort orders the jobs, SortCreated by default. They are listed in
+	// descending order unless Ascending.
+	Sort      string
+	Ascending bool
+}
+
+// Sort orders of JobFilter.
+const (
+	SortCreated = "created_at"
+	SortUpdated = "updated_at"
+	SortStatus  = "status"
+	// SortDuration orders by the longest run of the job.
+	SortDuration = "duration"
+)
+
+// ErrSort is returned for unknown sort orders.
+var ErrSort = errors.New("worm: unknown sort")
+
+// sortKeys return the sort expression of each order on the job table t.
+var sortKeys = map[string]func(t string) string{
+	SortCreated: func(t string) string {
+		return t + ".created_at"
+	},
+	SortUpdated: func(t string) string {
+		return "IFNULL(" + t + ".updated_at,'')"
+	},
+	SortStatus: func(t string) string {
+		return t + ".status"
+	},
+	SortDuration: func(t string) string {
+		return `IFNULL((SELECT MAX(julianday(r.finished_at)-julianday(r.started_at))
+			FROM worm_run AS r WHERE r.job_id=` + t + `.id),-1)`
+	},
+}
+
+// sortKey returns the sort expression of the filter, nil for unknown sorts.
+func (f JobFilter) sortKey() func(t string) string {
+	if len(f.Sort) < 1 {
+		return sortKeys[SortCreated]
+	}
+	return sortKeys[f.Sort]
+}
+
+// orderBy returns the ORDER BY clause of the filter.
+func (f JobFilter) orderBy() (string, error) {
+	key := f.sortKey()
+	if key == nil {
+		return "", ErrSort
+	}
+	dir := " DESC"
+	if f.Ascending {
+		dir = " ASC"
+	}
+	return "ORDER BY " + key("worm") + dir + ", worm.rowid" + dir, nil
+}
+
+// where returns the SQL conditions of the filter joined with AND and their
+// args.
+func (f JobFilter) where() (string, []interface{}) {
+	conds := []string{"deleted_at IS NULL"}
+	var args []interface{}
+	if len(f.ID) > 0 {
+		conds = append(conds, "id=?")
+		args = append(args, f.ID)
+	}
+	if len(f.Worker) > 0 {
+		conds = append(conds, "worker_name=?")
+		args = append(args, f.Worker)
+	}
+	if len(f.Group) > 0 {
+		conds = append(conds, "group_id=?")
+		args = append(args, f.Group)
+	}
+	if len(f.ExternalID) > 0 {
+		conds = append(conds, "external_id=?")
+		args = append(args, f.ExternalID)
+	}
+	if !f.CreatedAfter.IsZero() {
+		conds = append(conds, "created_at>=?")
+		args = append(args, f.CreatedAfter.UTC())
+	}
+	if !f.CreatedBefore.IsZero() {
+		conds = append(conds, "created_at<?")
+		args = append(args, f.CreatedBefore.UTC())
+	}
+	if f.Recurring {
+		conds = append(conds, "cron<>''")
+	}
+	if key := f.sortKey(); len(f.Cursor) > 0 && key != nil {
+		op := "<"
+		if f.Ascending {
+			op = ">"
+		}
+		conds = append(conds, "("+key("worm")+",worm.rowid)"+op+
+			"(SELECT "+key("c")+",c.rowid FROM worm AS c WHERE c.id=?)")
+		args = append(args, f.Cursor)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// Jobs returns up to limit jobs matching filter, newest first unless the
+// filter sets a sort.
+func (h *Worm) Jobs(filter JobFilter, limit int) ([]*Job, error) {
+	orderBy, err := filter.orderBy()
+	if err != nil {
+		return nil, err
+	}
+	where, args := filter.where()
+	var jobs []*Job
+	err = h.readDB(func(db *sqlx.DB) error {
+		return db.Select(&jobs, `
+			SELECT
+				id,
+				worker_name,
+				status,
+				IFNULL(error,'') AS "error",
+				log_file,
+				IFNULL(data,'') AS "data",
+				IFNULL(blob_key,'') AS "blob_key",
+				IFNULL(external_id,'') AS "external_id",
+				IFNULL(parent_id,'') AS "parent_id",
+				IFNULL(schedule_id,'') AS "schedule_id",
+				IFNULL(cron,'') AS "cron",
+				IFNULL(redact,'') AS "redact",
+				(SELECT COUNT(*) FROM worm_run WHERE worm_run.job_id=worm.id) AS "attempts",
+				created_at,
+				updated_at
+			FROM worm WHERE `+where+`
+			`+orderBy+` LIMIT ?;
+		`, append(args, limit)...)
+	})
+	if err != nil {
+		log.Printf("Jobs : retrieve : err [%s]", err)
+	}
+	h.redactJobs(jobs...)
+	return jobs, err
+}
+
+// Jobs _
+func Jobs(filter JobFilter, limit int) ([]*Job, error) {
+	return defaultWorm.Jobs(filter, limit)
+}
+
+// iterBatch is the number of jobs Iterate reads at once.
+const iterBatch = 500
+
+// Iterate calls fn with every job matching filter, in Jobs order, reading
+// them in batches so exports don't hold every job in memory. The database is
+// not locked while fn runs, so fn may use the hub. Iterate stops at the
+// first error of fn and returns it.
+func (h *Worm) Iterate(filter JobFilter, fn func(*Job) error) error {
+	for {
+		jobs, err := h.Jobs(filter, iterBatch)
+		if err != nil {
+			return err
+		}
+		for _, job := range jobs {
+			if err := fn(job); err != nil {
+				return err
+			}
+		}
+		if len(jobs) < iterBatch {
+			return nil
+		}
+		filter.Cursor = jobs[len(jobs)-1].ID
+	}
+}
+
+// Iterate _
+func Iterate(filter JobFilter, fn func(*Job) error) error {
+	return defaultWorm.Iterate(filter, fn)
+}
@@ -0,0 +1,159 @@
+package worm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJobs(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	h.MustRegister("count", &countDoer{})
+
+	first, _ := h.Queue("count", nil, Group("a"))
+	second, _ := h.Queue("count", nil, Group("a"))
+	if _, err := h.Queue("count", nil, Group("b")); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := h.Jobs(JobFilter{Group: "a"}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 || jobs[0].ID != second || jobs[1].ID != first {
+		t.Fatalf("group a : got [%d] jobs", len(jobs))
+	}
+	jobs, err = h.Jobs(JobFilter{ID: first}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != first {
+		t.Fatalf("by id : got [%d] jobs", len(jobs))
+	}
+	if jobs, _ := h.Jobs(JobFilter{}, 1); len(jobs) != 1 {
+		t.Fatalf("limit : got [%d] jobs", len(jobs))
+	}
+	jobs, err = h.Jobs(JobFilter{Group: "a", Cursor: second}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != first {
+		t.Fatalf("next page : got [%d] jobs", len(jobs))
+	}
+
+	cronID, err := h.Sched("count", nil, "0 0 3 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs, err = h.Jobs(JobFilter{Recurring: true}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != cronID || jobs[0].Cron != "0 0 3 * * *" {
+		t.Fatalf("recurring : got [%d] jobs", len(jobs))
+	}
+}
+
+func TestIterate(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+
+	n := 2*iterBatch + 3
+	if _, err := h.Db.Exec(`
+		WITH RECURSIVE seq(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM seq WHERE i<?)
+		INSERT INTO worm (id,worker_name,status,cron,created_at,updated_at)
+		SELECT printf('job-%04d',i),'count',?,'',?,? FROM seq;
+	`, n, StatusOK, start, start); err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	err := h.Iterate(JobFilter{Worker: "count"}, func(job *Job) error {
+		ids = append(ids, job.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != n || ids[0] != fmt.Sprintf("job-%04d", n) || ids[n-1] != "job-0001" {
+		t.Fatalf("iterate : got [%d] jobs", len(ids))
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] >= ids[i-1] {
+			t.Fatalf("iterate : job [%s] after [%s]", ids[i], ids[i-1])
+		}
+	}
+
+	stop := errors.New("stop")
+	var seen int
+	err = h.Iterate(JobFilter{}, func(job *Job) error {
+		seen++
+		return stop
+	})
+	if err != stop || seen != 1 {
+		t.Fatalf("stop : got [%d] jobs err [%v]", seen, err)
+	}
+}
+
+func TestJobsSort(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+
+	// fast, slow and failed jobs with runs of 1, 9 and 3 seconds.
+	for _, x := range []struct {
+		id     string
+		status int
+		secs   int
+	}{
+		{"fast", StatusOK, 1},
+		{"slow", StatusOK, 9},
+		{"failed", 2, 3},
+	} {
+		if _, err := h.Db.Exec(`
+			INSERT INTO worm (id,worker_name,status,cron,created_at,updated_at) VALUES (?,'count',?,'',?,?);
+		`, x.id, x.status, start, start); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := h.Db.Exec(`
+			INSERT INTO worm_run (job_id,instance_id,status,started_at,finished_at) VALUES (?,'a',?,?,?);
+		`, x.id, x.status, start, start.Add(time.Duration(x.secs)*time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ids := func(jobs []*Job) string {
+		var list []string
+		for _, job := range jobs {
+			list = append(list, job.ID)
+		}
+		return strings.Join(list, ",")
+	}
+	for _, tc := range []struct {
+		filter JobFilter
+		limit  int
+		ids    string
+	}{
+		{JobFilter{}, 10, "failed,slow,fast"},
+		{JobFilter{Sort: SortDuration}, 10, "slow,failed,fast"},
+		{JobFilter{Sort: SortDuration, Ascending: true}, 10, "fast,failed,slow"},
+		{JobFilter{Sort: SortStatus}, 10, "failed,slow,fast"},
+		{JobFilter{Sort: SortDuration, Cursor: "slow"}, 1, "failed"},
+		{JobFilter{Sort: SortDuration, Ascending: true, Cursor: "failed"}, 10, "slow"},
+	} {
+		jobs, err := h.Jobs(tc.filter, tc.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(jobs); got != tc.ids {
+			t.Fatalf("filter [%+v] : expected [%s] got [%s]", tc.filter, tc.ids, got)
+		}
+	}
+	if _, err := h.Jobs(JobFilter{Sort: "name"}, 10); err != ErrSort {
+		t.Fatalf("expected ErrSort got [%v]", err)
+	}
+}
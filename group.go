@@ -0,0 +1,59 @@
+package worm
+
+import "log"
+
+// GroupInfo contains the job counts of a group.
+type GroupInfo struct {
+	ID        string `json:"id"`
+	Total     int    `json:"total"`
+	Pending   int    `json:"pending"`
+	Succeeded int    `json:"succeeded"`
+	Failed    int    `json:"failed"`
+}
+
+// Group tags the job as member of the group groupID, to track bulk
+// operations as one unit with GroupStatus.
+func Group(groupID string) JobOption {
+	return func(o *jobOptions) {
+		o.groupID = groupID
+	}
+}
+
+// GroupStatus returns the job counts of the group. Unknown groups have no
+// jobs.
+func (h *Worm) GroupStatus(groupID string) (*GroupInfo, error) {
+	var rows []struct {
+		Status   int  `db:"status"`
+		Finished bool `db:"finished"`
+		Count    int  `db:"count"`
+	}
+	o := <-h.waitc
+	err := h.dbSelect(&rows, `
+		SELECT status, finished_at IS NOT NULL AS "finished", COUNT(*) AS "count"
+		FROM worm WHERE group_id=? AND deleted_at IS NULL GROUP BY status, finished;
+	`, groupID)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("GroupStatus : count : err [%s]", err)
+		return nil, err
+	}
+	x := &GroupInfo{ID: groupID}
+	for _, r := range rows {
+		x.Total += r.Count
+		// jobs finish with any status, waiting ones count as pending.
+		switch {
+		case r.Status == StatusWaiting || !r.Finished:
+			x.Pending += r.Count
+		case r.Status == StatusOK:
+			x.Succeeded += r.Count
+		default:
+			x.Failed += r.Count
+		}
+	}
+	return x, nil
+}
+
+// GroupStatus _
+func GroupStatus(groupID string) (*GroupInfo, error) {
+	return defaultWorm.GroupStatus(groupID)
+}
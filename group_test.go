@@ -0,0 +1,49 @@
+package worm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGroupStatus(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	h.MustRegister("ok", &testDoer{name: "ok"})
+	// status 1 is a failure once the job finished.
+	h.MustRegister("fail", &testDoer{name: "fail", status: 1, err: errors.New("boom")})
+
+	for i := 0; i < 3; i++ {
+		if _, err := h.Queue("ok", nil, Group("import")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := h.Queue("fail", nil, Group("import")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Queue("ok", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := h.GroupStatus("import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Total != 4 || g.Pending != 4 {
+		t.Fatalf("before run : got [%+v]", g)
+	}
+	h.Tick(start.Add(time.Minute))
+	g, err = h.GroupStatus("import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Total != 4 || g.Pending != 0 || g.Succeeded != 3 || g.Failed != 1 {
+		t.Fatalf("after run : got [%+v]", g)
+	}
+
+	g, err = h.GroupStatus("none")
+	if err != nil || g.Total != 0 {
+		t.Fatalf("unknown group : got [%+v] err [%v]", g, err)
+	}
+}
@@ -0,0 +1,71 @@
+package worm
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// ErrUnhealthy is returned when a job is queued for an unhealthy worker.
+var ErrUnhealthy = errors.New("worm: worker unhealthy")
+
+// HealthChecker can be implemented by a Doer to report its health. Jobs for
+// an unhealthy worker are rejected with ErrUnhealthy until it recovers.
+type HealthChecker interface {
+	// Healthy returns nil when the worker is able to run jobs.
+	Healthy(ctx context.Context) error
+}
+
+// healthy returns ErrUnhealthy if the last health check of the worker failed.
+func (w *worker) healthy() error {
+	w.mu.RLock()
+	err := w.healthErr
+	w.mu.RUnlock()
+	if err != nil {
+		return ErrUnhealthy
+	}
+	return nil
+}
+
+// checkHealth checks the workers every health interval until Close.
+func (h *Worm) checkHealth() {
+	ticker := time.NewTicker(h.healthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.quitc:
+			return
+		case <-ticker.C:
+			h.CheckHealth()
+		}
+	}
+}
+
+// CheckHealth runs the health check of every worker implementing
+// HealthChecker and stores the result.
+func (h *Worm) CheckHealth() {
+	h.RLock()
+	var list []*worker
+	for _, wk := range h.workers {
+		list = append(list, wk)
+	}
+	h.RUnlock()
+
+	for _, wk := range list {
+		hc, ok := wk.doer.(HealthChecker)
+		if !ok {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), h.healthInterval)
+		err := hc.Healthy(ctx)
+		cancel()
+		if err != nil {
+			log.Printf("CheckHealth : worker [%s] : err [%s]", wk.name, err)
+		}
+		wk.mu.Lock()
+		wk.healthErr = err
+		wk.checkedAt = h.now()
+		wk.mu.Unlock()
+	}
+}
@@ -0,0 +1,49 @@
+package worm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// sickDoer fails its health check.
+type sickDoer struct {
+	testDoer
+}
+
+func (d *sickDoer) Healthy(ctx context.Context) error {
+	return errors.New("smtp down")
+}
+
+func TestCheckHealth(t *testing.T) {
+	h := newTestWorm(t)
+	defer closeTestWorm(t, h)
+
+	h.MustRegister("sick", &sickDoer{testDoer{name: "sick"}})
+	h.MustRegister("fine", &testDoer{name: "fine"})
+	h.CheckHealth()
+
+	if _, err := h.Queue("sick", []byte("{}")); err != ErrUnhealthy {
+		t.Fatalf("queue sick : expected ErrUnhealthy got [%v]", err)
+	}
+	if _, err := h.Queue("fine", []byte("{}")); err != nil {
+		t.Fatalf("queue fine : err [%s]", err)
+	}
+
+	st, err := h.Stats()
+	if err != nil {
+		t.Fatalf("stats : err [%s]", err)
+	}
+	for _, ws := range st.Workers {
+		switch ws.Name {
+		case "sick":
+			if ws.Healthy || ws.HealthError != "smtp down" {
+				t.Errorf("sick : got [%+v]", ws)
+			}
+		case "fine":
+			if !ws.Healthy || ws.Pending != 1 {
+				t.Errorf("fine : got [%+v]", ws)
+			}
+		}
+	}
+}
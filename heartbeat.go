@@ -0,0 +1,228 @@
+package worm
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"time"
+)
+
+// Run is one execution of a job.
+type Run struct {
+	ID         int64      `db:"id" json:"id"`
+	JobID      string     `db:"job_id" json:"job_id"`
+	Instance   string     `db:"instance_id" json:"instance_id"`
+	Status     int        `db:"status" json:"status"`
+	Error      string     `db:"error" json:"error"`
+	StartedAt  time.Time  `db:"started_at" json:"started_at"`
+	FinishedAt *time.Time `db:"finished_at" json:"finished_at"`
+	// Version is the application version of the hub that ran it, see
+	// WithVersion.
+	Version string `db:"version" json:"version,omitempty"`
+	// LogFile is the output of this execution, empty when removed by the
+	// log retention.
+	LogFile string `db:"log_file" json:"log_file,omitempty"`
+	// Manual marks the runs fired by TriggerSchedule.
+	Manual bool `db:"manual" json:"manual,omitempty"`
+	// DueAt is when the firing of a recurring job was due, see Drift.
+	DueAt *time.Time `db:"due_at" json:"due_at,omitempty"`
+}
+
+// Runs returns the run history of the job, oldest first.
+func (h *Worm) Runs(jobID string) ([]*Run, error) {
+	var runs []*Run
+	o := <-h.waitc
+	err := h.dbSelect(&runs, `
+		SELECT id, job_id, instance_id, status, IFNULL(error,'') AS "error",
+		started_at, finished_at, IFNULL(version,'') AS "version", IFNULL(manual,0) AS "manual", due_at,
+		IFNULL(log_file,'') AS "log_file"
+		FROM worm_run WHERE job_id=? ORDER BY id;
+	`, jobID)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("Runs : select : err [%s]", err)
+		return nil, err
+	}
+	return runs, nil
+}
+
+// CopyRunLog writes the log of the run of the job to w.
+func (h *Worm) CopyRunLog(w io.Writer, jobID string, runID int64) error {
+	var name string
+	o := <-h.waitc
+	err := h.dbGet(&name, `
+		SELECT IFNULL(log_file,'') FROM worm_run WHERE id=? AND job_id=?;
+	`, runID, jobID)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("CopyRunLog : locate : err [%s]", err)
+		return err
+	}
+	return copyLog(w, name)
+}
+
+// startRun records the start of an execution of the job, due at dueAt
+// for recurring firings.
+func (h *Worm) startRun(jobID string, manual bool, dueAt time.Time) (int64, error) {
+	var due interface{}
+	if !dueAt.IsZero() {
+		due = dueAt.UTC()
+	}
+	o := <-h.waitc
+	res, err := h.dbExec(`
+		INSERT INTO worm_run (job_id,instance_id,version,manual,status,started_at,due_at)
+		VALUES (?,?,?,?,?,?,?);
+	`, jobID, h.instanceID, h.version, manual, StatusStart, h.now(), due)
+	h.waitc <- o
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// finishRun records the result of the execution.
+func (h *Worm) finishRun(runID int64, status int, errMsg string) {
+	h.finishRunLog(runID, status, errMsg, "", 0)
+}
+
+// finishRunLog records the result and the output log of the execution.
+func (h *Worm) finishRunLog(runID int64, status int, errMsg string, logFile string, logSize int64) {
+	o := <-h.waitc
+	_, err := h.dbExec(`
+		UPDATE worm_run SET status=?,error=?,finished_at=?,log_file=?,log_size=? WHERE id=?;
+	`, status, errMsg, h.now(), logFile, logSize, runID)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("finishRun : err [%s] run id [%d]", err, runID)
+	}
+}
+
+// heartbeat writes the heartbeat of this hub and recovers jobs of dead hubs
+// every heartbeat interval until Close.
+func (h *Worm) heartbeat() {
+	ticker := time.NewTicker(h.heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		if err := h.beat(); err != nil {
+			log.Printf("heartbeat : err [%s]", err)
+		}
+		if _, err := h.Recover(); err != nil {
+			log.Printf("heartbeat : recover : err [%s]", err)
+		}
+		select {
+		case <-h.quitc:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// beat writes the heartbeat of this hub.
+func (h *Worm) beat() error {
+	now := h.now()
+	o := <-h.waitc
+	defer func() {
+		h.waitc <- o
+	}()
+	res, err := h.dbExec(`
+		UPDATE worm_instance SET heartbeat_at=? WHERE id=?;
+	`, now, h.instanceID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+	_, err = h.dbExec(`
+		INSERT INTO worm_instance (id,started_at,heartbeat_at) VALUES (?,?,?);
+	`, h.instanceID, now, now)
+	return err
+}
+
+// Recover frees the jobs claimed by hubs whose heartbeat is older than three
+// heartbeat intervals so they can be claimed again. The abandoned runs are
+// closed with an error in the run history. Returns the recovered job count.
+func (h *Worm) Recover() (int, error) {
+	deadline := h.now().Add(-3 * h.heartbeatInterval)
+	var dead []string
+	o := <-h.waitc
+	err := h.dbSelect(&dead, `
+		SELECT id FROM worm_instance WHERE heartbeat_at<?;
+	`, deadline)
+	h.waitc <- o
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+	for _, instance := range dead {
+		n, err := h.recoverInstance(instance)
+		if err != nil {
+			return total, err
+		}
+		if n > 0 {
+			log.Printf("Recover : instance [%s] dead : recovered [%d] jobs", instance, n)
+			h.sweeps.add(func(s *SweepStats) {
+				s.Recovered += n
+			})
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// recoverInstance frees the unfinished jobs claimed by instance and removes
+// its heartbeat.
+func (h *Worm) recoverInstance(instance string) (int, error) {
+	now := h.now()
+	o := <-h.waitc
+	defer func() {
+		h.waitc <- o
+	}()
+	tx, err := h.Db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	err = h.loseInterrupted(tx, now, "instance stopped heartbeating", `claimed_by=?`, instance)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	_, err = tx.Exec(`
+		UPDATE worm_run SET error=?,finished_at=?
+		WHERE instance_id=? AND finished_at IS NULL;
+	`, fmt.Sprintf("recovered: instance %s stopped heartbeating", instance), now, instance)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	res, err := tx.Exec(`
+		UPDATE worm SET claimed_by='',claimed_until=NULL
+		WHERE claimed_by=? AND (cron<>'' OR finished_at IS NULL);
+	`, instance)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	_, err = tx.Exec(`DELETE FROM worm_instance WHERE id=?;`, instance)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	return int(n), tx.Commit()
+}
+
+// Runs _
+func Runs(jobID string) ([]*Run, error) {
+	return defaultWorm.Runs(jobID)
+}
+
+// CopyRunLog _
+func CopyRunLog(w io.Writer, jobID string, runID int64) error {
+	return defaultWorm.CopyRunLog(w, jobID, runID)
+}
@@ -0,0 +1,87 @@
+package worm
+
+import (
+	"bytes"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRecover(t *testing.T) {
+	h := newTestWorm(t)
+	defer closeTestWorm(t, h)
+	h.MustRegister("a", &testDoer{name: "a"})
+	_, jobID, err := h.store("a", []byte("{}"), &jobOptions{})
+	if err != nil {
+		t.Fatalf("store : err [%s]", err)
+	}
+
+	old := time.Now().UTC().Add(-time.Hour)
+	h.Db.MustExec(`INSERT INTO worm_instance (id,started_at,heartbeat_at) VALUES ('dead',?,?);`, old, old)
+	h.Db.MustExec(`UPDATE worm SET claimed_by='dead',claimed_until=? WHERE id=?;`, time.Now().UTC().Add(time.Hour), jobID)
+	h.Db.MustExec(`INSERT INTO worm_run (job_id,instance_id,status,started_at) VALUES (?,'dead',1,?);`, jobID, old)
+
+	n, err := h.Recover()
+	if err != nil || n != 1 {
+		t.Fatalf("recover : expected 1 got [%d] err [%v]", n, err)
+	}
+	if ok, err := h.claim(jobID); err != nil || !ok {
+		t.Fatalf("claim recovered : got [%v] err [%v]", ok, err)
+	}
+	runs, err := h.Runs(jobID)
+	if err != nil || len(runs) != 1 || runs[0].FinishedAt == nil || runs[0].Error == "" {
+		t.Fatalf("runs : got [%v] err [%v]", runs, err)
+	}
+}
+
+func TestRunLogs(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start), WithVersion("v1.2.0"))
+	defer closeTestWorm(t, h)
+
+	h.MustRegister("print", &printDoer{n: 4})
+	jobID, err := h.Sched("print", nil, "0 0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := h.Tick(start.Add(2 * time.Hour)); n != 2 {
+		t.Fatalf("tick : expected 2 firings got [%d]", n)
+	}
+	runs, err := h.Runs(jobID)
+	if err != nil || len(runs) != 2 {
+		t.Fatalf("runs : expected 2 got [%d] err [%v]", len(runs), err)
+	}
+	if runs[0].LogFile == "" || runs[0].LogFile == runs[1].LogFile {
+		t.Fatalf("runs : expected a log per run got [%s] [%s]", runs[0].LogFile, runs[1].LogFile)
+	}
+	for _, run := range runs {
+		if run.Version != "v1.2.0" {
+			t.Fatalf("run [%d] : expected version v1.2.0 got [%s]", run.ID, run.Version)
+		}
+		var b bytes.Buffer
+		if err := h.CopyRunLog(&b, jobID, run.ID); err != nil || b.String() != traceHeader(t, h, jobID)+"xxxx" {
+			t.Fatalf("run [%d] : got [%d] bytes err [%v]", run.ID, b.Len(), err)
+		}
+	}
+	job, err := h.Detail(jobID)
+	if err != nil || job.LogFile != runs[1].LogFile {
+		t.Fatalf("detail : expected latest run log got [%v] err [%v]", job, err)
+	}
+	if err := h.CopyRunLog(ioutil.Discard, "other", runs[0].ID); err != sql.ErrNoRows {
+		t.Fatalf("other job : expected ErrNoRows got [%v]", err)
+	}
+
+	if err := h.Delete(jobID); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := h.Purge(time.Now().Add(time.Hour)); err != nil || n != 1 {
+		t.Fatalf("purge : got [%d] err [%v]", n, err)
+	}
+	for _, run := range runs {
+		if _, err := os.Stat(run.LogFile); !os.IsNotExist(err) {
+			t.Fatalf("purge : expected log removed got [%v]", err)
+		}
+	}
+}
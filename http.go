@@ -0,0 +1,131 @@
+package worm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPDoer job statuses by response status class.
+const (
+	// HTTPRedirect 3xx response.
+	HTTPRedirect = 3
+	// HTTPClientError 4xx response.
+	HTTPClientError = 4
+	// HTTPServerError 5xx response.
+	HTTPServerError = 5
+	// HTTPNoResponse the request failed without a response.
+	HTTPNoResponse = 6
+)
+
+// httpLogLimit limits how much of the response body is written to the log.
+const httpLogLimit = 64 << 10
+
+// HTTPRequest is the payload of HTTPDoer jobs.
+type HTTPRequest struct {
+	Method string            `json:"method"`
+	URL    string            `json:"url"`
+	Header map[string]string `json:"headers"`
+	Body   string            `json:"body"`
+	Retry  HTTPRetry         `json:"retry"`
+}
+
+// HTTPRetry is the retry policy of an HTTPRequest. Requests are retried on
+// 429, 5xx responses and transport errors.
+type HTTPRetry struct {
+	// Max retries after the first attempt.
+	Max int `json:"max"`
+	// BackoffMS is the wait before the first retry, doubled on each retry.
+	BackoffMS int `json:"backoff_ms"`
+}
+
+// HTTPDoer is a Doer that makes the HTTP request described by the job data
+// and records the response to the job log.
+type HTTPDoer struct {
+	// WorkerName is the name returned by Name.
+	WorkerName string
+	// Client used for requests, http.DefaultClient when nil.
+	Client *http.Client
+}
+
+// Name implements Doer.
+func (x *HTTPDoer) Name() string {
+	return x.WorkerName
+}
+
+// Run implements Doer.
+func (x *HTTPDoer) Run(data []byte, logOutput io.Writer) (int, error) {
+	var req HTTPRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return HTTPNoResponse, err
+	}
+	if len(req.Method) < 1 {
+		req.Method = http.MethodGet
+	}
+
+	backoff := time.Duration(req.Retry.BackoffMS) * time.Millisecond
+	var status int
+	var err error
+	for attempt := 0; attempt <= req.Retry.Max; attempt++ {
+		if attempt > 0 {
+			Printf(logOutput, "retry %d in %s", attempt, backoff)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		var code int
+		code, err = x.do(&req, logOutput)
+		status = httpStatus(code)
+		if err == nil && status != HTTPServerError && code != http.StatusTooManyRequests {
+			break
+		}
+	}
+	return status, err
+}
+
+// do makes one request and returns the response status code.
+func (x *HTTPDoer) do(req *HTTPRequest, logOutput io.Writer) (int, error) {
+	r, err := http.NewRequest(req.Method, req.URL, strings.NewReader(req.Body))
+	if err != nil {
+		return 0, err
+	}
+	for k, v := range req.Header {
+		r.Header.Set(k, v)
+	}
+	Printf(logOutput, "%s %s", req.Method, req.URL)
+
+	client := x.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(r)
+	if err != nil {
+		Printf(logOutput, "ERROR: %s", err)
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	Printf(logOutput, "%s", res.Status)
+	if _, err := io.Copy(logOutput, io.LimitReader(res.Body, httpLogLimit)); err != nil {
+		Printf(logOutput, "ERROR: read body: %s", err)
+	}
+	Println(logOutput)
+	if res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("worm: http status %s", res.Status)
+	}
+	return res.StatusCode, nil
+}
+
+// httpStatus maps a response status code to the job status.
+func httpStatus(code int) int {
+	switch {
+	case code == 0:
+		return HTTPNoResponse
+	case code < 300:
+		return StatusOK
+	default:
+		return code / 100
+	}
+}
@@ -0,0 +1,57 @@
+package worm
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHTTPDoer(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/flaky":
+			if atomic.AddInt32(&calls, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("pong"))
+	}))
+	defer ts.Close()
+
+	var table = []struct {
+		Path   string
+		Retry  int
+		Status int
+	}{
+		{"/ping", 0, StatusOK},
+		{"/missing", 3, HTTPClientError},
+		{"/flaky", 1, HTTPServerError},
+		{"/flaky", 3, StatusOK},
+	}
+	d := &HTTPDoer{WorkerName: "webhook"}
+	for _, x := range table {
+		b, err := json.Marshal(&HTTPRequest{
+			URL:   ts.URL + x.Path,
+			Retry: HTTPRetry{Max: x.Retry},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		status, err := d.Run(b, &buf)
+		if status != x.Status {
+			t.Errorf("%s : expected status [%d] got [%d] err [%v] log [%s]", x.Path, x.Status, status, err, buf.String())
+		}
+	}
+	if calls != 3 {
+		t.Errorf("flaky : expected 3 calls got [%d]", calls)
+	}
+}
@@ -0,0 +1,147 @@
+package worm
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// IDGenerator generates job IDs.
+type IDGenerator interface {
+	NewID() string
+}
+
+// UUIDGenerator generates random UUID v4 IDs. It is the default generator.
+type UUIDGenerator struct{}
+
+// NewID implements IDGenerator.
+func (UUIDGenerator) NewID() string {
+	return uuid.NewV4().String()
+}
+
+// crockford is the ULID base32 alphabet.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDGenerator generates ULIDs: 26 characters, lexicographically sorted by
+// creation time. IDs created in the same millisecond are monotonic.
+type ULIDGenerator struct {
+	mu      sync.Mutex
+	lastMs  uint64
+	last    [10]byte
+	entropy io.Reader
+}
+
+// NewULIDGenerator returns a ULIDGenerator reading randomness from
+// crypto/rand.
+func NewULIDGenerator() *ULIDGenerator {
+	return &ULIDGenerator{
+		entropy: rand.Reader,
+	}
+}
+
+// NewID implements IDGenerator.
+func (g *ULIDGenerator) NewID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	if ms <= g.lastMs {
+		// same millisecond or clock going back: increment the random part.
+		ms = g.lastMs
+		for i := len(g.last) - 1; i >= 0; i-- {
+			g.last[i]++
+			if g.last[i] != 0 {
+				break
+			}
+		}
+	} else {
+		entropy := g.entropy
+		if entropy == nil {
+			entropy = rand.Reader
+		}
+		if _, err := io.ReadFull(entropy, g.last[:]); err != nil {
+			panic(fmt.Sprintf("worm: ulid entropy: %s", err))
+		}
+	}
+	g.lastMs = ms
+
+	var b [16]byte
+	for i := 0; i < 6; i++ {
+		b[i] = byte(ms >> uint(40-8*i))
+	}
+	copy(b[6:], g.last[:])
+	return encodeULID(b)
+}
+
+// encodeULID encodes 128 bits as 26 base32 characters, most significant
+// bits first.
+func encodeULID(b [16]byte) string {
+	var out [26]byte
+	// 130 bits of output, the first character holds the 3 top bits.
+	var acc uint64
+	var bits uint
+	pos := 25
+	for i := 15; i >= 0; i-- {
+		acc |= uint64(b[i]) << bits
+		bits += 8
+		for bits >= 5 && pos >= 0 {
+			out[pos] = crockford[acc&31]
+			acc >>= 5
+			bits -= 5
+			pos--
+		}
+	}
+	for pos >= 0 {
+		out[pos] = crockford[acc&31]
+		acc >>= 5
+		pos--
+	}
+	return string(out[:])
+}
+
+// snowflakeEpoch is the start of SnowflakeGenerator timestamps.
+var snowflakeEpoch = time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// SnowflakeGenerator generates 63 bit snowflake IDs: 41 bits of
+// milliseconds since 2016, 10 bits of node and 12 bits of sequence. IDs are
+// formatted as 19 zero padded digits so they sort lexicographically.
+type SnowflakeGenerator struct {
+	mu     sync.Mutex
+	node   int64
+	lastMs int64
+	seq    int64
+}
+
+// NewSnowflakeGenerator returns a SnowflakeGenerator for node, which must be
+// unique between hubs sharing a database and in the range 0-1023.
+func NewSnowflakeGenerator(node int64) (*SnowflakeGenerator, error) {
+	if node < 0 || node > 1023 {
+		return nil, errors.New("worm: snowflake node out of range 0-1023")
+	}
+	return &SnowflakeGenerator{node: node}, nil
+}
+
+// NewID implements IDGenerator.
+func (g *SnowflakeGenerator) NewID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ms := int64(time.Since(snowflakeEpoch) / time.Millisecond)
+	if ms < g.lastMs {
+		ms = g.lastMs
+	}
+	if ms == g.lastMs {
+		g.seq = (g.seq + 1) & 4095
+		if g.seq == 0 {
+			// sequence exhausted, borrow the next millisecond.
+			ms++
+		}
+	} else {
+		g.seq = 0
+	}
+	g.lastMs = ms
+	return fmt.Sprintf("%019d", ms<<22|g.node<<12|g.seq)
+}
@@ -0,0 +1,56 @@
+package worm
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestIDGenerators(t *testing.T) {
+	snow, err := NewSnowflakeGenerator(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var table = []struct {
+		Name   string
+		Gen    IDGenerator
+		Length int
+	}{
+		{"ulid", NewULIDGenerator(), 26},
+		{"snowflake", snow, 19},
+	}
+	for _, x := range table {
+		var ids []string
+		seen := make(map[string]bool)
+		for i := 0; i < 10000; i++ {
+			id := x.Gen.NewID()
+			if len(id) != x.Length {
+				t.Fatalf("%s : expected length %d got [%s]", x.Name, x.Length, id)
+			}
+			if seen[id] {
+				t.Fatalf("%s : duplicated id [%s]", x.Name, id)
+			}
+			seen[id] = true
+			ids = append(ids, id)
+		}
+		if !sort.StringsAreSorted(ids) {
+			t.Errorf("%s : ids not sorted by creation", x.Name)
+		}
+	}
+
+	if _, err := NewSnowflakeGenerator(1024); err == nil {
+		t.Errorf("snowflake : expected node error")
+	}
+}
+
+func TestEncodeULID(t *testing.T) {
+	var b [16]byte
+	if got := encodeULID(b); got != "00000000000000000000000000" {
+		t.Errorf("zero : got [%s]", got)
+	}
+	for i := range b {
+		b[i] = 0xff
+	}
+	if got := encodeULID(b); got != "7ZZZZZZZZZZZZZZZZZZZZZZZZZ" {
+		t.Errorf("max : got [%s]", got)
+	}
+}
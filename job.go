@@ -0,0 +1,120 @@
+package worm
+
+import (
+	"log"
+	"time"
+)
+
+// JobOption configures a job on Queue and Sched.
+type JobOption func(*jobOptions)
+
+// jobOptions are the settings of a new job.
+type jobOptions struct {
+	// cron is the stored cron format, once for single executions.
+	cron string
+	// runAt is when single executions are due, now when zero.
+	runAt time.Time
+	// ttl expires single executions not started within ttl of runAt.
+	ttl        time.Duration
+	id         string
+	externalID string
+	groupID    string
+	parentID   string
+	// continuations are queued when the job finishes.
+	continuations []*continuation
+	// calendar holidays are skipped by recurring jobs.
+	calendar *Calendar
+	// window defers the job to the time of day it may run.
+	window *window
+	// semaphore limits to semaphoreMax the running jobs holding it.
+	semaphore    string
+	semaphoreMax int
+	// plan is filled instead of storing the job on dry runs.
+	plan *Plan
+	// metadata is stored with the job and passed to the Doer.
+	metadata JobMetadata
+	// traceID correlates the job with the request that queued it.
+	traceID string
+	// scheduleID identifies recurring jobs apart from their job ID.
+	scheduleID string
+
+	// workflow step settings.
+	workflowID string
+	step       string
+	after      []string
+	fanIn      bool
+	// waiting stores the job with StatusWaiting.
+	waiting bool
+}
+
+// newJobOptions applies opts.
+func newJobOptions(cronformat string, opts []JobOption) *jobOptions {
+	o := &jobOptions{
+		cron: cronformat,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// JobID sets the job ID instead of generating one. Queue fails if the ID is
+// already taken.
+func JobID(id string) JobOption {
+	return func(o *jobOptions) {
+		o.id = id
+	}
+}
+
+// ExternalID stores a business reference with the job, like an order number
+// or a webhook delivery ID, to find it later with ByExternalID.
+func ExternalID(ref string) JobOption {
+	return func(o *jobOptions) {
+		o.externalID = ref
+	}
+}
+
+// ByExternalID returns the jobs stored with the external reference ref,
+// newest first.
+func (h *Worm) ByExternalID(ref string) ([]*Job, error) {
+	var jobs []*Job
+	o := <-h.waitc
+	err := h.dbSelect(&jobs, `
+		SELECT
+			id,
+			worker_name,
+			status,
+			IFNULL(error,'') AS "error",
+			IFNULL(data,'') AS "data",
+			IFNULL(blob_key,'') AS "blob_key",
+			IFNULL(external_id,'') AS "external_id",
+			IFNULL(parent_id,'') AS "parent_id",
+			IFNULL(schedule_id,'') AS "schedule_id",
+			IFNULL(cron,'') AS "cron",
+			IFNULL(redact,'') AS "redact",
+			(SELECT COUNT(*) FROM worm_run WHERE worm_run.job_id=worm.id) AS "attempts",
+			log_file,
+			created_at,
+			updated_at
+		FROM worm WHERE external_id=? AND deleted_at IS NULL ORDER BY created_at DESC;
+	`, ref)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("ByExternalID : select : err [%s]", err)
+		return nil, err
+	}
+	h.redactJobs(jobs...)
+	return jobs, nil
+}
+
+// ByExternalID _
+func ByExternalID(ref string) ([]*Job, error) {
+	return defaultWorm.ByExternalID(ref)
+}
+
+// Enqueuer queues and schedules jobs. It is implemented by Worm and by the
+// wormtest fake hub so application code can be tested without a database.
+type Enqueuer interface {
+	Queue(workerName string, data []byte, opts ...JobOption) (string, error)
+	Sched(workerName string, data []byte, cronformat string, opts ...JobOption) (string, error)
+}
@@ -0,0 +1,30 @@
+package worm
+
+import "testing"
+
+func TestExternalID(t *testing.T) {
+	h := newTestWorm(t)
+	defer closeTestWorm(t, h)
+	h.MustRegister("a", &testDoer{name: "a"})
+
+	jobID, err := h.Queue("a", []byte("{}"), JobID("order-1-job"), ExternalID("order-1"))
+	if err != nil || jobID != "order-1-job" {
+		t.Fatalf("queue : got [%s] err [%v]", jobID, err)
+	}
+	if _, err := h.Queue("a", []byte("{}"), JobID("order-1-job")); err == nil {
+		t.Fatalf("queue same id : expected error")
+	}
+	if _, err := h.Queue("a", []byte("{}"), ExternalID("order-1")); err != nil {
+		t.Fatalf("queue : err [%s]", err)
+	}
+
+	jobs, err := h.ByExternalID("order-1")
+	if err != nil || len(jobs) != 2 {
+		t.Fatalf("by external id : got [%d] err [%v]", len(jobs), err)
+	}
+	for _, job := range jobs {
+		if job.ExternalID != "order-1" {
+			t.Errorf("external id : got [%s]", job.ExternalID)
+		}
+	}
+}
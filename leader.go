@@ -0,0 +1,91 @@
+package worm
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// leaderName is the lease row of the cron scheduler.
+const leaderName = "scheduler"
+
+// IsLeader reports whether this hub fires recurring jobs. Always true when
+// leader election is disabled.
+func (h *Worm) IsLeader() bool {
+	if !h.election {
+		return true
+	}
+	return atomic.LoadInt32(&h.leader) == 1
+}
+
+// elect keeps trying to take or renew the scheduler lease until Close.
+func (h *Worm) elect() {
+	ticker := time.NewTicker(h.lease / 3)
+	defer ticker.Stop()
+	for {
+		h.campaign()
+		select {
+		case <-h.quitc:
+			h.resign()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// campaign takes the scheduler lease when it is free or expired, or renews
+// it when this hub already holds it.
+func (h *Worm) campaign() {
+	now := h.now()
+	until := now.Add(h.lease)
+	o := <-h.waitc
+	_, err := h.dbExec(`
+		INSERT OR IGNORE INTO worm_leader (name,holder,expires_at)
+		VALUES (?,?,?);
+	`, leaderName, h.instanceID, until)
+	var n int64
+	if err == nil {
+		res, err2 := h.dbExec(`
+			UPDATE worm_leader SET holder=?,expires_at=?
+			WHERE name=? AND (holder=? OR expires_at<?);
+		`, h.instanceID, until, leaderName, h.instanceID, now)
+		err = err2
+		if err == nil {
+			n, err = res.RowsAffected()
+		}
+	}
+	h.waitc <- o
+	if err != nil {
+		log.Printf("campaign : err [%s]", err)
+		n = 0
+	}
+
+	var v int32
+	if n == 1 {
+		v = 1
+	}
+	if old := atomic.SwapInt32(&h.leader, v); old != v {
+		log.Printf("campaign : instance [%s] leader [%v]", h.instanceID, v == 1)
+	}
+}
+
+// resign releases the scheduler lease so another hub takes over without
+// waiting for it to expire.
+func (h *Worm) resign() {
+	if atomic.SwapInt32(&h.leader, 0) != 1 {
+		return
+	}
+	o := <-h.waitc
+	_, err := h.dbExec(`
+		DELETE FROM worm_leader WHERE name=? AND holder=?;
+	`, leaderName, h.instanceID)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("resign : err [%s]", err)
+	}
+}
+
+// IsLeader _
+func IsLeader() bool {
+	return defaultWorm.IsLeader()
+}
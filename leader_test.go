@@ -0,0 +1,37 @@
+package worm
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLeaderElection(t *testing.T) {
+	h := newTestWorm(t)
+	defer closeTestWorm(t, h)
+
+	path := filepath.Join(h.logDir, "worm.db")
+	lease := WithLease(60 * time.Millisecond)
+	a, err := New(path, h.logDir, WithInstanceID("a"), WithLeaderElection(), lease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	b, err := New(path, h.logDir, WithInstanceID("b"), WithLeaderElection(), lease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	time.Sleep(30 * time.Millisecond)
+
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatalf("expected a leader got a [%v] b [%v]", a.IsLeader(), b.IsLeader())
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if !b.IsLeader() {
+		t.Fatalf("expected b to take over")
+	}
+}
@@ -0,0 +1,62 @@
+package worm
+
+import (
+	"errors"
+	"time"
+)
+
+// Concurrency limits the runs of the worker at the same time on this hub to
+// n. Jobs beyond it stay pending and run on a later poll; recurring firings
+// beyond it are skipped.
+func Concurrency(n int) WorkerOption {
+	return func(w *worker) error {
+		if n < 1 {
+			return errors.New("worm: concurrency must be positive")
+		}
+		w.concurrency = n
+		return nil
+	}
+}
+
+// RateLimit limits the runs of the worker started on this hub to n every
+// per, e.g. to respect the quota of the API it calls. Jobs beyond it stay
+// pending and run on a later poll; recurring firings beyond it are skipped.
+func RateLimit(n int, per time.Duration) WorkerOption {
+	return func(w *worker) error {
+		if n < 1 || per <= 0 {
+			return errors.New("worm: rate limit and period must be positive")
+		}
+		w.rate, w.per = n, per
+		return nil
+	}
+}
+
+// acquire takes a run slot of the worker at now. False when the worker is
+// at its concurrency or rate limit. Slots must be released.
+func (w *worker) acquire(now time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.concurrency > 0 && w.running >= w.concurrency {
+		return false
+	}
+	if w.rate > 0 {
+		i := 0
+		for i < len(w.starts) && !w.starts[i].After(now.Add(-w.per)) {
+			i++
+		}
+		w.starts = w.starts[i:]
+		if len(w.starts) >= w.rate {
+			return false
+		}
+		w.starts = append(w.starts, now)
+	}
+	w.running++
+	return true
+}
+
+// release returns a run slot taken with acquire.
+func (w *worker) release() {
+	w.mu.Lock()
+	w.running--
+	w.mu.Unlock()
+}
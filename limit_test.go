@@ -0,0 +1,86 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAcquire(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	w := &worker{name: "w"}
+	if err := Concurrency(2)(w); err != nil {
+		t.Fatal(err)
+	}
+	if !w.acquire(start) || !w.acquire(start) || w.acquire(start) {
+		t.Fatalf("concurrency : expected 2 slots")
+	}
+	w.release()
+	if !w.acquire(start) {
+		t.Fatalf("concurrency : expected released slot")
+	}
+	w.release()
+	w.release()
+
+	w = &worker{name: "w"}
+	if err := RateLimit(2, time.Minute)(w); err != nil {
+		t.Fatal(err)
+	}
+	for i, tc := range []struct {
+		at time.Duration
+		ok bool
+	}{
+		{0, true},
+		{10 * time.Second, true},
+		{30 * time.Second, false},
+		{time.Minute, true},
+		{65 * time.Second, false},
+		{70 * time.Second, true},
+	} {
+		ok := w.acquire(start.Add(tc.at))
+		if ok != tc.ok {
+			t.Fatalf("rate %d : at [%s] expected [%v]", i, tc.at, tc.ok)
+		}
+		if ok {
+			w.release()
+		}
+	}
+	if err := RateLimit(0, time.Minute)(w); err == nil {
+		t.Fatal("expected rate limit error")
+	}
+	if err := Concurrency(0)(w); err == nil {
+		t.Fatal("expected concurrency error")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	d := &countDoer{}
+	h.MustRegister("count", d, RateLimit(1, time.Hour))
+
+	for i := 0; i < 2; i++ {
+		if _, err := h.Queue("count", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h.Tick(start.Add(time.Minute))
+	if d.runs != 1 {
+		t.Fatalf("first hour : expected 1 run got [%d]", d.runs)
+	}
+	h.Tick(start.Add(2 * time.Minute))
+	if d.runs != 1 {
+		t.Fatalf("limited : expected 1 run got [%d]", d.runs)
+	}
+	// Tick runs due jobs before moving the clock to t, the pending job runs
+	// on the first tick after the period passed.
+	h.Tick(start.Add(2 * time.Hour))
+	h.Tick(start.Add(2*time.Hour + time.Minute))
+	if d.runs != 2 {
+		t.Fatalf("next hour : expected 2 runs got [%d]", d.runs)
+	}
+	list := h.Workers()
+	if len(list) != 1 || list[0].RateLimit != 1 || list[0].RatePeriod != time.Hour || list[0].Running != 0 {
+		t.Fatalf("workers : got [%+v]", list)
+	}
+}
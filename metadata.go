@@ -0,0 +1,65 @@
+package worm
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// JobMetadata are the labels of a job, like the enqueuer, the source request
+// ID or the tenant, kept apart from the business payload.
+type JobMetadata map[string]string
+
+// Value implements driver.Valuer.
+func (m JobMetadata) Value() (driver.Value, error) {
+	if len(m) < 1 {
+		return "", nil
+	}
+	b, err := json.Marshal(map[string]string(m))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (m *JobMetadata) Scan(src interface{}) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return errors.New("worm: unsupported metadata type")
+	}
+	*m = nil
+	if len(b) < 1 {
+		return nil
+	}
+	return json.Unmarshal(b, m)
+}
+
+// Metadata stores md with the job. The Doer gets it from MetadataFrom, Detail
+// returns it. Repeated options merge.
+func Metadata(md map[string]string) JobOption {
+	return func(o *jobOptions) {
+		if o.metadata == nil {
+			o.metadata = make(JobMetadata, len(md))
+		}
+		for k, v := range md {
+			o.metadata[k] = v
+		}
+	}
+}
+
+// MetadataFrom returns the metadata of the job running with ctx, the context
+// a ContextDoer receives. Nil outside a running job or without metadata.
+func MetadataFrom(ctx context.Context) map[string]string {
+	if info, ok := FromContext(ctx); ok {
+		return info.Metadata
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package worm
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+// metadataDoer keeps the job metadata of its last run.
+type metadataDoer struct {
+	md map[string]string
+}
+
+func (d *metadataDoer) Name() string {
+	return "metadata"
+}
+
+func (d *metadataDoer) Run(data []byte, w io.Writer) (int, error) {
+	return d.RunContext(context.Background(), data, w)
+}
+
+func (d *metadataDoer) RunContext(ctx context.Context, data []byte, w io.Writer) (int, error) {
+	d.md = MetadataFrom(ctx)
+	return StatusOK, nil
+}
+
+func TestMetadata(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+
+	d := &metadataDoer{}
+	h.MustRegister("metadata", d)
+	jobID, err := h.Queue("metadata", []byte(`{}`),
+		Metadata(map[string]string{"tenant": "acme", "enqueuer": "api"}),
+		Metadata(map[string]string{"request_id": "r-1"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start)
+	if len(d.md) != 3 || d.md["tenant"] != "acme" || d.md["request_id"] != "r-1" {
+		t.Fatalf("run : got [%v]", d.md)
+	}
+	job, err := h.Detail(jobID)
+	if err != nil || job.Metadata["enqueuer"] != "api" || job.Data != `{}` {
+		t.Fatalf("detail : got [%v] err [%v]", job, err)
+	}
+
+	plainID, err := h.Queue("metadata", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start)
+	if d.md != nil {
+		t.Fatalf("without metadata : got [%v]", d.md)
+	}
+	if job, err := h.Detail(plainID); err != nil || job.Metadata != nil {
+		t.Fatalf("detail without metadata : got [%v] err [%v]", job, err)
+	}
+}
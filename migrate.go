@@ -0,0 +1,104 @@
+package worm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// defaultVersionField is the payload field holding the payload version.
+const defaultVersionField = "version"
+
+// MigratePayload registers fn to upgrade, before Run, the JSON data of the
+// worker jobs whose payload version is from. fn returns the data with a
+// greater version; migrators chain, so payloads queued several deploys ago
+// are upgraded step by step. The stored payload is kept as queued. Jobs whose
+// data can't be migrated fail with StatusDecode.
+func MigratePayload(from int, fn func(data []byte) ([]byte, error)) WorkerOption {
+	return func(w *worker) error {
+		if fn == nil {
+			return fmt.Errorf("worm: worker %s: nil payload migrator", w.name)
+		}
+		if w.migrators == nil {
+			w.migrators = make(map[int]func([]byte) ([]byte, error))
+		}
+		if _, ok := w.migrators[from]; ok {
+			return fmt.Errorf("worm: worker %s: payload migrator of version %d already registered", w.name, from)
+		}
+		w.migrators[from] = fn
+		return nil
+	}
+}
+
+// VersionField sets the top level JSON field holding the payload version
+// read by the migrators. Default "version"; payloads without it are version
+// 0.
+func VersionField(name string) WorkerOption {
+	return func(w *worker) error {
+		w.versionField = name
+		return nil
+	}
+}
+
+// migrate upgrades data with the worker migrators.
+func (w *worker) migrate(data []byte) ([]byte, error) {
+	if len(w.migrators) < 1 {
+		return data, nil
+	}
+	v, err := w.payloadVersion(data)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		fn, ok := w.migrators[v]
+		if !ok {
+			return data, nil
+		}
+		data, err = fn(data)
+		if err != nil {
+			return nil, fmt.Errorf("worm: migrate payload from version %d: %s", v, err)
+		}
+		next, err := w.payloadVersion(data)
+		if err != nil {
+			return nil, err
+		}
+		if next <= v {
+			return nil, fmt.Errorf("worm: payload migrator of version %d returned version %d", v, next)
+		}
+		v = next
+	}
+}
+
+// payloadVersion reads the version field of data.
+func (w *worker) payloadVersion(data []byte) (int, error) {
+	if len(bytes.TrimSpace(data)) < 1 {
+		return 0, nil
+	}
+	field := w.versionField
+	if len(field) < 1 {
+		field = defaultVersionField
+	}
+	var x map[string]json.RawMessage
+	if err := json.Unmarshal(data, &x); err != nil {
+		return 0, fmt.Errorf("worm: payload version: %s", err)
+	}
+	raw, ok := x[field]
+	if !ok {
+		return 0, nil
+	}
+	var v int
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return 0, fmt.Errorf("worm: payload version: %s", err)
+	}
+	return v, nil
+}
+
+// migrate upgrades data of the worker named name, or of the worker whose
+// doer has that name.
+func (h *Worm) migrate(name string, data []byte) ([]byte, error) {
+	wk, ok := h.workerOf(name)
+	if !ok {
+		return data, nil
+	}
+	return wk.migrate(data)
+}
@@ -0,0 +1,97 @@
+package worm
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMigratePayload(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+
+	// version 0 had name, version 1 renamed it to user, version 2 added
+	// the locale.
+	d := &dataDoer{}
+	h.MustRegister("data", d,
+		MigratePayload(0, func(data []byte) ([]byte, error) {
+			var x struct {
+				Name string `json:"name"`
+			}
+			if err := json.Unmarshal(data, &x); err != nil {
+				return nil, err
+			}
+			if len(x.Name) < 1 {
+				return nil, errors.New("missing name")
+			}
+			return json.Marshal(map[string]interface{}{"version": 1, "user": x.Name})
+		}),
+		MigratePayload(1, func(data []byte) ([]byte, error) {
+			var x map[string]interface{}
+			if err := json.Unmarshal(data, &x); err != nil {
+				return nil, err
+			}
+			x["version"], x["locale"] = 2, "en"
+			return json.Marshal(x)
+		}),
+	)
+
+	table := []struct {
+		data   string
+		expect string
+	}{
+		{`{"name":"ann"}`, `{"locale":"en","user":"ann","version":2}`},
+		{`{"version":1,"user":"bob"}`, `{"locale":"en","user":"bob","version":2}`},
+		{`{"version":2,"user":"cy","locale":"es"}`, `{"version":2,"user":"cy","locale":"es"}`},
+	}
+	for _, x := range table {
+		jobID, err := h.Queue("data", []byte(x.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.Tick(start)
+		if string(d.data) != x.expect {
+			t.Fatalf("data [%s] : expected [%s] got [%s]", x.data, x.expect, d.data)
+		}
+		job, err := h.Detail(jobID)
+		if err != nil || job.Data != x.data {
+			t.Fatalf("data [%s] : expected stored payload kept got [%v] err [%v]", x.data, job, err)
+		}
+	}
+
+	jobID, err := h.Queue("data", []byte(`{"other":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start)
+	job, err := h.Detail(jobID)
+	if err != nil || job.Status != StatusDecode || job.Error == "" {
+		t.Fatalf("failed migration : got [%v] err [%v]", job, err)
+	}
+
+	noop := func(data []byte) ([]byte, error) { return data, nil }
+	if err := h.Register("twice", &testDoer{name: "twice"}, MigratePayload(0, noop), MigratePayload(0, noop)); err == nil {
+		t.Fatalf("expected duplicated migrator error")
+	}
+}
+
+func TestMigratePayloadVersion(t *testing.T) {
+	noop := func(data []byte) ([]byte, error) { return data, nil }
+	w := &worker{name: "w"}
+	for _, opt := range []WorkerOption{VersionField("v"), MigratePayload(3, noop)} {
+		if err := opt(w); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := w.migrate([]byte(`{"v":3}`)); err == nil {
+		t.Fatalf("expected error of migrator not upgrading the version")
+	}
+	if data, err := w.migrate([]byte(`{"version":3}`)); err != nil || string(data) != `{"version":3}` {
+		t.Fatalf("other field : got [%s] err [%v]", data, err)
+	}
+	if _, err := w.migrate([]byte(`not json`)); err == nil {
+		t.Fatalf("expected payload version error")
+	}
+}
@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS worm_claim;
+ALTER TABLE worm RENAME TO worm_old;
+CREATE TABLE worm (
+    id text PRIMARY KEY ASC,
+    worker_name TEXT,
+    status TEXT,
+    error TEXT DEFAULT '',
+    created_at DATETIME,
+    data TEXT,
+    log_file TEXT DEFAULT ''
+);
+INSERT INTO worm (id,worker_name,status,error,created_at,data,log_file)
+SELECT id,worker_name,status,error,created_at,data,log_file FROM worm_old;
+DROP TABLE worm_old;
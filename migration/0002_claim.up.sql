@@ -0,0 +1,5 @@
+ALTER TABLE worm ADD COLUMN cron TEXT DEFAULT '';
+ALTER TABLE worm ADD COLUMN finished_at DATETIME;
+ALTER TABLE worm ADD COLUMN claimed_by TEXT DEFAULT '';
+ALTER TABLE worm ADD COLUMN claimed_until DATETIME;
+CREATE INDEX worm_claim ON worm (finished_at, claimed_until);
@@ -0,0 +1,5 @@
+CREATE TABLE worm_leader (
+    name TEXT PRIMARY KEY ASC,
+    holder TEXT,
+    expires_at DATETIME
+);
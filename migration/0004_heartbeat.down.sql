@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS worm_run_job;
+DROP TABLE IF EXISTS worm_run;
+DROP TABLE IF EXISTS worm_instance;
@@ -0,0 +1,15 @@
+CREATE TABLE worm_instance (
+    id TEXT PRIMARY KEY ASC,
+    started_at DATETIME,
+    heartbeat_at DATETIME
+);
+CREATE TABLE worm_run (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id TEXT,
+    instance_id TEXT,
+    status TEXT,
+    error TEXT DEFAULT '',
+    started_at DATETIME,
+    finished_at DATETIME
+);
+CREATE INDEX worm_run_job ON worm_run (job_id);
@@ -0,0 +1,18 @@
+ALTER TABLE worm RENAME TO worm_old;
+CREATE TABLE worm (
+    id text PRIMARY KEY ASC,
+    worker_name TEXT,
+    status TEXT,
+    error TEXT DEFAULT '',
+    created_at DATETIME,
+    data TEXT,
+    log_file TEXT DEFAULT '',
+    cron TEXT DEFAULT '',
+    finished_at DATETIME,
+    claimed_by TEXT DEFAULT '',
+    claimed_until DATETIME
+);
+INSERT INTO worm (id,worker_name,status,error,created_at,data,log_file,cron,finished_at,claimed_by,claimed_until)
+SELECT id,worker_name,status,error,created_at,data,log_file,cron,finished_at,claimed_by,claimed_until FROM worm_old;
+DROP TABLE worm_old;
+CREATE INDEX worm_claim ON worm (finished_at, claimed_until);
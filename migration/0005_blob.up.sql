@@ -0,0 +1 @@
+ALTER TABLE worm ADD COLUMN blob_key TEXT DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE worm ADD COLUMN external_id TEXT DEFAULT '';
+CREATE INDEX worm_external_id ON worm (external_id);
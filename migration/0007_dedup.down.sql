@@ -0,0 +1,22 @@
+DROP INDEX IF EXISTS worm_dedup;
+ALTER TABLE worm RENAME TO worm_old;
+CREATE TABLE worm (
+    id text PRIMARY KEY ASC,
+    worker_name TEXT,
+    status TEXT,
+    error TEXT DEFAULT '',
+    created_at DATETIME,
+    data TEXT,
+    log_file TEXT DEFAULT '',
+    cron TEXT DEFAULT '',
+    finished_at DATETIME,
+    claimed_by TEXT DEFAULT '',
+    claimed_until DATETIME,
+    blob_key TEXT DEFAULT '',
+    external_id TEXT DEFAULT ''
+);
+INSERT INTO worm (id,worker_name,status,error,created_at,data,log_file,cron,finished_at,claimed_by,claimed_until,blob_key,external_id)
+SELECT id,worker_name,status,error,created_at,data,log_file,cron,finished_at,claimed_by,claimed_until,blob_key,external_id FROM worm_old;
+DROP TABLE worm_old;
+CREATE INDEX worm_claim ON worm (finished_at, claimed_until);
+CREATE INDEX worm_external_id ON worm (external_id);
@@ -0,0 +1,2 @@
+ALTER TABLE worm ADD COLUMN payload_hash TEXT DEFAULT '';
+CREATE INDEX worm_dedup ON worm (worker_name, payload_hash, created_at);
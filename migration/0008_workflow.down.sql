@@ -0,0 +1,24 @@
+DROP INDEX IF EXISTS worm_workflow;
+ALTER TABLE worm RENAME TO worm_old;
+CREATE TABLE worm (
+    id text PRIMARY KEY ASC,
+    worker_name TEXT,
+    status TEXT,
+    error TEXT DEFAULT '',
+    created_at DATETIME,
+    data TEXT,
+    log_file TEXT DEFAULT '',
+    cron TEXT DEFAULT '',
+    finished_at DATETIME,
+    claimed_by TEXT DEFAULT '',
+    claimed_until DATETIME,
+    blob_key TEXT DEFAULT '',
+    external_id TEXT DEFAULT '',
+    payload_hash TEXT DEFAULT ''
+);
+INSERT INTO worm (id,worker_name,status,error,created_at,data,log_file,cron,finished_at,claimed_by,claimed_until,blob_key,external_id,payload_hash)
+SELECT id,worker_name,status,error,created_at,data,log_file,cron,finished_at,claimed_by,claimed_until,blob_key,external_id,payload_hash FROM worm_old;
+DROP TABLE worm_old;
+CREATE INDEX worm_claim ON worm (finished_at, claimed_until);
+CREATE INDEX worm_external_id ON worm (external_id);
+CREATE INDEX worm_dedup ON worm (worker_name, payload_hash, created_at);
@@ -0,0 +1,4 @@
+ALTER TABLE worm ADD COLUMN workflow_id TEXT DEFAULT '';
+ALTER TABLE worm ADD COLUMN step TEXT DEFAULT '';
+ALTER TABLE worm ADD COLUMN after_steps TEXT DEFAULT '';
+CREATE INDEX worm_workflow ON worm (workflow_id);
@@ -0,0 +1 @@
+ALTER TABLE worm ADD COLUMN fan_in INTEGER DEFAULT 0;
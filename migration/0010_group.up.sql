@@ -0,0 +1,2 @@
+ALTER TABLE worm ADD COLUMN group_id TEXT DEFAULT '';
+CREATE INDEX worm_group ON worm (group_id);
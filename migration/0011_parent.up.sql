@@ -0,0 +1,2 @@
+ALTER TABLE worm ADD COLUMN parent_id TEXT DEFAULT '';
+CREATE INDEX worm_parent ON worm (parent_id);
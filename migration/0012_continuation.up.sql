@@ -0,0 +1 @@
+ALTER TABLE worm ADD COLUMN continuations TEXT DEFAULT '';
@@ -0,0 +1,4 @@
+CREATE TABLE worm_paused (
+    worker_name TEXT PRIMARY KEY ASC,
+    paused_at DATETIME
+);
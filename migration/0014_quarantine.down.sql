@@ -0,0 +1,8 @@
+ALTER TABLE worm_paused RENAME TO worm_paused_old;
+CREATE TABLE worm_paused (
+    worker_name TEXT PRIMARY KEY ASC,
+    paused_at DATETIME
+);
+INSERT INTO worm_paused (worker_name,paused_at)
+SELECT worker_name,paused_at FROM worm_paused_old WHERE paused_until IS NULL;
+DROP TABLE worm_paused_old;
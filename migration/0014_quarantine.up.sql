@@ -0,0 +1 @@
+ALTER TABLE worm_paused ADD COLUMN paused_until DATETIME;
@@ -0,0 +1 @@
+ALTER TABLE worm ADD COLUMN updated_at DATETIME;
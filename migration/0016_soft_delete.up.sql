@@ -0,0 +1 @@
+ALTER TABLE worm ADD COLUMN deleted_at DATETIME;
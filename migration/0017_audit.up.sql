@@ -0,0 +1,8 @@
+CREATE TABLE worm_audit (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id TEXT,
+    status TEXT,
+    reason TEXT DEFAULT '',
+    created_at DATETIME
+);
+CREATE INDEX worm_audit_job ON worm_audit (job_id);
@@ -0,0 +1,7 @@
+CREATE TABLE worm_note (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id TEXT,
+    text TEXT,
+    created_at DATETIME
+);
+CREATE INDEX worm_note_job ON worm_note (job_id);
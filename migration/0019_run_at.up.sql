@@ -0,0 +1,3 @@
+ALTER TABLE worm ADD COLUMN run_at DATETIME;
+CREATE INDEX worm_run_at ON worm (run_at);
+UPDATE worm SET run_at=created_at WHERE cron='';
@@ -0,0 +1,3 @@
+ALTER TABLE worm ADD COLUMN semaphore TEXT DEFAULT '';
+ALTER TABLE worm ADD COLUMN semaphore_max INTEGER DEFAULT 0;
+CREATE INDEX worm_semaphore ON worm (semaphore, claimed_until);
@@ -0,0 +1 @@
+ALTER TABLE worm ADD COLUMN disabled_at DATETIME;
@@ -0,0 +1 @@
+ALTER TABLE worm ADD COLUMN expires_at DATETIME;
@@ -0,0 +1,2 @@
+ALTER TABLE worm ADD COLUMN log_size INTEGER DEFAULT 0;
+CREATE INDEX worm_log_size ON worm (log_size, finished_at);
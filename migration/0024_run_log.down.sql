@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS worm_run_log_size;
+DROP INDEX IF EXISTS worm_run_job;
+ALTER TABLE worm_run RENAME TO worm_run_old;
+CREATE TABLE worm_run (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id TEXT,
+    instance_id TEXT,
+    status TEXT,
+    error TEXT DEFAULT '',
+    started_at DATETIME,
+    finished_at DATETIME
+);
+INSERT INTO worm_run (id,job_id,instance_id,status,error,started_at,finished_at)
+SELECT id,job_id,instance_id,status,error,started_at,finished_at FROM worm_run_old;
+DROP TABLE worm_run_old;
+CREATE INDEX worm_run_job ON worm_run (job_id);
@@ -0,0 +1,3 @@
+ALTER TABLE worm_run ADD COLUMN log_file TEXT DEFAULT '';
+ALTER TABLE worm_run ADD COLUMN log_size INTEGER DEFAULT 0;
+CREATE INDEX worm_run_log_size ON worm_run (log_size, finished_at);
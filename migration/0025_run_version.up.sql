@@ -0,0 +1 @@
+ALTER TABLE worm_run ADD COLUMN version TEXT DEFAULT '';
@@ -0,0 +1,5 @@
+CREATE TABLE worm_config (
+    worker_name TEXT PRIMARY KEY ASC,
+    config TEXT,
+    updated_at DATETIME
+);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS worm_change_update;
+DROP TRIGGER IF EXISTS worm_change_insert;
+DROP TABLE IF EXISTS worm_change;
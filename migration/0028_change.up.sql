@@ -0,0 +1,29 @@
+CREATE TABLE worm_change (
+    seq INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id TEXT,
+    worker_name TEXT,
+    kind TEXT,
+    status TEXT,
+    error TEXT DEFAULT '',
+    finished_at DATETIME,
+    changed_at DATETIME
+);
+CREATE TRIGGER worm_change_insert AFTER INSERT ON worm
+BEGIN
+    INSERT INTO worm_change (job_id,worker_name,kind,status,error,finished_at,changed_at)
+    VALUES (NEW.id,NEW.worker_name,'queued',NEW.status,IFNULL(NEW.error,''),NEW.finished_at,IFNULL(NEW.updated_at,NEW.created_at));
+END;
+CREATE TRIGGER worm_change_update AFTER UPDATE OF status,finished_at,deleted_at,disabled_at ON worm
+WHEN NEW.status IS NOT OLD.status OR NEW.finished_at IS NOT OLD.finished_at
+    OR NEW.deleted_at IS NOT OLD.deleted_at OR NEW.disabled_at IS NOT OLD.disabled_at
+BEGIN
+    INSERT INTO worm_change (job_id,worker_name,kind,status,error,finished_at,changed_at)
+    VALUES (NEW.id,NEW.worker_name,
+        CASE
+            WHEN NEW.deleted_at IS NOT OLD.deleted_at THEN 'deleted'
+            WHEN NEW.disabled_at IS NOT OLD.disabled_at AND NEW.disabled_at IS NULL THEN 'enabled'
+            WHEN NEW.disabled_at IS NOT OLD.disabled_at THEN 'disabled'
+            ELSE 'status'
+        END,
+        NEW.status,IFNULL(NEW.error,''),NEW.finished_at,NEW.updated_at);
+END;
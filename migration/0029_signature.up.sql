@@ -0,0 +1 @@
+ALTER TABLE worm ADD COLUMN signature TEXT DEFAULT '';
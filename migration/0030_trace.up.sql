@@ -0,0 +1 @@
+ALTER TABLE worm ADD COLUMN trace_id TEXT DEFAULT '';
@@ -0,0 +1,67 @@
+DROP INDEX IF EXISTS worm_schedule;
+ALTER TABLE worm RENAME TO worm_old;
+CREATE TABLE worm (
+    id text PRIMARY KEY ASC,
+    worker_name TEXT,
+    status TEXT,
+    error TEXT DEFAULT '',
+    created_at DATETIME,
+    data TEXT,
+    log_file TEXT DEFAULT '',
+    cron TEXT DEFAULT '',
+    finished_at DATETIME,
+    claimed_by TEXT DEFAULT '',
+    claimed_until DATETIME,
+    blob_key TEXT DEFAULT '',
+    external_id TEXT DEFAULT '',
+    payload_hash TEXT DEFAULT '',
+    workflow_id TEXT DEFAULT '',
+    step TEXT DEFAULT '',
+    after_steps TEXT DEFAULT '',
+    fan_in INTEGER DEFAULT 0,
+    group_id TEXT DEFAULT '',
+    parent_id TEXT DEFAULT '',
+    continuations TEXT DEFAULT '',
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    run_at DATETIME,
+    semaphore TEXT DEFAULT '',
+    semaphore_max INTEGER DEFAULT 0,
+    disabled_at DATETIME,
+    expires_at DATETIME,
+    log_size INTEGER DEFAULT 0,
+    metadata TEXT DEFAULT '',
+    signature TEXT DEFAULT '',
+    trace_id TEXT DEFAULT ''
+);
+INSERT INTO worm (id,worker_name,status,error,created_at,data,log_file,cron,finished_at,claimed_by,claimed_until,blob_key,external_id,payload_hash,workflow_id,step,after_steps,fan_in,group_id,parent_id,continuations,updated_at,deleted_at,run_at,semaphore,semaphore_max,disabled_at,expires_at,log_size,metadata,signature,trace_id)
+SELECT id,worker_name,status,error,created_at,data,log_file,cron,finished_at,claimed_by,claimed_until,blob_key,external_id,payload_hash,workflow_id,step,after_steps,fan_in,group_id,parent_id,continuations,updated_at,deleted_at,run_at,semaphore,semaphore_max,disabled_at,expires_at,log_size,metadata,signature,trace_id FROM worm_old;
+DROP TABLE worm_old;
+CREATE INDEX worm_claim ON worm (finished_at, claimed_until);
+CREATE INDEX worm_external_id ON worm (external_id);
+CREATE INDEX worm_dedup ON worm (worker_name, payload_hash, created_at);
+CREATE INDEX worm_workflow ON worm (workflow_id);
+CREATE INDEX worm_group ON worm (group_id);
+CREATE INDEX worm_parent ON worm (parent_id);
+CREATE INDEX worm_run_at ON worm (run_at);
+CREATE INDEX worm_semaphore ON worm (semaphore, claimed_until);
+CREATE INDEX worm_log_size ON worm (log_size, finished_at);
+CREATE TRIGGER worm_change_insert AFTER INSERT ON worm
+BEGIN
+    INSERT INTO worm_change (job_id,worker_name,kind,status,error,finished_at,changed_at)
+    VALUES (NEW.id,NEW.worker_name,'queued',NEW.status,IFNULL(NEW.error,''),NEW.finished_at,IFNULL(NEW.updated_at,NEW.created_at));
+END;
+CREATE TRIGGER worm_change_update AFTER UPDATE OF status,finished_at,deleted_at,disabled_at ON worm
+WHEN NEW.status IS NOT OLD.status OR NEW.finished_at IS NOT OLD.finished_at
+    OR NEW.deleted_at IS NOT OLD.deleted_at OR NEW.disabled_at IS NOT OLD.disabled_at
+BEGIN
+    INSERT INTO worm_change (job_id,worker_name,kind,status,error,finished_at,changed_at)
+    VALUES (NEW.id,NEW.worker_name,
+        CASE
+            WHEN NEW.deleted_at IS NOT OLD.deleted_at THEN 'deleted'
+            WHEN NEW.disabled_at IS NOT OLD.disabled_at AND NEW.disabled_at IS NULL THEN 'enabled'
+            WHEN NEW.disabled_at IS NOT OLD.disabled_at THEN 'disabled'
+            ELSE 'status'
+        END,
+        NEW.status,IFNULL(NEW.error,''),NEW.finished_at,NEW.updated_at);
+END;
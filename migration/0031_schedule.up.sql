@@ -0,0 +1,3 @@
+ALTER TABLE worm ADD COLUMN schedule_id TEXT DEFAULT '';
+UPDATE worm SET schedule_id=id WHERE cron<>'';
+CREATE UNIQUE INDEX worm_schedule ON worm (schedule_id) WHERE schedule_id<>'' AND deleted_at IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE worm_run ADD COLUMN manual INTEGER DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE worm_run ADD COLUMN due_at DATETIME;
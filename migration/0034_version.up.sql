@@ -0,0 +1,6 @@
+ALTER TABLE worm ADD COLUMN version INTEGER DEFAULT 0;
+CREATE TRIGGER worm_version AFTER UPDATE ON worm
+WHEN NEW.version IS OLD.version
+BEGIN
+    UPDATE worm SET version=IFNULL(OLD.version,0)+1 WHERE id=NEW.id;
+END;
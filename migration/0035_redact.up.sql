@@ -0,0 +1 @@
+ALTER TABLE worm ADD COLUMN redact TEXT DEFAULT '';
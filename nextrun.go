@@ -0,0 +1,56 @@
+package worm
+
+import (
+	"database/sql"
+	"log"
+	"time"
+)
+
+// scheduleInfo sets the next firing and the last run of the recurring job
+// d. Jobs scheduled by other hubs get the next firing of their bare spec,
+// without splay, window or calendar.
+func (h *Worm) scheduleInfo(d *Job) error {
+	var disabledAt *time.Time
+	var last Run
+	o := <-h.waitc
+	err := h.dbGet(&disabledAt, `
+		SELECT disabled_at FROM worm WHERE id=?;
+	`, d.ID)
+	if err == nil {
+		err = h.dbGet(&last, `
+			SELECT id, job_id, instance_id, status, IFNULL(error,'') AS "error",
+			started_at, finished_at, IFNULL(version,'') AS "version", IFNULL(manual,0) AS "manual", due_at,
+			IFNULL(log_file,'') AS "log_file"
+			FROM worm_run WHERE job_id=? ORDER BY id DESC LIMIT 1;
+		`, d.ID)
+		if err == sql.ErrNoRows {
+			err = nil
+		} else if err == nil {
+			d.LastRun = &last
+		}
+	}
+	h.waitc <- o
+	if err != nil {
+		log.Printf("scheduleInfo : select : err [%s] job id [%s]", err, d.ID)
+		return err
+	}
+	if disabledAt != nil {
+		return nil
+	}
+
+	now := h.now()
+	h.RLock()
+	e, ok := h.crons[d.ID]
+	h.RUnlock()
+	var next time.Time
+	if ok && !e.retired() {
+		next = e.schedule.Next(now)
+	} else if schedule, err := h.parseSpec(d.Cron, now); err == nil {
+		next = schedule.Next(now)
+	}
+	if !next.IsZero() {
+		next = next.UTC()
+		d.NextRun = &next
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetailSchedule(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+
+	h.MustRegister("data", &dataDoer{})
+	jobID, err := h.Sched("data", nil, "0 0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, err := h.Detail(jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.LastRun != nil || job.NextRun == nil || !job.NextRun.Equal(start.Add(time.Hour)) {
+		t.Fatalf("not run : got next [%v] last [%v]", job.NextRun, job.LastRun)
+	}
+
+	if _, err := h.Tick(start.Add(90 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	job, err = h.Detail(jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.NextRun == nil || !job.NextRun.Equal(start.Add(2*time.Hour)) {
+		t.Fatalf("next : expected 2:00 got [%v]", job.NextRun)
+	}
+	if job.LastRun == nil || job.LastRun.Status != StatusOK || job.LastRun.FinishedAt == nil {
+		t.Fatalf("last : got [%+v]", job.LastRun)
+	}
+
+	if err := h.DisableSchedule(jobID); err != nil {
+		t.Fatal(err)
+	}
+	job, err = h.Detail(jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.NextRun != nil || job.LastRun == nil {
+		t.Fatalf("disabled : got next [%v] last [%v]", job.NextRun, job.LastRun)
+	}
+
+	single, err := h.Queue("data", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, err = h.Detail(single)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.NextRun != nil || job.LastRun != nil {
+		t.Fatalf("single execution : got next [%v] last [%v]", job.NextRun, job.LastRun)
+	}
+}
@@ -0,0 +1,45 @@
+package worm
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+// Note is a free form operator note of a job.
+type Note struct {
+	Text      string    `db:"text" json:"text"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Annotate attaches the note text to the job, e.g. "retried after fixing
+// DNS". Notes show in Detail.
+func (h *Worm) Annotate(jobID, text string) error {
+	if len(text) < 1 {
+		return errors.New("worm: empty note")
+	}
+	var n int
+	o := <-h.waitc
+	err := h.dbGet(&n, `
+		SELECT COUNT(*) FROM worm WHERE id=? AND deleted_at IS NULL;
+	`, jobID)
+	if err == nil && n < 1 {
+		err = ErrNotFound
+	}
+	if err == nil {
+		_, err = h.dbExec(`
+			INSERT INTO worm_note (job_id,text,created_at) VALUES (?,?,?);
+		`, jobID, text, h.now())
+	}
+	h.waitc <- o
+	if err != nil {
+		log.Printf("Annotate : err [%s] job id [%s]", err, jobID)
+		return err
+	}
+	return nil
+}
+
+// Annotate _
+func Annotate(jobID, text string) error {
+	return defaultWorm.Annotate(jobID, text)
+}
@@ -0,0 +1,34 @@
+package worm
+
+import "testing"
+
+func TestAnnotate(t *testing.T) {
+	h := newTestWorm(t)
+	defer closeTestWorm(t, h)
+	h.MustRegister("ok", &testDoer{name: "ok"})
+
+	jobID, err := h.Queue("ok", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Annotate(jobID, "retried after fixing DNS"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Annotate(jobID, "all good now"); err != nil {
+		t.Fatal(err)
+	}
+	job, err := h.Detail(jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(job.Notes) != 2 || job.Notes[0].Text != "retried after fixing DNS" {
+		t.Fatalf("notes : got [%+v]", job.Notes)
+	}
+
+	if err := h.Annotate("none", "x"); err == nil {
+		t.Errorf("unknown job : expected error")
+	}
+	if err := h.Annotate(jobID, ""); err == nil {
+		t.Errorf("empty note : expected error")
+	}
+}
@@ -0,0 +1,174 @@
+package worm
+
+import "time"
+
+// Option configures a Worm hub on New.
+type Option func(*Worm)
+
+// WithHealthInterval sets how often workers implementing HealthChecker are
+// checked. Default 30 seconds.
+func WithHealthInterval(d time.Duration) Option {
+	return func(h *Worm) {
+		if d > 0 {
+			h.healthInterval = d
+		}
+	}
+}
+
+// WithInstanceID sets the identifier this hub uses when claiming jobs.
+// Default hostname:pid plus a random suffix.
+func WithInstanceID(id string) Option {
+	return func(h *Worm) {
+		if len(id) > 0 {
+			h.instanceID = id
+		}
+	}
+}
+
+// WithVersion sets the application version, e.g. the deployed commit,
+// recorded on every run of this hub so failures can be traced to a deploy.
+func WithVersion(v string) Option {
+	return func(h *Worm) {
+		h.version = v
+	}
+}
+
+// WithLease sets how long a claimed job stays owned by this hub without
+// renewal. Running jobs renew their lease, so it only expires when the hub
+// dies. Default 30 seconds.
+func WithLease(d time.Duration) Option {
+	return func(h *Worm) {
+		if d > 0 {
+			h.lease = d
+		}
+	}
+}
+
+// WithLeaderElection makes hubs sharing a database elect one leader to fire
+// recurring jobs. The others take over when the leader lease expires.
+func WithLeaderElection() Option {
+	return func(h *Worm) {
+		h.election = true
+	}
+}
+
+// WithSplay delays the recurring jobs of this hub by an offset below max,
+// derived from the instance ID. Hubs running the same schedules, e.g. one
+// per region, fire them staggered instead of in lockstep. Set WithInstanceID
+// for offsets stable across restarts.
+func WithSplay(max time.Duration) Option {
+	return func(h *Worm) {
+		if max > 0 {
+			h.splay = max
+		}
+	}
+}
+
+// WithVisibilityTimeout switches the hub to visibility timeout delivery: a
+// claimed job stays invisible to other hubs for d and its claim is not
+// renewed. Jobs not acked in time are delivered again, so Doers must be
+// idempotent.
+func WithVisibilityTimeout(d time.Duration) Option {
+	return func(h *Worm) {
+		if d > 0 {
+			h.visibility = d
+		}
+	}
+}
+
+// WithHeartbeat sets how often the hub writes its heartbeat. Jobs claimed by
+// hubs silent for three intervals are recovered. Default 10 seconds.
+func WithHeartbeat(d time.Duration) Option {
+	return func(h *Worm) {
+		if d > 0 {
+			h.heartbeatInterval = d
+		}
+	}
+}
+
+// WithBlobStore stores payloads bigger than threshold bytes in store, the job
+// row keeps only the blob key. Payloads are fetched right before Run.
+func WithBlobStore(store BlobStore, threshold int) Option {
+	return func(h *Worm) {
+		h.blobs = store
+		h.blobThreshold = threshold
+	}
+}
+
+// WithCodec sets the Codec used by QueueValue, SchedValue and RegisterValue.
+// Default JSONCodec.
+func WithCodec(c Codec) Option {
+	return func(h *Worm) {
+		if c != nil {
+			h.codec = c
+		}
+	}
+}
+
+// WithIDGenerator sets the job ID generator. Default UUIDGenerator.
+func WithIDGenerator(g IDGenerator) Option {
+	return func(h *Worm) {
+		if g != nil {
+			h.ids = g
+		}
+	}
+}
+
+// WithManualTick makes the hub scheduler ignore wall clock time: hub time
+// starts at start and jobs fire only when Tick is called, single executions
+// included. Useful for deterministic tests and batch tools.
+func WithManualTick(start time.Time) Option {
+	return func(h *Worm) {
+		h.croner = &manualScheduler{now: start.UTC()}
+	}
+}
+
+// WithPollInterval sets how often the hub looks for due single executions:
+// jobs queued for later, by another hub or left behind by an expired lease.
+// Jobs queued for now by this hub run at once. Default 1 second.
+func WithPollInterval(d time.Duration) Option {
+	return func(h *Worm) {
+		if d > 0 {
+			h.pollInterval = d
+		}
+	}
+}
+
+// WithSweepInterval sets how often the hub runs Sweep. Default 1 minute.
+func WithSweepInterval(d time.Duration) Option {
+	return func(h *Worm) {
+		if d > 0 {
+			h.sweepInterval = d
+		}
+	}
+}
+
+// WithPoolSize runs at most n jobs at once on this hub, the due jobs wait
+// their turn in the database. Default unlimited, each job runs on its own
+// goroutine.
+func WithPoolSize(n int) Option {
+	return func(h *Worm) {
+		if n > 0 {
+			h.poolSize = n
+		}
+	}
+}
+
+// WithFairDispatch takes the jobs waiting for the pool round-robin across
+// workers, by their Weight, instead of in arrival order, so one worker with
+// a big backlog can't monopolize the pool. Only with WithPoolSize.
+func WithFairDispatch() Option {
+	return func(h *Worm) {
+		h.fair = true
+	}
+}
+
+// WithMaxPending limits the jobs queued for a single execution and not
+// finished yet. Beyond n Queue returns ErrQueueFull. Default unlimited.
+func WithMaxPending(n int) Option {
+	return func(h *Worm) {
+		if n > 0 {
+			h.maxPending = n
+		}
+	}
+}
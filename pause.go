@@ -0,0 +1,120 @@
+package worm
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// Pause stops the hub from starting executions. Jobs are still stored and
+// running ones finish. Firings of schedules while paused are skipped, jobs
+// queued for a single execution run on Resume.
+func (h *Worm) Pause() {
+	atomic.StoreInt32(&h.paused, 1)
+}
+
+// Resume starts executions again after Pause and runs the pending jobs.
+func (h *Worm) Resume() {
+	if atomic.CompareAndSwapInt32(&h.paused, 1, 0) {
+		h.claimPending()
+	}
+}
+
+// Paused reports if the hub is paused.
+func (h *Worm) Paused() bool {
+	return atomic.LoadInt32(&h.paused) == 1
+}
+
+// Pause _
+func Pause() {
+	defaultWorm.Pause()
+}
+
+// Resume _
+func Resume() {
+	defaultWorm.Resume()
+}
+
+// Paused _
+func Paused() bool {
+	return defaultWorm.Paused()
+}
+
+// PauseWorker stops the hub and every hub sharing the database from starting
+// executions of the worker. Jobs are still stored. The pause is kept in the
+// database until ResumeWorker.
+func (h *Worm) PauseWorker(workerName string) error {
+	o := <-h.waitc
+	_, err := h.dbExec(`
+		INSERT OR REPLACE INTO worm_paused (worker_name,paused_at) VALUES (?,?);
+	`, workerName, h.now())
+	h.waitc <- o
+	if err != nil {
+		log.Printf("PauseWorker : err [%s] worker [%s]", err, workerName)
+		return err
+	}
+	h.setPaused(workerName, true, time.Time{})
+	return nil
+}
+
+// ResumeWorker starts executions of the worker again and runs its pending
+// jobs. It also ends quarantines.
+func (h *Worm) ResumeWorker(workerName string) error {
+	o := <-h.waitc
+	_, err := h.dbExec(`DELETE FROM worm_paused WHERE worker_name=?;`, workerName)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("ResumeWorker : err [%s] worker [%s]", err, workerName)
+		return err
+	}
+	h.setPaused(workerName, false, time.Time{})
+	h.claimPending()
+	return nil
+}
+
+// setPaused sets the pause state reported by Workers. Zero until pauses
+// until resumed.
+func (h *Worm) setPaused(workerName string, paused bool, until time.Time) {
+	h.RLock()
+	wk, ok := h.workers[workerName]
+	h.RUnlock()
+	if !ok {
+		return
+	}
+	wk.mu.Lock()
+	wk.paused, wk.pausedUntil = paused, until
+	wk.mu.Unlock()
+}
+
+// workerPaused returns the pause state of the worker in the database.
+func (h *Worm) workerPaused(workerName string) (bool, time.Time) {
+	var list []struct {
+		Until *time.Time `db:"paused_until"`
+	}
+	o := <-h.waitc
+	err := h.dbSelect(&list, `
+		SELECT paused_until FROM worm_paused WHERE worker_name=?;
+	`, workerName)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("workerPaused : err [%s] worker [%s]", err, workerName)
+	}
+	if len(list) < 1 {
+		return false, time.Time{}
+	}
+	until := list[0].Until
+	if until == nil {
+		return true, time.Time{}
+	}
+	return until.After(h.now()), *until
+}
+
+// PauseWorker _
+func PauseWorker(workerName string) error {
+	return defaultWorm.PauseWorker(workerName)
+}
+
+// ResumeWorker _
+func ResumeWorker(workerName string) error {
+	return defaultWorm.ResumeWorker(workerName)
+}
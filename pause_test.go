@@ -0,0 +1,81 @@
+package worm
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPause(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	d := &countDoer{}
+	h.MustRegister("count", d)
+
+	h.Pause()
+	if !h.Paused() {
+		t.Fatalf("expected paused hub")
+	}
+	if _, err := h.Queue("count", nil); err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(time.Minute))
+	if n := atomic.LoadInt32(&d.runs); n != 0 {
+		t.Fatalf("paused : expected no runs got [%d]", n)
+	}
+
+	h.Resume()
+	if h.Paused() {
+		t.Fatalf("expected running hub")
+	}
+	// pending jobs run in the background on resume.
+	for i := 0; i < 100 && atomic.LoadInt32(&d.runs) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&d.runs); n != 1 {
+		t.Fatalf("resumed : expected 1 run got [%d]", n)
+	}
+}
+
+func TestPauseWorker(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	paused := &countDoer{}
+	other := &countDoer{}
+	h.MustRegister("paused", paused)
+	h.MustRegister("other", other)
+
+	if err := h.PauseWorker("paused"); err != nil {
+		t.Fatal(err)
+	}
+	if !h.Workers()[1].Paused {
+		t.Fatalf("expected paused worker [%+v]", h.Workers())
+	}
+	h.Queue("paused", nil)
+	h.Queue("other", nil)
+	h.Tick(start.Add(time.Minute))
+	if paused.runs != 0 || other.runs != 1 {
+		t.Fatalf("runs : expected 0 and 1 got [%d] [%d]", paused.runs, other.runs)
+	}
+
+	// the pause is kept for workers registered again.
+	if err := h.Deregister("paused"); err != nil {
+		t.Fatal(err)
+	}
+	h.MustRegister("paused", paused)
+	if !h.Workers()[1].Paused {
+		t.Fatalf("expected paused worker after register")
+	}
+
+	if err := h.ResumeWorker("paused"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100 && atomic.LoadInt32(&paused.runs) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&paused.runs); n != 1 {
+		t.Fatalf("resumed : expected 1 run got [%d]", n)
+	}
+}
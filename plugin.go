@@ -0,0 +1,142 @@
+package worm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+)
+
+// LoadPlugin opens the Go plugin at path and registers the Doers it exports.
+// The plugin must export either a `Doers` func() []worm.Doer or a `Doer`
+// variable of type worm.Doer; workers are registered by their Name.
+func (h *Worm) LoadPlugin(path string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return err
+	}
+	if sym, err := p.Lookup("Doers"); err == nil {
+		fn, ok := sym.(func() []Doer)
+		if !ok {
+			return fmt.Errorf("worm: plugin %s: Doers must be func() []worm.Doer", path)
+		}
+		for _, doer := range fn() {
+			if err := h.Register(doer.Name(), doer); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	doer, err := lookupDoer(p, "Doer")
+	if err != nil {
+		return fmt.Errorf("worm: plugin %s: %s", path, err)
+	}
+	return h.Register(doer.Name(), doer)
+}
+
+// lookupDoer returns the Doer exported by the plugin as symbol.
+func lookupDoer(p *plugin.Plugin, symbol string) (Doer, error) {
+	sym, err := p.Lookup(symbol)
+	if err != nil {
+		return nil, err
+	}
+	// exported variables are looked up as pointers.
+	switch v := sym.(type) {
+	case *Doer:
+		return *v, nil
+	case Doer:
+		return v, nil
+	}
+	return nil, fmt.Errorf("%s is not a worm.Doer", symbol)
+}
+
+// Manifest lists the workers to register at startup.
+type Manifest struct {
+	Workers []ManifestWorker `json:"workers"`
+}
+
+// ManifestWorker is a worker loaded from a Go plugin or run as a command.
+// Exactly one of Plugin or Exec must be set.
+type ManifestWorker struct {
+	// Name registers the worker, defaults to the Doer Name for plugins.
+	Name string `json:"name"`
+	// Plugin is the path of a Go plugin, relative to the manifest.
+	Plugin string `json:"plugin"`
+	// Symbol exported by the plugin, default "Doer".
+	Symbol string `json:"symbol"`
+	// Exec is a command and arguments run by an ExecDoer.
+	Exec []string `json:"exec"`
+	// Env is added to the Exec command environment.
+	Env []string `json:"env"`
+}
+
+// LoadManifest reads the JSON manifest at path and registers its workers.
+func (h *Worm) LoadManifest(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var m Manifest
+	if err := json.NewDecoder(f).Decode(&m); err != nil {
+		return fmt.Errorf("worm: manifest %s: %s", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	for _, w := range m.Workers {
+		doer, err := w.doer(dir)
+		if err != nil {
+			return fmt.Errorf("worm: manifest %s: %s", path, err)
+		}
+		name := w.Name
+		if len(name) < 1 {
+			name = doer.Name()
+		}
+		if err := h.Register(name, doer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// doer returns the Doer described by w.
+func (w ManifestWorker) doer(dir string) (Doer, error) {
+	switch {
+	case len(w.Plugin) > 0 && len(w.Exec) > 0:
+		return nil, errors.New("plugin and exec are exclusive")
+	case len(w.Exec) > 0:
+		if len(w.Name) < 1 {
+			return nil, errors.New("exec worker without name")
+		}
+		x := NewExecDoer(w.Name, w.Exec[0], w.Exec[1:]...)
+		x.Env = w.Env
+		return x, nil
+	case len(w.Plugin) > 0:
+		path := w.Plugin
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		p, err := plugin.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		symbol := w.Symbol
+		if len(symbol) < 1 {
+			symbol = "Doer"
+		}
+		return lookupDoer(p, symbol)
+	}
+	return nil, errors.New("worker without plugin or exec")
+}
+
+// LoadPlugin _
+func LoadPlugin(path string) error {
+	return defaultWorm.LoadPlugin(path)
+}
+
+// LoadManifest _
+func LoadManifest(path string) error {
+	return defaultWorm.LoadManifest(path)
+}
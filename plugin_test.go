@@ -0,0 +1,38 @@
+package worm
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadManifest(t *testing.T) {
+	h := newTestWorm(t)
+	defer closeTestWorm(t, h)
+
+	path := filepath.Join(h.logDir, "manifest.json")
+	err := ioutil.WriteFile(path, []byte(`{
+		"workers": [
+			{"name": "echo", "exec": ["cat"]},
+			{"name": "report", "exec": ["sh", "-c", "exit 0"], "env": ["A=1"]}
+		]
+	}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.LoadManifest(path); err != nil {
+		t.Fatalf("load : err [%s]", err)
+	}
+	list := h.Workers()
+	if len(list) != 2 || list[0].Name != "echo" || list[1].Name != "report" {
+		t.Fatalf("workers : got [%v]", list)
+	}
+
+	err = ioutil.WriteFile(path, []byte(`{"workers": [{"name": "bad"}]}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.LoadManifest(path); err == nil {
+		t.Fatalf("load : expected error")
+	}
+}
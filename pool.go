@@ -0,0 +1,187 @@
+package worm
+
+import (
+	"errors"
+	"sync"
+)
+
+// pool runs the jobs of the hub on a fixed number of goroutines. Waiting
+// jobs are taken in arrival order or, when fair, round-robin across workers
+// so a worker with a big backlog can't starve the others.
+type pool struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	fair bool
+	// weight returns the turns per round of the jobs of a Doer.
+	weight func(name string) int
+
+	queues map[string][]*poolJob
+	// workers is the round-robin order, turn the position and taken the
+	// jobs taken by the current worker in this round.
+	workers []string
+	turn    int
+	taken   int
+	// held are the jobs waiting or running, a job is queued once.
+	held    map[string]bool
+	waiting int
+	seq     uint64
+	closed  bool
+}
+
+// poolJob is a job waiting for a pool goroutine.
+type poolJob struct {
+	doer  Doer
+	jobID string
+	data  []byte
+	seq   uint64
+}
+
+func newPool(fair bool, weight func(string) int) *pool {
+	p := &pool{
+		fair:   fair,
+		weight: weight,
+		queues: make(map[string][]*poolJob),
+		held:   make(map[string]bool),
+	}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// push queues the job unless already waiting or running.
+func (p *pool) push(doer Doer, jobID string, data []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || p.held[jobID] {
+		return
+	}
+	p.held[jobID] = true
+	p.seq++
+	name := doer.Name()
+	if _, ok := p.queues[name]; !ok {
+		p.workers = append(p.workers, name)
+	}
+	p.queues[name] = append(p.queues[name], &poolJob{doer: doer, jobID: jobID, data: data, seq: p.seq})
+	p.waiting++
+	p.cond.Signal()
+}
+
+// pop waits for the next job, nil once closed.
+func (p *pool) pop() *poolJob {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for !p.closed && p.waiting < 1 {
+		p.cond.Wait()
+	}
+	if p.closed {
+		return nil
+	}
+	var name string
+	if p.fair {
+		name = p.nextFair()
+	} else {
+		name = p.nextFIFO()
+	}
+	q := p.queues[name]
+	job := q[0]
+	q[0] = nil
+	p.queues[name] = q[1:]
+	p.waiting--
+	return job
+}
+
+// nextFIFO returns the worker of the oldest waiting job.
+func (p *pool) nextFIFO() string {
+	var name string
+	var seq uint64
+	for _, w := range p.workers {
+		q := p.queues[w]
+		if len(q) > 0 && (name == "" || q[0].seq < seq) {
+			name, seq = w, q[0].seq
+		}
+	}
+	return name
+}
+
+// nextFair returns the worker whose turn it is: each worker with waiting
+// jobs takes up to its weight jobs before the next one.
+func (p *pool) nextFair() string {
+	for {
+		w := p.workers[p.turn%len(p.workers)]
+		if len(p.queues[w]) > 0 && p.taken < p.weight(w) {
+			p.taken++
+			return w
+		}
+		p.turn = (p.turn + 1) % len(p.workers)
+		p.taken = 0
+	}
+}
+
+// done releases the job after it ran.
+func (p *pool) done(jobID string) {
+	p.mu.Lock()
+	delete(p.held, jobID)
+	p.mu.Unlock()
+}
+
+// close wakes the waiting goroutines, queued jobs stay due in the database.
+func (p *pool) close() {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	p.cond.Broadcast()
+}
+
+// start runs the job on the pool, or on its own goroutine without pool.
+func (h *Worm) start(doer Doer, jobID string, data []byte) {
+	if h.pool == nil {
+		go h.run(doer, jobID, data)
+		return
+	}
+	h.pool.push(doer, jobID, data)
+}
+
+// runPool runs the pool jobs until the hub closes.
+func (h *Worm) runPool() {
+	for {
+		job := h.pool.pop()
+		if job == nil {
+			return
+		}
+		h.run(job.doer, job.jobID, job.data)
+		h.pool.done(job.jobID)
+	}
+}
+
+// startPool starts size pool goroutines.
+func (h *Worm) startPool(size int) {
+	h.pool = newPool(h.fair, h.weight)
+	for i := 0; i < size; i++ {
+		h.background(h.runPool)
+	}
+	h.background(func() {
+		<-h.quitc
+		h.pool.close()
+	})
+}
+
+// weight returns the fair dispatch weight of the worker running the Doer
+// named name.
+func (h *Worm) weight(name string) int {
+	wk, ok := h.workerOf(name)
+	if ok && wk.weight > 0 {
+		return wk.weight
+	}
+	return 1
+}
+
+// Weight gives the worker n turns per round of fair dispatch, so it gets n
+// times the pool share of a worker with weight 1. Default 1.
+func Weight(n int) WorkerOption {
+	return func(w *worker) error {
+		if n < 1 {
+			return errors.New("worm: weight must be positive")
+		}
+		w.weight = n
+		return nil
+	}
+}
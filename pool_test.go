@@ -0,0 +1,80 @@
+package worm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPoolOrder(t *testing.T) {
+	big, small := &testDoer{name: "big"}, &testDoer{name: "small"}
+	for _, tc := range []struct {
+		fair    bool
+		weights map[string]int
+		order   string
+	}{
+		{false, nil, "big big big big small small"},
+		{true, nil, "big small big small big big"},
+		{true, map[string]int{"big": 2}, "big big small big big small"},
+	} {
+		p := newPool(tc.fair, func(name string) int {
+			if w, ok := tc.weights[name]; ok {
+				return w
+			}
+			return 1
+		})
+		for i := 0; i < 4; i++ {
+			p.push(big, fmt.Sprintf("big-%d", i), nil)
+		}
+		p.push(small, "small-0", nil)
+		p.push(small, "small-1", nil)
+		// held jobs are not queued twice.
+		p.push(small, "small-1", nil)
+
+		var names []string
+		for i := 0; i < 6; i++ {
+			names = append(names, p.pop().doer.Name())
+		}
+		if got := strings.Join(names, " "); got != tc.order {
+			t.Fatalf("fair [%v] weights [%v] : expected [%s] got [%s]", tc.fair, tc.weights, tc.order, got)
+		}
+		p.close()
+		if p.pop() != nil {
+			t.Fatalf("expected nil pop on closed pool")
+		}
+	}
+}
+
+func TestPoolSize(t *testing.T) {
+	h := newTestWorm(t, WithPoolSize(2), WithFairDispatch())
+	defer closeTestWorm(t, h)
+	d := &countDoer{}
+	h.MustRegister("count", d, Weight(3))
+
+	var ids []string
+	for i := 0; i < 10; i++ {
+		jobID, err := h.Queue("count", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, jobID)
+	}
+	for _, jobID := range ids {
+		waitStatus(t, h, jobID, StatusOK)
+	}
+	if err := h.Register("zero", &testDoer{name: "zero"}, Weight(0)); err == nil {
+		t.Fatalf("expected weight error")
+	}
+}
+
+// waitStatus waits for the job to reach status.
+func waitStatus(t *testing.T, h *Worm, jobID string, status int) {
+	for i := 0; i < 200; i++ {
+		if job, _ := h.Detail(jobID); job != nil && job.Status == status {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job [%s] : expected status [%d]", jobID, status)
+}
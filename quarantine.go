@@ -0,0 +1,73 @@
+package worm
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+// Quarantine pauses the worker for coolDown after threshold consecutive
+// failed jobs and emits EventQuarantine. ResumeWorker ends it early.
+func Quarantine(threshold int, coolDown time.Duration) WorkerOption {
+	return func(w *worker) error {
+		if threshold < 1 || coolDown <= 0 {
+			return errors.New("worm: quarantine threshold and cool down must be positive")
+		}
+		w.threshold, w.coolDown = threshold, coolDown
+		return nil
+	}
+}
+
+// countFailure tracks the consecutive failures of the worker and quarantines
+// it on threshold.
+func (h *Worm) countFailure(jobID, workerName string, status int, errMsg string) {
+	h.RLock()
+	wk, ok := h.workers[workerName]
+	h.RUnlock()
+	if !ok || wk.threshold < 1 {
+		return
+	}
+	wk.mu.Lock()
+	if status == StatusOK {
+		wk.failures = 0
+	} else {
+		wk.failures++
+	}
+	trip := wk.failures >= wk.threshold
+	if trip {
+		wk.failures = 0
+	}
+	wk.mu.Unlock()
+	if !trip {
+		return
+	}
+
+	now := h.now()
+	until := now.Add(wk.coolDown)
+	// a manual pause is kept as is.
+	o := <-h.waitc
+	_, err := h.dbExec(`
+		UPDATE worm_paused SET paused_at=?,paused_until=?
+		WHERE worker_name=? AND paused_until IS NOT NULL;
+	`, now, until, workerName)
+	if err == nil {
+		_, err = h.dbExec(`
+			INSERT OR IGNORE INTO worm_paused (worker_name,paused_at,paused_until)
+			VALUES (?,?,?);
+		`, workerName, now, until)
+	}
+	h.waitc <- o
+	if err != nil {
+		log.Printf("countFailure : quarantine : err [%s] worker [%s]", err, workerName)
+		return
+	}
+	paused, pausedUntil := h.workerPaused(workerName)
+	h.setPaused(workerName, paused, pausedUntil)
+	log.Printf("countFailure : worker [%s] quarantined until [%s]", workerName, until)
+	h.emit(Event{
+		Type:   EventQuarantine,
+		Worker: workerName,
+		JobID:  jobID,
+		Error:  errMsg,
+	})
+}
@@ -0,0 +1,51 @@
+package worm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQuarantine(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	var events []Event
+	h := newTestWorm(t, WithManualTick(start), WithEventHandler(func(e Event) {
+		if e.Type == EventQuarantine {
+			events = append(events, e)
+		}
+	}))
+	defer closeTestWorm(t, h)
+	d := &testDoer{name: "smtp", status: 2, err: errors.New("connection refused")}
+	h.MustRegister("smtp", d, Quarantine(2, time.Hour))
+
+	h.Queue("smtp", nil)
+	h.Queue("smtp", nil)
+	h.Tick(start.Add(time.Minute))
+	if len(events) != 1 || events[0].Type != EventQuarantine || events[0].Worker != "smtp" {
+		t.Fatalf("events : got [%+v]", events)
+	}
+	info := h.Workers()[0]
+	if !info.Paused || info.PausedUntil.Before(start.Add(time.Hour)) {
+		t.Fatalf("worker : got [%+v]", info)
+	}
+
+	// quarantined jobs wait for the cool down.
+	d.status, d.err = StatusOK, nil
+	jobID, _ := h.Queue("smtp", nil)
+	h.Tick(start.Add(30 * time.Minute))
+	if job, _ := h.Detail(jobID); job.Status != StatusStart {
+		t.Fatalf("quarantined : expected pending job got [%+v]", job)
+	}
+	h.Tick(start.Add(2 * time.Hour))
+	if h.Workers()[0].Paused {
+		t.Fatalf("expected quarantine over")
+	}
+	h.claimPending()
+	for i := 0; i < 100; i++ {
+		if job, _ := h.Detail(jobID); job.Status == StatusOK {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected job run after quarantine")
+}
@@ -0,0 +1,42 @@
+package worm
+
+// DefaultQueue is the queue of the workers registered without OnQueue.
+const DefaultQueue = "default"
+
+// OnQueue puts the worker on the named queue. Hubs started WithQueues run
+// the jobs of their queues only, so e.g. heavy workers get their own
+// machines. Any hub can queue jobs for any queue.
+func OnQueue(name string) WorkerOption {
+	return func(w *worker) error {
+		w.queue = name
+		return nil
+	}
+}
+
+// WithQueues makes the hub run only the jobs of the workers on the named
+// queues; name DefaultQueue to keep running the workers without OnQueue. By
+// default hubs run every queue.
+func WithQueues(names ...string) Option {
+	return func(h *Worm) {
+		h.queues = make(map[string]bool)
+		for _, name := range names {
+			h.queues[name] = true
+		}
+	}
+}
+
+// queueName returns the queue of the worker.
+func (w *worker) queueName() string {
+	if len(w.queue) < 1 {
+		return DefaultQueue
+	}
+	return w.queue
+}
+
+// serves reports if the hub runs the jobs of wk.
+func (h *Worm) serves(wk *worker) bool {
+	if h.schedulerOnly {
+		return false
+	}
+	return h.queues == nil || h.queues[wk.queueName()]
+}
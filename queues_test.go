@@ -0,0 +1,41 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueues(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start), WithQueues("reports"))
+	defer closeTestWorm(t, h)
+	h.MustRegister("count", &countDoer{})
+	report := &dataDoer{}
+	h.MustRegister("data", report, OnQueue("reports"))
+
+	countID, err := h.Queue("count", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Queue("data", []byte("q1")); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := h.Tick(start.Add(time.Minute)); n != 1 || string(report.data) != "q1" {
+		t.Fatalf("tick : got [%d] runs data [%s]", n, report.data)
+	}
+	// default queue jobs are left to other hubs.
+	job, err := h.Detail(countID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != StatusStart || job.Attempts != 0 {
+		t.Fatalf("default queue : got [%+v]", job)
+	}
+	queues := map[string]string{}
+	for _, wk := range h.Workers() {
+		queues[wk.Name] = wk.Queue
+	}
+	if queues["count"] != DefaultQueue || queues["data"] != "reports" {
+		t.Fatalf("workers : got [%v]", queues)
+	}
+}
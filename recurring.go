@@ -0,0 +1,201 @@
+package worm
+
+import (
+	"database/sql"
+	"log"
+	"time"
+)
+
+// Schedule is a recurring job seen by its schedule ID, apart from the IDs of
+// the job row and of its runs.
+type Schedule struct {
+	ID         string     `db:"schedule_id" json:"id"`
+	JobID      string     `db:"id" json:"job_id"`
+	Worker     string     `db:"worker_name" json:"worker_name"`
+	Spec       string     `db:"cron" json:"spec"`
+	DisabledAt *time.Time `db:"disabled_at" json:"disabled_at,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	// NextRun is the next firing, nil when disabled.
+	NextRun *time.Time `db:"-" json:"next_run,omitempty"`
+	// LastRun is the latest firing, see RunByID.
+	LastRun *Run `db:"-" json:"last_run,omitempty"`
+}
+
+// ScheduleID sets the schedule ID of a recurring job instead of generating
+// one. Sched fails if the ID is already taken. Single executions ignore it.
+func ScheduleID(id string) JobOption {
+	return func(o *jobOptions) {
+		o.scheduleID = id
+	}
+}
+
+// selectSchedule is the query of the schedules, without conditions.
+const selectSchedule = `
+	SELECT schedule_id, id, worker_name, cron, disabled_at, created_at
+	FROM worm WHERE cron<>'' AND schedule_id<>'' AND deleted_at IS NULL`
+
+// Schedules returns the recurring jobs, oldest first.
+func (h *Worm) Schedules() ([]*Schedule, error) {
+	var list []*Schedule
+	o := <-h.waitc
+	err := h.dbSelect(&list, selectSchedule+` ORDER BY created_at, rowid;`)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("Schedules : err [%s]", err)
+		return nil, err
+	}
+	for _, s := range list {
+		if err := h.scheduleRuns(s); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// Schedule returns the recurring job with the schedule ID scheduleID.
+func (h *Worm) Schedule(scheduleID string) (*Schedule, error) {
+	var s Schedule
+	o := <-h.waitc
+	err := h.dbGet(&s, selectSchedule+` AND schedule_id=?;`, scheduleID)
+	h.waitc <- o
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Schedule : err [%s] schedule id [%s]", err, scheduleID)
+		return nil, err
+	}
+	if err := h.scheduleRuns(&s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// scheduleRuns sets the next firing and the last run of s.
+func (h *Worm) scheduleRuns(s *Schedule) error {
+	d := Job{ID: s.JobID, Cron: s.Spec}
+	if err := h.scheduleInfo(&d); err != nil {
+		return err
+	}
+	s.NextRun, s.LastRun = d.NextRun, d.LastRun
+	return nil
+}
+
+// DeleteSchedule soft deletes the recurring job with the schedule ID
+// scheduleID, like Delete. Its runs stay available with RunByID.
+func (h *Worm) DeleteSchedule(scheduleID string) error {
+	if len(scheduleID) < 1 {
+		return ErrNotFound
+	}
+	now := h.now()
+	o := <-h.waitc
+	res, err := h.dbExec(`
+		UPDATE worm SET deleted_at=?,updated_at=?
+		WHERE schedule_id=? AND cron<>'' AND deleted_at IS NULL;
+	`, now, now, scheduleID)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("DeleteSchedule : err [%s] schedule id [%s]", err, scheduleID)
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// TriggerSchedule fires the recurring job with the schedule ID scheduleID
+// now, recorded as a manual run, without waiting for its next firing. The
+// schedule doesn't change. The run starts at once on its own goroutine, or
+// before returning with WithManualTick, and is skipped like the firings
+// while the job is running. Scheduler-only hubs queue the execution like
+// the firings. Returns ErrConflict for disabled schedules.
+func (h *Worm) TriggerSchedule(scheduleID string) error {
+	var job struct {
+		ID       string `db:"id"`
+		Worker   string `db:"worker_name"`
+		Disabled bool   `db:"disabled"`
+	}
+	o := <-h.waitc
+	err := h.dbGet(&job, `
+		SELECT id, worker_name, disabled_at IS NOT NULL AS "disabled"
+		FROM worm WHERE schedule_id=? AND cron<>'' AND deleted_at IS NULL;
+	`, scheduleID)
+	h.waitc <- o
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		log.Printf("TriggerSchedule : err [%s] schedule id [%s]", err, scheduleID)
+		return err
+	}
+	if job.Disabled {
+		return ErrConflict
+	}
+	if h.schedulerOnly {
+		h.enqueueFiring(job.ID)
+		return nil
+	}
+	h.RLock()
+	wk, ok := h.workers[job.Worker]
+	h.RUnlock()
+	if !ok {
+		return ErrNotFound
+	}
+	if h.manual() {
+		h.runAs(wk.doer, job.ID, nil, true)
+		return nil
+	}
+	go h.runAs(wk.doer, job.ID, nil, true)
+	return nil
+}
+
+// RunByID returns the run runID, of any job or schedule.
+func (h *Worm) RunByID(runID int64) (*Run, error) {
+	var run Run
+	o := <-h.waitc
+	err := h.dbGet(&run, `
+		SELECT id, job_id, instance_id, status, IFNULL(error,'') AS "error",
+		started_at, finished_at, IFNULL(version,'') AS "version", IFNULL(manual,0) AS "manual", due_at,
+		IFNULL(log_file,'') AS "log_file"
+		FROM worm_run WHERE id=?;
+	`, runID)
+	h.waitc <- o
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		log.Printf("RunByID : err [%s] run id [%d]", err, runID)
+		return nil, err
+	}
+	return &run, nil
+}
+
+// Schedules _
+func Schedules() ([]*Schedule, error) {
+	return defaultWorm.Schedules()
+}
+
+// GetSchedule _
+func GetSchedule(scheduleID string) (*Schedule, error) {
+	return defaultWorm.Schedule(scheduleID)
+}
+
+// DeleteSchedule _
+func DeleteSchedule(scheduleID string) error {
+	return defaultWorm.DeleteSchedule(scheduleID)
+}
+
+// TriggerSchedule _
+func TriggerSchedule(scheduleID string) error {
+	return defaultWorm.TriggerSchedule(scheduleID)
+}
+
+// RunByID _
+func RunByID(runID int64) (*Run, error) {
+	return defaultWorm.RunByID(runID)
+}
@@ -0,0 +1,120 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedules(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	h.MustRegister("ok", &testDoer{name: "ok"})
+
+	hourly, err := h.Sched("ok", nil, "0 0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Sched("ok", nil, "0 30 * * * *", ScheduleID("half")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Sched("ok", nil, "@hourly", ScheduleID("half")); err == nil {
+		t.Fatal("expected taken schedule ID error")
+	}
+	single, err := h.Queue("ok", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(time.Hour))
+
+	job, err := h.Detail(hourly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(job.ScheduleID) < 1 || job.ScheduleID == hourly {
+		t.Fatalf("detail : expected own schedule ID got [%s]", job.ScheduleID)
+	}
+	if job, err := h.Detail(single); err != nil || job.ScheduleID != "" {
+		t.Fatalf("single : got [%+v] err [%v]", job, err)
+	}
+	list, err := h.Schedules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].ID != job.ScheduleID || list[1].ID != "half" {
+		t.Fatalf("schedules : got [%v]", list)
+	}
+	s, err := h.Schedule(job.ScheduleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.JobID != hourly || s.Spec != "0 0 * * * *" || s.LastRun == nil || s.NextRun == nil || !s.NextRun.Equal(start.Add(2*time.Hour)) {
+		t.Fatalf("schedule : got [%+v]", s)
+	}
+
+	// runs are inspected by their own ID.
+	run, err := h.RunByID(s.LastRun.ID)
+	if err != nil || run.JobID != hourly || run.Status != StatusOK {
+		t.Fatalf("run : got [%+v] err [%v]", run, err)
+	}
+	if _, err := h.RunByID(1000); err != ErrNotFound {
+		t.Fatalf("missing run : expected ErrNotFound got [%v]", err)
+	}
+
+	// job IDs are not schedule IDs.
+	if err := h.DeleteSchedule(hourly); err != ErrNotFound {
+		t.Fatalf("job ID : expected ErrNotFound got [%v]", err)
+	}
+	if err := h.DeleteSchedule(""); err != ErrNotFound {
+		t.Fatalf("empty : expected ErrNotFound got [%v]", err)
+	}
+	if err := h.DeleteSchedule(s.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Schedule(s.ID); err != ErrNotFound {
+		t.Fatalf("deleted : expected ErrNotFound got [%v]", err)
+	}
+	if _, err := h.RunByID(run.ID); err != nil {
+		t.Fatalf("deleted run : got err [%v]", err)
+	}
+}
+
+func TestTriggerSchedule(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	h.MustRegister("ok", &testDoer{name: "ok"})
+
+	jobID, err := h.Sched("ok", nil, "0 0 * * * *", ScheduleID("hourly"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(30 * time.Minute))
+	if err := h.TriggerSchedule("hourly"); err != nil {
+		t.Fatal(err)
+	}
+	// the schedule still fires at 1:00.
+	if n, _ := h.Tick(start.Add(time.Hour)); n != 1 {
+		t.Fatalf("tick : expected 1 firing got [%d]", n)
+	}
+	runs, err := h.Runs(jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || !runs[0].Manual || runs[1].Manual || runs[0].Status != StatusOK {
+		t.Fatalf("runs : got [%+v]", runs)
+	}
+	if !runs[0].StartedAt.Equal(start.Add(30 * time.Minute)) {
+		t.Fatalf("manual run : got started at [%s]", runs[0].StartedAt)
+	}
+
+	if err := h.TriggerSchedule("missing"); err != ErrNotFound {
+		t.Fatalf("missing : expected ErrNotFound got [%v]", err)
+	}
+	if err := h.DisableSchedule(jobID); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.TriggerSchedule("hourly"); err != ErrConflict {
+		t.Fatalf("disabled : expected ErrConflict got [%v]", err)
+	}
+}
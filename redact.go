@@ -0,0 +1,103 @@
+package worm
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// redacted replaces the values of the redacted fields.
+const redacted = "[redacted]"
+
+// Redact masks the JSON fields named fields, at any depth, in the data of
+// the worker jobs returned by Detail, Jobs, Iterate, ByExternalID and
+// Query, so exports and the admin API don't leak secrets like passwords or
+// tokens. Data that isn't JSON is masked whole. The fields are stored with
+// each job, so hubs reading jobs of workers they don't register mask them
+// too. The stored payload and the data passed to the Doer stay intact.
+func Redact(fields ...string) WorkerOption {
+	return func(w *worker) error {
+		if len(fields) < 1 {
+			return errors.New("worm: no fields to redact")
+		}
+		if w.redact == nil {
+			w.redact = make(map[string]bool)
+		}
+		for _, f := range fields {
+			w.redact[f] = true
+		}
+		return nil
+	}
+}
+
+// redactList returns the redacted fields of the worker as stored with its
+// jobs.
+func redactList(wk *worker) string {
+	fields := make([]string, 0, len(wk.redact))
+	for f := range wk.redact {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return strings.Join(fields, ",")
+}
+
+// redactJobs masks the sensitive fields in the data of the jobs: the ones
+// stored with the job or, for jobs stored without, the ones of the
+// registered worker.
+func (h *Worm) redactJobs(jobs ...*Job) {
+	for _, job := range jobs {
+		if job == nil || len(job.Data) < 1 {
+			continue
+		}
+		fields := make(map[string]bool)
+		for _, f := range strings.Split(job.Redact, ",") {
+			if len(f) > 0 {
+				fields[f] = true
+			}
+		}
+		if len(fields) < 1 {
+			if wk, ok := h.workerOf(job.Worker); ok {
+				fields = wk.redact
+			}
+		}
+		if len(fields) < 1 {
+			continue
+		}
+		job.Data = redactData(job.Data, fields)
+	}
+}
+
+// redactData returns the JSON data with the values of fields masked.
+func redactData(data string, fields map[string]bool) string {
+	d := json.NewDecoder(strings.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return redacted
+	}
+	b, err := json.Marshal(redactValue(v, fields))
+	if err != nil {
+		return redacted
+	}
+	return string(b)
+}
+
+// redactValue masks the fields of the objects in v.
+func redactValue(v interface{}, fields map[string]bool) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, item := range x {
+			if fields[k] {
+				x[k] = redacted
+				continue
+			}
+			x[k] = redactValue(item, fields)
+		}
+	case []interface{}:
+		for i, item := range x {
+			x[i] = redactValue(item, fields)
+		}
+	}
+	return v
+}
@@ -0,0 +1,77 @@
+package worm
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRedact(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	doer := &dataDoer{}
+	h.MustRegister("data", doer, Redact("password", "token"))
+	h.MustRegister("plain", &testDoer{name: "plain", status: StatusOK})
+
+	data := `{"user":"ana","password":"s3cret","auth":{"token":"abc","ttl":3600},"list":[{"token":"x"}]}`
+	jobID, err := h.Queue("data", []byte(data), ExternalID("order-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plainID, err := h.Queue("plain", []byte(`{"password":"visible"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"auth":{"token":"[redacted]","ttl":3600},"list":[{"token":"[redacted]"}],"password":"[redacted]","user":"ana"}`
+	job, err := h.Detail(jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Data != expected {
+		t.Fatalf("detail : expected [%s] got [%s]", expected, job.Data)
+	}
+	jobs, err := h.Jobs(JobFilter{}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, job := range jobs {
+		if job.ID == jobID && job.Data != expected {
+			t.Errorf("jobs : got [%s]", job.Data)
+		}
+		if job.ID == plainID && job.Data != `{"password":"visible"}` {
+			t.Errorf("plain : got [%s]", job.Data)
+		}
+	}
+	if jobs, err := h.ByExternalID("order-1"); err != nil || len(jobs) != 1 || jobs[0].Data != expected {
+		t.Fatalf("external id : got [%v] err [%v]", jobs, err)
+	}
+
+	// hubs not registering the worker mask the data too.
+	other, err := New(filepath.Join(h.logDir, "worm.db"), h.logDir,
+		WithInstanceID("other"), WithManualTick(start))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	if job, err := other.Detail(jobID); err != nil || job.Data != expected {
+		t.Fatalf("other detail : got [%v] err [%v]", job, err)
+	}
+	if jobs, err := other.ByExternalID("order-1"); err != nil || len(jobs) != 1 || jobs[0].Data != expected {
+		t.Fatalf("other external id : got [%v] err [%v]", jobs, err)
+	}
+	if job, err := other.Detail(plainID); err != nil || job.Data != `{"password":"visible"}` {
+		t.Fatalf("other plain : got [%v] err [%v]", job, err)
+	}
+
+	// the doer gets the stored payload.
+	h.Tick(start)
+	if string(doer.data) != data {
+		t.Fatalf("doer : got [%s]", doer.data)
+	}
+
+	if got := redactData("not json", map[string]bool{"token": true}); got != redacted {
+		t.Fatalf("not json : got [%s]", got)
+	}
+}
@@ -0,0 +1,32 @@
+package worm
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// WithReadReplica reads Detail, Jobs, Query and the Stats job counts from
+// the SQLite database at connectURL, e.g. a LiteFS or Litestream replica
+// of the hub database, while writes stay on the primary. Replica reads
+// don't wait for the primary lock and may lag behind its writes.
+func WithReadReplica(connectURL string) Option {
+	return func(h *Worm) {
+		h.replicaURL = connectURL
+	}
+}
+
+// readDB runs fn with the read replica, or with the primary holding waitc
+// without one. Busy errors are retried.
+func (h *Worm) readDB(fn func(db *sqlx.DB) error) error {
+	if h.replica != nil {
+		return retryBusy(func() error {
+			return fn(h.replica)
+		})
+	}
+	o := <-h.waitc
+	defer func() {
+		h.waitc <- o
+	}()
+	return retryBusy(func() error {
+		return fn(h.Db)
+	})
+}
@@ -0,0 +1,61 @@
+package worm
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadReplica(t *testing.T) {
+	h := newTestWorm(t)
+	defer closeTestWorm(t, h)
+	h.MustRegister("held", &testDoer{name: "held", status: StatusOK})
+	if err := h.PauseWorker("held"); err != nil {
+		t.Fatal(err)
+	}
+	before, err := h.Queue("held", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the replica is a snapshot of the primary taken now.
+	replica := filepath.Join(h.logDir, "replica.db")
+	if _, err := h.Db.Exec(`VACUUM INTO ?;`, replica); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := New(filepath.Join(h.logDir, "worm.db"), h.logDir, WithReadReplica(replica))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			t.Errorf("close : err [%s]", err)
+		}
+	}()
+	r.MustRegister("held", &testDoer{name: "held", status: StatusOK})
+	after, err := r.Queue("held", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := r.Jobs(JobFilter{}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != before {
+		t.Fatalf("replica jobs : got [%v]", jobs)
+	}
+	if _, err := r.Detail(after); err != sql.ErrNoRows {
+		t.Fatalf("replica detail : expected ErrNoRows got [%v]", err)
+	}
+	st, err := r.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(st.Workers) != 1 || st.Workers[0].Pending != 1 {
+		t.Fatalf("replica stats : got [%+v]", st.Workers)
+	}
+	if _, err := h.Detail(after); err != nil {
+		t.Fatalf("primary detail : err [%s]", err)
+	}
+}
@@ -0,0 +1,151 @@
+package worm
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrNoLog is returned by CopyLog for jobs without output, not run yet or
+// whose log was removed by the log retention.
+var ErrNoLog = errors.New("worm: job log not available")
+
+// logBatch limits the logs removed per sweep.
+const logBatch = 500
+
+// WithLogRetention removes the output logs, the results of the runs, bigger
+// than minSize bytes d after their job finished, while the job rows stay
+// until purged. Logs are removed lazily by Sweep, logBatch per pass. d is
+// the default of the workers registered without LogRetention.
+func WithLogRetention(d time.Duration, minSize int64) Option {
+	return func(h *Worm) {
+		if d > 0 {
+			h.logRetention = d
+			h.logMinSize = minSize
+		}
+	}
+}
+
+// LogRetention keeps the logs of the worker jobs for d instead of the hub
+// log retention, see WithLogRetention. Zero keeps them until purged.
+func LogRetention(d time.Duration) WorkerOption {
+	return func(w *worker) error {
+		if d <= 0 {
+			d = -1
+		}
+		w.logRetention = d
+		return nil
+	}
+}
+
+// expiredLog is a run log past its retention.
+type expiredLog struct {
+	ID      int64  `db:"id"`
+	LogFile string `db:"log_file"`
+}
+
+// expireLogs removes the logs past the log retention of their worker, the
+// hub one for workers not registered on this hub. Returns the number of
+// removed logs.
+func (h *Worm) expireLogs(now time.Time) (int, error) {
+	var registered []string
+	retentions := make(map[time.Duration][]string)
+	h.RLock()
+	for name, wk := range h.workers {
+		registered = append(registered, name)
+		if wk.logRetention > 0 {
+			retentions[wk.logRetention] = append(retentions[wk.logRetention], name)
+		}
+	}
+	h.RUnlock()
+	if h.logRetention <= 0 && len(retentions) < 1 {
+		return 0, nil
+	}
+	var runs []expiredLog
+	var err error
+	o := <-h.waitc
+	if h.logRetention > 0 {
+		runs, err = h.expiredLogs(now.Add(-h.logRetention), registered, false, logBatch)
+	}
+	for d, names := range retentions {
+		if err != nil || len(runs) >= logBatch {
+			break
+		}
+		var more []expiredLog
+		more, err = h.expiredLogs(now.Add(-d), names, true, logBatch-len(runs))
+		runs = append(runs, more...)
+	}
+	h.waitc <- o
+	if err != nil || len(runs) < 1 {
+		return 0, err
+	}
+
+	ids := make([]int64, 0, len(runs))
+	names := make([]string, 0, len(runs))
+	for _, run := range runs {
+		if err := os.Remove(run.LogFile); err != nil && !os.IsNotExist(err) {
+			log.Printf("expireLogs : remove : err [%s] run id [%d]", err, run.ID)
+			continue
+		}
+		ids = append(ids, run.ID)
+		names = append(names, run.LogFile)
+	}
+	if len(ids) < 1 {
+		return 0, nil
+	}
+	// jobs point to the log of their latest run.
+	o = <-h.waitc
+	err = h.dbExecIn(`UPDATE worm_run SET log_file='',log_size=0 WHERE id IN (?);`, ids)
+	if err == nil {
+		err = h.dbExecIn(`UPDATE worm SET log_file='',log_size=0 WHERE log_file IN (?);`, names)
+	}
+	if err == nil && len(h.fts) > 0 {
+		err = h.dbExecIn(`DELETE FROM worm_log_fts WHERE rowid IN (?);`, ids)
+	}
+	h.waitc <- o
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// expiredLogs returns up to limit logs of the runs finished before cutoff of
+// the named workers when in, of the other workers otherwise. Must be called
+// holding waitc.
+func (h *Worm) expiredLogs(cutoff time.Time, names []string, in bool, limit int) ([]expiredLog, error) {
+	q := `
+		SELECT id, log_file FROM worm_run
+		WHERE log_size>? AND finished_at<? AND IFNULL(log_file,'')<>''`
+	args := []interface{}{h.logMinSize, cutoff}
+	switch {
+	case in:
+		q += ` AND job_id IN (SELECT id FROM worm WHERE worker_name IN (?))`
+		args = append(args, names)
+	case len(names) > 0:
+		q += ` AND job_id NOT IN (SELECT id FROM worm WHERE worker_name IN (?))`
+		args = append(args, names)
+	}
+	q += ` LIMIT ?;`
+	args = append(args, limit)
+	q, args, err := sqlx.In(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	var runs []expiredLog
+	err = h.dbSelect(&runs, h.Db.Rebind(q), args...)
+	return runs, err
+}
+
+// dbExecIn executes q expanding its IN (?) to args. Must be called holding
+// waitc.
+func (h *Worm) dbExecIn(q string, args interface{}) error {
+	q, xargs, err := sqlx.In(q, args)
+	if err != nil {
+		return err
+	}
+	_, err = h.dbExec(h.Db.Rebind(q), xargs...)
+	return err
+}
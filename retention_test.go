@@ -0,0 +1,60 @@
+package worm
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// printDoer writes n bytes of output.
+type printDoer struct {
+	n int
+}
+
+func (d *printDoer) Name() string {
+	return "print"
+}
+
+func (d *printDoer) Run(data []byte, w io.Writer) (int, error) {
+	io.WriteString(w, strings.Repeat("x", d.n))
+	return StatusOK, nil
+}
+
+func TestLogRetention(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start), WithLogRetention(time.Hour, 1024))
+	defer closeTestWorm(t, h)
+
+	big, small := &printDoer{n: 4096}, &printDoer{n: 10}
+	h.MustRegister("big", big)
+	h.MustRegister("small", small)
+	bigID, err := h.Queue("big", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	smallID, err := h.Queue("small", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start)
+
+	if pass, _ := h.Sweep(); pass.Logs != 0 {
+		t.Fatalf("sweep : expected logs kept within retention got [%+v]", pass)
+	}
+	h.Tick(start.Add(2 * time.Hour))
+	if pass, _ := h.Sweep(); pass.Logs != 1 {
+		t.Fatalf("sweep : expected 1 log removed got [%+v]", pass)
+	}
+	var b bytes.Buffer
+	if err := h.CopyLog(&b, bigID); err != ErrNoLog {
+		t.Fatalf("big : expected ErrNoLog got [%v]", err)
+	}
+	if _, err := h.Detail(bigID); err != nil {
+		t.Fatalf("big : expected job kept got [%v]", err)
+	}
+	if err := h.CopyLog(&b, smallID); err != nil || b.Len() != len(traceHeader(t, h, smallID))+10 {
+		t.Fatalf("small : got [%d] bytes err [%v]", b.Len(), err)
+	}
+}
@@ -0,0 +1,84 @@
+package worm
+
+import (
+	"errors"
+	"log"
+)
+
+// MaxRetries runs the failed single execution jobs of the worker again, up
+// to n times, before they fail for good. Completion hooks and failure events
+// wait for the last attempt. Tampered and undecodable jobs are not retried.
+// Overrides WithDefaultRetries.
+func MaxRetries(n int) WorkerOption {
+	return func(w *worker) error {
+		if n < 0 {
+			return errors.New("worm: max retries must not be negative")
+		}
+		w.maxRetries = n
+		return nil
+	}
+}
+
+// Retry runs a failed single execution job again with the same ID and data.
+// Returns ErrConflict when the job did not fail.
+func (h *Worm) Retry(jobID string) error {
+	var workerName string
+	o := <-h.waitc
+	err := h.dbGet(&workerName, `
+		SELECT worker_name FROM worm WHERE id=? AND cron='' AND deleted_at IS NULL;
+	`, jobID)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("Retry : select : err [%s] job id [%s]", err, jobID)
+		return err
+	}
+	h.RLock()
+	wk, ok := h.workers[workerName]
+	h.RUnlock()
+	if !ok {
+		return errors.New("worm: doer not found")
+	}
+	now := h.now()
+	if err := h.transition(jobID, StatePending, ",run_at=?,expires_at=NULL", now); err != nil {
+		log.Printf("Retry : transition : err [%s] job id [%s]", err, jobID)
+		return err
+	}
+	h.dispatch(wk.doer, jobID, nil, now)
+	return nil
+}
+
+// retryFailed makes the single execution job pending again when its run,
+// attempt number attempt, failed with status and the worker has retries
+// left. Reports if it did.
+func (h *Worm) retryFailed(doer Doer, jobID string, status, attempt int) bool {
+	switch status {
+	case StatusOK, StatusTampered, StatusDecode:
+		return false
+	}
+	wk, ok := h.workerOf(doer.Name())
+	if !ok || attempt > wk.maxRetries {
+		return false
+	}
+	now := h.now()
+	o := <-h.waitc
+	res, err := h.dbExec(`
+		UPDATE worm SET status=?,error='',finished_at=NULL,run_at=?,expires_at=NULL,updated_at=?
+		WHERE id=? AND cron='' AND status=? AND finished_at IS NOT NULL AND deleted_at IS NULL;
+	`, StatusStart, now, now, jobID, status)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("run : retry : err [%s] job id [%s]", err, jobID)
+		return false
+	}
+	if n, err := res.RowsAffected(); err != nil || n < 1 {
+		return false
+	}
+	log.Printf("run : retry [%d] of [%d] : job id [%s]", attempt, wk.maxRetries, jobID)
+	h.dispatch(doer, jobID, nil, now)
+	return true
+}
+
+// Retry _
+func Retry(jobID string) error {
+	return defaultWorm.Retry(jobID)
+}
@@ -0,0 +1,82 @@
+package worm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	d := &testDoer{name: "flaky", status: 2, err: errors.New("boom")}
+	h.MustRegister("flaky", d)
+
+	jobID, err := h.Queue("flaky", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(time.Minute))
+
+	d.status, d.err = StatusOK, nil
+	if err := h.Retry(jobID); err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(2 * time.Minute))
+	job, err := h.Detail(jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != StatusOK || job.Error != "" || job.Attempts != 2 {
+		t.Fatalf("retried job : got [%+v]", job)
+	}
+	jobs, err := h.Jobs(JobFilter{Worker: "flaky"}, 10)
+	if err != nil || len(jobs) != 1 || jobs[0].Attempts != 2 {
+		t.Fatalf("jobs : expected 2 attempts got [%v] err [%v]", jobs, err)
+	}
+	if err := h.Retry(jobID); err != ErrConflict {
+		t.Fatalf("succeeded job : expected ErrConflict got [%v]", err)
+	}
+}
+
+func TestMaxRetries(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	var failed int
+	h := newTestWorm(t, WithManualTick(start), WithEventHandler(func(e Event) {
+		if e.Type == EventFailed {
+			failed++
+		}
+	}))
+	defer closeTestWorm(t, h)
+	d := &testDoer{name: "flaky", status: 2, err: errors.New("boom")}
+	h.MustRegister("flaky", d, MaxRetries(2))
+
+	jobID, err := h.Queue("flaky", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// each tick tries the job once.
+	for i := 1; i <= 3; i++ {
+		h.Tick(start.Add(time.Duration(i) * time.Minute))
+		job, err := h.Detail(jobID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Attempts != i {
+			t.Fatalf("tick %d : expected [%d] attempts got [%d]", i, i, job.Attempts)
+		}
+		if i < 3 && (job.Status != StatusStart || failed != 0) {
+			t.Fatalf("tick %d : expected pending job got status [%d] failed events [%d]", i, job.Status, failed)
+		}
+		if i == 3 && (job.Status != 2 || job.Error != "boom" || failed != 1) {
+			t.Fatalf("tick %d : got [%+v] failed events [%d]", i, job, failed)
+		}
+	}
+	if n, _ := h.Tick(start.Add(time.Hour)); n != 0 {
+		t.Fatalf("expected no more runs got [%d]", n)
+	}
+	if err := h.Register("bad", d, MaxRetries(-1)); err == nil {
+		t.Fatal("expected max retries error")
+	}
+}
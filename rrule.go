@@ -0,0 +1,596 @@
+package worm
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+// parseSpec parses a schedule spec. Besides cron specs it accepts RFC 5545
+// recurrence rules (RRULE:FREQ=DAILY;BYHOUR=3, optionally preceded by a
+// DTSTART line) and ISO 8601 repeating intervals (R5/2016-01-01T03:00:00Z/P1D).
+// Rules without start are anchored at now. Cron specs are parsed in format.
+func parseSpec(spec string, now time.Time, format CronFormat) (cron.Schedule, error) {
+	s := strings.TrimSpace(spec)
+	switch {
+	case strings.HasPrefix(strings.ToUpper(s), "RRULE:"),
+		strings.HasPrefix(strings.ToUpper(s), "DTSTART"):
+		return parseRRule(s, now)
+	case strings.HasPrefix(s, "R") && strings.Contains(s, "/"):
+		return parseRepeat(s, now)
+	}
+	return format.parse(spec)
+}
+
+// frequencies of a recurrence rule, finest first.
+const (
+	freqSecondly = iota
+	freqMinutely
+	freqHourly
+	freqDaily
+	freqWeekly
+	freqMonthly
+	freqYearly
+)
+
+var (
+	rruleFreqs = map[string]int{
+		"SECONDLY": freqSecondly,
+		"MINUTELY": freqMinutely,
+		"HOURLY":   freqHourly,
+		"DAILY":    freqDaily,
+		"WEEKLY":   freqWeekly,
+		"MONTHLY":  freqMonthly,
+		"YEARLY":   freqYearly,
+	}
+	rruleUnits = []string{"second", "minute", "hour", "day", "week", "month", "year"}
+	rruleDays  = map[string]time.Weekday{
+		"SU": time.Sunday,
+		"MO": time.Monday,
+		"TU": time.Tuesday,
+		"WE": time.Wednesday,
+		"TH": time.Thursday,
+		"FR": time.Friday,
+		"SA": time.Saturday,
+	}
+)
+
+// rrule is a cron.Schedule for a subset of RFC 5545 recurrence rules: FREQ,
+// INTERVAL, COUNT, UNTIL, BYMONTH, BYMONTHDAY, BYDAY (without ordinals),
+// BYHOUR, BYMINUTE and BYSECOND.
+type rrule struct {
+	start    time.Time
+	freq     int
+	interval int
+	count    int
+	until    time.Time
+
+	byMonth    []int
+	byMonthDay []int
+	byDay      []time.Weekday
+	byHour     []int
+	byMinute   []int
+	bySecond   []int
+}
+
+// parseRRule parses an RRULE with an optional DTSTART line.
+func parseRRule(spec string, now time.Time) (*rrule, error) {
+	r := &rrule{
+		start:    now.Truncate(time.Second),
+		freq:     -1,
+		interval: 1,
+	}
+	var rule string
+	for _, line := range strings.Fields(spec) {
+		name, value := splitProp(line)
+		switch upper := strings.ToUpper(name); {
+		case strings.HasPrefix(upper, "DTSTART"):
+			t, err := parseICalTime(name, value)
+			if err != nil {
+				return nil, err
+			}
+			r.start = t
+		case upper == "RRULE":
+			rule = value
+		default:
+			return nil, fmt.Errorf("worm: rrule : unknown property [%s]", line)
+		}
+	}
+	if rule == "" {
+		return nil, errors.New("worm: rrule : RRULE not found")
+	}
+
+	for _, part := range strings.Split(rule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("worm: rrule : invalid part [%s]", part)
+		}
+		key, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+		var err error
+		switch key {
+		case "FREQ":
+			freq, ok := rruleFreqs[value]
+			if !ok {
+				return nil, fmt.Errorf("worm: rrule : invalid FREQ [%s]", value)
+			}
+			r.freq = freq
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(value)
+			if err == nil && r.interval < 1 {
+				err = errors.New("interval must be positive")
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(value)
+			if err == nil && r.count < 1 {
+				err = errors.New("count must be positive")
+			}
+		case "UNTIL":
+			r.until, err = parseICalTime("UNTIL", value)
+		case "BYMONTH":
+			r.byMonth, err = parseInts(value, 1, 12)
+		case "BYMONTHDAY":
+			r.byMonthDay, err = parseInts(value, -31, 31)
+		case "BYHOUR":
+			r.byHour, err = parseInts(value, 0, 23)
+		case "BYMINUTE":
+			r.byMinute, err = parseInts(value, 0, 59)
+		case "BYSECOND":
+			r.bySecond, err = parseInts(value, 0, 59)
+		case "BYDAY":
+			for _, d := range strings.Split(value, ",") {
+				wd, ok := rruleDays[d]
+				if !ok {
+					return nil, fmt.Errorf("worm: rrule : unsupported BYDAY [%s]", d)
+				}
+				r.byDay = append(r.byDay, wd)
+			}
+		case "WKST":
+			// weeks always start on monday.
+		default:
+			return nil, fmt.Errorf("worm: rrule : unsupported part [%s]", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("worm: rrule : %s : %s", key, err)
+		}
+	}
+	if r.freq < 0 {
+		return nil, errors.New("worm: rrule : FREQ is required")
+	}
+	if !r.possible() {
+		return nil, errors.New("worm: rrule : BYMONTHDAY : no day in BYMONTH")
+	}
+	return r, nil
+}
+
+// monthDays are the most days of each month, February of leap years.
+var monthDays = []int{31, 29, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+// possible reports if any BYMONTHDAY exists in any BYMONTH.
+func (r *rrule) possible() bool {
+	if len(r.byMonth) < 1 || len(r.byMonthDay) < 1 {
+		return true
+	}
+	for _, m := range r.byMonth {
+		for _, v := range r.byMonthDay {
+			if v <= monthDays[m-1] && -v <= monthDays[m-1] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitProp splits an iCalendar line as name (with params) and value.
+func splitProp(line string) (string, string) {
+	i := strings.Index(line, ":")
+	if i < 0 {
+		return line, ""
+	}
+	return line[:i], line[i+1:]
+}
+
+// parseICalTime parses DATE-TIME and DATE values. A TZID param sets the
+// location, floating times are local.
+func parseICalTime(name, value string) (time.Time, error) {
+	loc := time.Local
+	if i := strings.Index(strings.ToUpper(name), ";TZID="); i > 0 {
+		l, err := time.LoadLocation(name[i+6:])
+		if err != nil {
+			return time.Time{}, err
+		}
+		loc = l
+	}
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse("20060102T150405Z", value)
+	}
+	if len(value) == len("20060102") {
+		return time.ParseInLocation("20060102", value, loc)
+	}
+	return time.ParseInLocation("20060102T150405", value, loc)
+}
+
+// parseInts parses a comma separated list of ints within min and max.
+func parseInts(value string, min, max int) ([]int, error) {
+	var list []int
+	for _, s := range strings.Split(value, ",") {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, err
+		}
+		if v < min || v > max || v == 0 && min < 0 {
+			return nil, fmt.Errorf("value [%d] out of range", v)
+		}
+		list = append(list, v)
+	}
+	sort.Ints(list)
+	return list, nil
+}
+
+// Next implements cron.Schedule. It returns zero time when the rule ended
+// or nothing matches in 5 years.
+func (r *rrule) Next(t time.Time) time.Time {
+	limit := t.AddDate(5, 0, 0)
+	n := 0
+	k := 0
+	// without COUNT the periods before t can be skipped.
+	if r.count == 0 && t.After(r.start) {
+		k = r.skip(t)
+	}
+	for ; ; k++ {
+		from, to := r.period(k)
+		if from.After(limit) {
+			return time.Time{}
+		}
+		// sub daily periods of the days the rule skips have no occurrences,
+		// the next day it fires is looked up instead of each period.
+		if r.freq < freqDaily && !r.matchDay(from) {
+			day := r.nextDay(from, limit)
+			if day.IsZero() {
+				return time.Time{}
+			}
+			if j := r.first(day); j > k {
+				k = j - 1
+			}
+			continue
+		}
+		for _, c := range r.expand(from, to) {
+			if c.Before(r.start) {
+				continue
+			}
+			n++
+			if r.count > 0 && n > r.count {
+				return time.Time{}
+			}
+			if !r.until.IsZero() && c.After(r.until) {
+				return time.Time{}
+			}
+			if c.After(t) {
+				return c.In(t.Location())
+			}
+		}
+	}
+}
+
+// skip returns a period index before t.
+func (r *rrule) skip(t time.Time) int {
+	var k int
+	switch r.freq {
+	case freqSecondly:
+		k = int(t.Sub(r.start) / time.Second)
+	case freqMinutely:
+		k = int(t.Sub(r.start) / time.Minute)
+	case freqHourly:
+		k = int(t.Sub(r.start) / time.Hour)
+	case freqDaily:
+		k = int(t.Sub(r.start) / (24 * time.Hour))
+	case freqWeekly:
+		k = int(t.Sub(r.start) / (7 * 24 * time.Hour))
+	case freqMonthly:
+		k = (t.Year()-r.start.Year())*12 + int(t.Month()-r.start.Month())
+	case freqYearly:
+		k = t.Year() - r.start.Year()
+	}
+	// step back one period for DST and calendar drift.
+	k = k/r.interval - 1
+	if k < 0 {
+		return 0
+	}
+	return k
+}
+
+// nextDay returns the first midnight after the day of t the rule fires on,
+// zero time if none until limit.
+func (r *rrule) nextDay(t, limit time.Time) time.Time {
+	d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for d = d.AddDate(0, 0, 1); !d.After(limit); d = d.AddDate(0, 0, 1) {
+		if r.matchDay(d) {
+			return d
+		}
+	}
+	return time.Time{}
+}
+
+// first returns the index of the first sub daily period starting at t or
+// later.
+func (r *rrule) first(t time.Time) int {
+	unit := time.Second
+	switch r.freq {
+	case freqMinutely:
+		unit = time.Minute
+	case freqHourly:
+		unit = time.Hour
+	}
+	step := unit * time.Duration(r.interval)
+	from, _ := r.period(0)
+	return int((t.Sub(from) + step - 1) / step)
+}
+
+// period returns the bounds of the k period of the rule.
+func (r *rrule) period(k int) (time.Time, time.Time) {
+	s := r.start
+	n := k * r.interval
+	day := time.Date(s.Year(), s.Month(), s.Day(), 0, 0, 0, 0, s.Location())
+	switch r.freq {
+	case freqSecondly:
+		from := s.Add(time.Duration(n) * time.Second)
+		return from, from.Add(time.Second)
+	case freqMinutely:
+		from := time.Date(s.Year(), s.Month(), s.Day(), s.Hour(), s.Minute(), 0, 0, s.Location()).
+			Add(time.Duration(n) * time.Minute)
+		return from, from.Add(time.Minute)
+	case freqHourly:
+		from := time.Date(s.Year(), s.Month(), s.Day(), s.Hour(), 0, 0, 0, s.Location()).
+			Add(time.Duration(n) * time.Hour)
+		return from, from.Add(time.Hour)
+	case freqDaily:
+		from := day.AddDate(0, 0, n)
+		return from, from.AddDate(0, 0, 1)
+	case freqWeekly:
+		monday := day.AddDate(0, 0, -((int(s.Weekday()) + 6) % 7))
+		from := monday.AddDate(0, 0, 7*n)
+		return from, from.AddDate(0, 0, 7)
+	case freqMonthly:
+		from := time.Date(s.Year(), s.Month()+time.Month(n), 1, 0, 0, 0, 0, s.Location())
+		return from, from.AddDate(0, 1, 0)
+	}
+	from := time.Date(s.Year()+n, 1, 1, 0, 0, 0, 0, s.Location())
+	return from, from.AddDate(1, 0, 0)
+}
+
+// expand returns the occurrences within the period sorted.
+func (r *rrule) expand(from, to time.Time) []time.Time {
+	hours := orDefault(r.byHour, r.start.Hour())
+	minutes := orDefault(r.byMinute, r.start.Minute())
+	seconds := orDefault(r.bySecond, r.start.Second())
+	// sub daily periods keep their own units and only expand finer ones.
+	switch r.freq {
+	case freqSecondly:
+		seconds = []int{from.Second()}
+		fallthrough
+	case freqMinutely:
+		minutes = []int{from.Minute()}
+		fallthrough
+	case freqHourly:
+		hours = []int{from.Hour()}
+	}
+	var list []time.Time
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		if !r.matchDay(d) {
+			continue
+		}
+		for _, hh := range hours {
+			if r.freq < freqDaily && !r.match(r.byHour, hh) {
+				continue
+			}
+			for _, mm := range minutes {
+				if r.freq < freqHourly && !r.match(r.byMinute, mm) {
+					continue
+				}
+				for _, ss := range seconds {
+					if r.freq < freqMinutely && !r.match(r.bySecond, ss) {
+						continue
+					}
+					list = append(list, time.Date(d.Year(), d.Month(), d.Day(), hh, mm, ss, 0, d.Location()))
+				}
+			}
+		}
+		if r.freq < freqDaily {
+			break
+		}
+	}
+	return list
+}
+
+// matchDay reports if the rule fires on day d.
+func (r *rrule) matchDay(d time.Time) bool {
+	if !r.match(r.byMonth, int(d.Month())) {
+		return false
+	}
+	if len(r.byMonthDay) > 0 {
+		last := time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, d.Location()).Day()
+		ok := false
+		for _, v := range r.byMonthDay {
+			if v == d.Day() || v < 0 && last+v+1 == d.Day() {
+				ok = true
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	if len(r.byDay) > 0 {
+		ok := false
+		for _, wd := range r.byDay {
+			if wd == d.Weekday() {
+				ok = true
+			}
+		}
+		return ok
+	}
+	if len(r.byMonthDay) > 0 {
+		return true
+	}
+	// without BY day parts the day of start is used.
+	switch r.freq {
+	case freqWeekly:
+		return d.Weekday() == r.start.Weekday()
+	case freqMonthly:
+		return d.Day() == r.start.Day()
+	case freqYearly:
+		return d.Day() == r.start.Day() && (len(r.byMonth) > 0 || d.Month() == r.start.Month())
+	}
+	return true
+}
+
+// match reports if v is in list. Empty lists match everything.
+func (r *rrule) match(list []int, v int) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// orDefault returns list or a list with v when empty.
+func orDefault(list []int, v int) []int {
+	if len(list) > 0 {
+		return list
+	}
+	return []int{v}
+}
+
+// summary describes the rule.
+func (r *rrule) summary() string {
+	unit := rruleUnits[r.freq]
+	s := "every " + unit
+	if r.interval > 1 {
+		s = fmt.Sprintf("every %d %ss", r.interval, unit)
+	}
+	if len(r.byDay) > 0 {
+		days := make([]int, len(r.byDay))
+		for i, wd := range r.byDay {
+			days[i] = int(wd)
+		}
+		sort.Ints(days)
+		s += " on " + ranges(days, dowNames)
+	}
+	if len(r.byMonthDay) > 0 {
+		s += " on day " + ranges(r.byMonthDay, nil)
+	}
+	if len(r.byMonth) > 0 {
+		s += " in " + ranges(r.byMonth, monthNames)
+	}
+	if r.freq >= freqDaily && len(r.byHour) < 2 && len(r.byMinute) < 2 {
+		hours := orDefault(r.byHour, r.start.Hour())
+		minutes := orDefault(r.byMinute, r.start.Minute())
+		s += fmt.Sprintf(" at %02d:%02d", hours[0], minutes[0])
+	}
+	if r.count > 0 {
+		s += fmt.Sprintf(" %d times", r.count)
+	}
+	if !r.until.IsZero() {
+		s += " until " + r.until.Format(time.RFC3339)
+	}
+	return s
+}
+
+// repeat is a cron.Schedule for ISO 8601 repeating intervals.
+type repeat struct {
+	start time.Time
+	// count is the number of occurrences, zero repeats forever.
+	count               int
+	years, months, days int
+	duration            time.Duration
+	period              string
+}
+
+// isoDuration matches ISO 8601 durations like P1Y2M3W4DT5H6M7S.
+var isoDuration = regexp.MustCompile(`^P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseRepeat parses Rn/start/duration or Rn/duration intervals.
+func parseRepeat(spec string, now time.Time) (*repeat, error) {
+	parts := strings.Split(spec, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("worm: invalid repeating interval [%s]", spec)
+	}
+	x := &repeat{start: now.Truncate(time.Second)}
+	if n := parts[0][1:]; n != "" {
+		count, err := strconv.Atoi(n)
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("worm: invalid repetitions [%s]", parts[0])
+		}
+		x.count = count
+	}
+	period := parts[len(parts)-1]
+	if len(parts) == 3 {
+		t, err := time.Parse(time.RFC3339, parts[1])
+		if err != nil {
+			return nil, err
+		}
+		x.start = t
+	}
+	m := isoDuration.FindStringSubmatch(period)
+	if m == nil || period == "P" || strings.HasSuffix(period, "T") {
+		return nil, fmt.Errorf("worm: invalid duration [%s]", period)
+	}
+	v := make([]int, len(m))
+	for i := 1; i < len(m); i++ {
+		if m[i] != "" {
+			v[i], _ = strconv.Atoi(m[i])
+		}
+	}
+	x.years, x.months, x.days = v[1], v[2], 7*v[3]+v[4]
+	x.duration = time.Duration(v[5])*time.Hour +
+		time.Duration(v[6])*time.Minute +
+		time.Duration(v[7])*time.Second
+	x.period = period
+	if x.years == 0 && x.months == 0 && x.days == 0 && x.duration < time.Second {
+		return nil, fmt.Errorf("worm: duration too short [%s]", period)
+	}
+	return x, nil
+}
+
+// at returns the i occurrence.
+func (x *repeat) at(i int) time.Time {
+	return x.start.AddDate(x.years*i, x.months*i, x.days*i).Add(x.duration * time.Duration(i))
+}
+
+// Next implements cron.Schedule.
+func (x *repeat) Next(t time.Time) time.Time {
+	i := 0
+	if t.After(x.start) {
+		approx := time.Duration(x.years)*365*24*time.Hour +
+			time.Duration(x.months)*30*24*time.Hour +
+			time.Duration(x.days)*24*time.Hour + x.duration
+		i = int(t.Sub(x.start) / approx)
+	}
+	for i > 0 && x.at(i-1).After(t) {
+		i--
+	}
+	for !x.at(i).After(t) {
+		i++
+	}
+	if x.count > 0 && i >= x.count {
+		return time.Time{}
+	}
+	return x.at(i).In(t.Location())
+}
+
+// summary describes the interval.
+func (x *repeat) summary() string {
+	s := "every " + x.period + " from " + x.start.Format(time.RFC3339)
+	if x.count > 0 {
+		s += fmt.Sprintf(" %d times", x.count)
+	}
+	return s
+}
@@ -0,0 +1,127 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSpecRRule(t *testing.T) {
+	from := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	table := []struct {
+		spec string
+		// n is the number of runs asked, bounded rules return less.
+		n        int
+		expected []string
+	}{
+		{
+			"DTSTART:20160101T030000Z RRULE:FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR",
+			3,
+			[]string{"2016-01-01T03:00:00Z", "2016-01-04T03:00:00Z", "2016-01-05T03:00:00Z"},
+		},
+		{
+			"DTSTART:20160101T090000Z RRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=2",
+			5,
+			[]string{"2016-01-01T09:00:00Z", "2016-01-15T09:00:00Z"},
+		},
+		{
+			"DTSTART:20160101T000000Z RRULE:FREQ=MONTHLY;BYMONTHDAY=-1;BYHOUR=23;BYMINUTE=30",
+			3,
+			[]string{"2016-01-31T23:30:00Z", "2016-02-29T23:30:00Z", "2016-03-31T23:30:00Z"},
+		},
+		{
+			"DTSTART:20160101T001500Z RRULE:FREQ=HOURLY;INTERVAL=6;UNTIL=20160101T130000Z",
+			5,
+			[]string{"2016-01-01T00:15:00Z", "2016-01-01T06:15:00Z", "2016-01-01T12:15:00Z"},
+		},
+		{
+			"DTSTART:20160201T000000Z RRULE:FREQ=SECONDLY;INTERVAL=7;BYMONTH=1",
+			2,
+			[]string{"2017-01-01T00:00:06Z", "2017-01-01T00:00:13Z"},
+		},
+		{
+			"R3/2016-01-01T03:00:00Z/P1D",
+			5,
+			[]string{"2016-01-01T03:00:00Z", "2016-01-02T03:00:00Z", "2016-01-03T03:00:00Z"},
+		},
+		{
+			"R/2015-12-31T23:00:00Z/PT1H30M",
+			3,
+			[]string{"2016-01-01T00:30:00Z", "2016-01-01T02:00:00Z", "2016-01-01T03:30:00Z"},
+		},
+	}
+	for _, x := range table {
+		list, err := NextRuns(x.spec, x.n, from)
+		if err != nil {
+			t.Errorf("spec [%s] : err [%s]", x.spec, err)
+			continue
+		}
+		if len(list) != len(x.expected) {
+			t.Errorf("spec [%s] : expected %d runs got [%v]", x.spec, len(x.expected), list)
+			continue
+		}
+		for i, s := range x.expected {
+			if list[i].Format(time.RFC3339) != s {
+				t.Errorf("spec [%s] : run %d : expected [%s] got [%s]", x.spec, i, s, list[i].Format(time.RFC3339))
+			}
+		}
+	}
+
+	for _, spec := range []string{
+		"RRULE:BYHOUR=3",
+		"RRULE:FREQ=DAILY;BYDAY=1MO",
+		"RRULE:FREQ=DAILY;BYHOUR=24",
+		"RRULE:FREQ=MINUTELY;BYMONTH=2;BYMONTHDAY=30",
+		"RRULE:FREQ=SECONDLY;BYMONTH=4,6;BYMONTHDAY=-31",
+		"R/2016-01-01T00:00:00Z/P",
+		"R0/P1D",
+	} {
+		if _, err := parseSpec(spec, from, CronDefault); err == nil {
+			t.Errorf("spec [%s] : expected error", spec)
+		}
+	}
+}
+
+func TestRRuleSkip(t *testing.T) {
+	r, err := parseRRule("DTSTART:20100101T030000Z RRULE:FREQ=MINUTELY;INTERVAL=7", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	next := r.Next(now)
+	if !next.After(now) || next.Sub(now) > 7*time.Minute {
+		t.Errorf("expected next run within 7 minutes got [%s]", next)
+	}
+	if next.Sub(r.start)%(7*time.Minute) != 0 {
+		t.Errorf("next run [%s] not aligned to start", next)
+	}
+}
+
+func TestRRuleSparse(t *testing.T) {
+	feb := time.Date(2016, 2, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC)
+	table := []struct {
+		spec     string
+		from     time.Time
+		expected time.Time
+	}{
+		{"RRULE:FREQ=SECONDLY;BYMONTH=1", feb, time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"RRULE:FREQ=HOURLY;BYMONTHDAY=31;BYDAY=SU", feb, time.Date(2016, 7, 31, 0, 0, 0, 0, time.UTC)},
+		{"RRULE:FREQ=SECONDLY;BYMONTH=2;BYMONTHDAY=29;BYDAY=MO", feb, time.Date(2016, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// the next Monday February 29 is in 2044.
+		{"RRULE:FREQ=SECONDLY;BYMONTH=2;BYMONTHDAY=29;BYDAY=MO", mar, time.Time{}},
+	}
+	for _, x := range table {
+		r, err := parseRRule(x.spec, x.from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		began := time.Now()
+		next := r.Next(x.from.Add(time.Second))
+		if elapsed := time.Since(began); elapsed > 100*time.Millisecond {
+			t.Errorf("spec [%s] : took [%s]", x.spec, elapsed)
+		}
+		if !next.Equal(x.expected) {
+			t.Errorf("spec [%s] : expected [%s] got [%s]", x.spec, x.expected, next)
+		}
+	}
+}
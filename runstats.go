@@ -0,0 +1,93 @@
+package worm
+
+import (
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+// WorkerRunStats contains the execution statistics of a worker over a window.
+type WorkerRunStats struct {
+	Worker    string `json:"worker"`
+	Runs      int    `json:"runs"`
+	Succeeded int    `json:"succeeded"`
+	Failed    int    `json:"failed"`
+	// SuccessRate is Succeeded over Runs, zero without runs.
+	SuccessRate float64 `json:"success_rate"`
+	// Throughput is the finished runs per minute.
+	Throughput float64       `json:"throughput"`
+	P50        time.Duration `json:"p50"`
+	P95        time.Duration `json:"p95"`
+}
+
+// RunStats returns the execution statistics of the runs finished in the last
+// window, per worker sorted by name.
+func (h *Worm) RunStats(window time.Duration) ([]*WorkerRunStats, error) {
+	var rows []struct {
+		Worker     string    `db:"worker_name"`
+		Status     int       `db:"status"`
+		StartedAt  time.Time `db:"started_at"`
+		FinishedAt time.Time `db:"finished_at"`
+	}
+	o := <-h.waitc
+	err := h.dbSelect(&rows, `
+		SELECT w.worker_name, r.status, r.started_at, r.finished_at
+		FROM worm_run r JOIN worm w ON w.id=r.job_id
+		WHERE r.finished_at IS NOT NULL AND r.finished_at>=?;
+	`, h.now().Add(-window))
+	h.waitc <- o
+	if err != nil {
+		log.Printf("RunStats : select : err [%s]", err)
+		return nil, err
+	}
+
+	index := make(map[string]*WorkerRunStats)
+	durations := make(map[string][]time.Duration)
+	var list []*WorkerRunStats
+	for _, r := range rows {
+		st, ok := index[r.Worker]
+		if !ok {
+			st = &WorkerRunStats{Worker: r.Worker}
+			index[r.Worker] = st
+			list = append(list, st)
+		}
+		st.Runs++
+		if r.Status == StatusOK {
+			st.Succeeded++
+		} else {
+			st.Failed++
+		}
+		durations[r.Worker] = append(durations[r.Worker], r.FinishedAt.Sub(r.StartedAt))
+	}
+	for _, st := range list {
+		st.SuccessRate = float64(st.Succeeded) / float64(st.Runs)
+		st.Throughput = float64(st.Runs) / window.Minutes()
+		d := durations[st.Worker]
+		sort.Slice(d, func(i, j int) bool {
+			return d[i] < d[j]
+		})
+		st.P50, st.P95 = percentile(d, 0.5), percentile(d, 0.95)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Worker < list[j].Worker
+	})
+	return list, nil
+}
+
+// percentile returns the nearest rank percentile p of the sorted list.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) < 1 {
+		return 0
+	}
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+// RunStats _
+func RunStats(window time.Duration) ([]*WorkerRunStats, error) {
+	return defaultWorm.RunStats(window)
+}
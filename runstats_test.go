@@ -0,0 +1,52 @@
+package worm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	var list []time.Duration
+	for i := 1; i <= 20; i++ {
+		list = append(list, time.Duration(i)*time.Second)
+	}
+	if p := percentile(list, 0.5); p != 10*time.Second {
+		t.Errorf("p50 : expected 10s got [%s]", p)
+	}
+	if p := percentile(list, 0.95); p != 19*time.Second {
+		t.Errorf("p95 : expected 19s got [%s]", p)
+	}
+	if p := percentile(nil, 0.5); p != 0 {
+		t.Errorf("empty : expected 0 got [%s]", p)
+	}
+}
+
+func TestRunStats(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	h.MustRegister("ok", &testDoer{name: "ok"})
+	h.MustRegister("fail", &testDoer{name: "fail", status: 2, err: errors.New("boom")})
+
+	for i := 0; i < 3; i++ {
+		h.Queue("ok", nil)
+	}
+	h.Queue("fail", nil)
+	h.Tick(start.Add(time.Minute))
+
+	list, err := h.RunStats(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected 2 workers got [%d]", len(list))
+	}
+	fail, ok := list[0], list[1]
+	if fail.Worker != "fail" || fail.Runs != 1 || fail.Failed != 1 || fail.SuccessRate != 0 {
+		t.Errorf("fail : got [%+v]", fail)
+	}
+	if ok.Worker != "ok" || ok.Runs != 3 || ok.SuccessRate != 1 || ok.Throughput != 3.0/60 {
+		t.Errorf("ok : got [%+v]", ok)
+	}
+}
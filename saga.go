@@ -0,0 +1,124 @@
+package worm
+
+import (
+	"encoding/json"
+	"log"
+)
+
+// Compensation is the data of compensating jobs.
+type Compensation struct {
+	// JobID, Worker and Data are the job to undo.
+	JobID  string `json:"job_id"`
+	Worker string `json:"worker"`
+	Data   []byte `json:"data"`
+	// FailedJobID is the failed job, JobID itself or a later step of its
+	// workflow.
+	FailedJobID string `json:"failed_job_id"`
+	Status      int    `json:"status"`
+	Error       string `json:"error"`
+}
+
+// Compensate queues a job of the compensating worker workerName when a job
+// of this worker fails, or when a later step of its workflow fails after it
+// succeeded, once per step. The compensating job gets a Compensation as data and the
+// compensated job as parent.
+func Compensate(workerName string) WorkerOption {
+	return func(w *worker) error {
+		w.compensation = workerName
+		return nil
+	}
+}
+
+// compensate queues the compensations of the failed job and, if it breaks
+// its workflow, of the steps that succeeded before it, latest first.
+func (h *Worm) compensate(jobID, workerName, workflowID string, status int, errMsg string) {
+	type target struct{ id, worker string }
+	targets := []target{{jobID, workerName}}
+	if len(workflowID) > 0 {
+		list, err := h.steps(workflowID)
+		if err != nil {
+			log.Printf("compensate : steps : err [%s] job id [%s]", err, jobID)
+			return
+		}
+		if breaks(list, jobID) {
+			for i := len(list) - 1; i >= 0; i-- {
+				if list[i].state() == StateSucceeded {
+					targets = append(targets, target{list[i].ID, list[i].Worker})
+				}
+			}
+		}
+	}
+
+	for _, t := range targets {
+		h.RLock()
+		wk, ok := h.workers[t.worker]
+		h.RUnlock()
+		if !ok || len(wk.compensation) < 1 {
+			continue
+		}
+		// steps before the failed one are undone once.
+		if t.id != jobID && h.compensated(t.id, wk.compensation) {
+			continue
+		}
+		data, err := h.payload(t.id)
+		if err != nil {
+			log.Printf("compensate : payload : err [%s] job id [%s]", err, t.id)
+			continue
+		}
+		b, err := json.Marshal(Compensation{
+			JobID:       t.id,
+			Worker:      t.worker,
+			Data:        data,
+			FailedJobID: jobID,
+			Status:      status,
+			Error:       errMsg,
+		})
+		if err != nil {
+			log.Printf("compensate : marshal : err [%s] job id [%s]", err, t.id)
+			continue
+		}
+		opts := newJobOptions(once, nil)
+		opts.parentID = t.id
+		if _, err := h.queue(wk.compensation, b, opts); err != nil {
+			log.Printf("compensate : queue [%s] : err [%s] job id [%s]", wk.compensation, err, t.id)
+		}
+	}
+}
+
+// compensated reports if jobID already has a compensating job.
+func (h *Worm) compensated(jobID, compensation string) bool {
+	var n int
+	o := <-h.waitc
+	err := h.dbGet(&n, `
+		SELECT COUNT(*) FROM worm WHERE parent_id=? AND worker_name=?;
+	`, jobID, compensation)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("compensated : err [%s] job id [%s]", err, jobID)
+	}
+	return n > 0
+}
+
+// breaks reports if the failure of the step jobID fails the workflow, that
+// is unless every step after it is a fan in step.
+func breaks(list []*workflowStep, jobID string) bool {
+	var name string
+	for _, s := range list {
+		if s.ID == jobID {
+			name = s.Step
+		}
+	}
+	dependents := 0
+	for _, s := range list {
+		for _, after := range s.after() {
+			if after != name {
+				continue
+			}
+			if !s.FanIn {
+				return true
+			}
+			dependents++
+		}
+	}
+	return dependents < 1
+}
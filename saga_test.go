@@ -0,0 +1,56 @@
+package worm
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCompensate(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	undo := &dataDoer{}
+	h.MustRegister("undo", undo)
+	h.MustRegister("reserve", &testDoer{name: "reserve"}, Compensate("undo"))
+	h.MustRegister("charge", &testDoer{name: "charge", status: 2, err: errors.New("card declined")})
+
+	_, err := h.Workflow([]Step{
+		{Name: "reserve", Worker: "reserve", Data: []byte("seat 1A")},
+		{Name: "charge", Worker: "charge", After: []string{"reserve"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(time.Minute))
+
+	var x Compensation
+	if err := json.Unmarshal(undo.data, &x); err != nil {
+		t.Fatalf("compensation data [%s] : err [%s]", undo.data, err)
+	}
+	if x.Worker != "reserve" || string(x.Data) != "seat 1A" || x.Status != 2 || x.Error != "card declined" {
+		t.Fatalf("compensation : got [%+v]", x)
+	}
+	reserve, err := h.Detail(x.JobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reserve.Children) != 1 {
+		t.Fatalf("reserve children : expected 1 got [%v]", reserve.Children)
+	}
+}
+
+func TestBreaks(t *testing.T) {
+	list := []*workflowStep{
+		{ID: "1", Step: "a"},
+		{ID: "2", Step: "b", After: "a"},
+		{ID: "3", Step: "c", After: "a,b", FanIn: true},
+	}
+	table := map[string]bool{"1": true, "2": false, "3": true}
+	for jobID, expected := range table {
+		if got := breaks(list, jobID); got != expected {
+			t.Errorf("job [%s] : expected [%v] got [%v]", jobID, expected, got)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package worm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"time"
+)
+
+// ManagedByKey is the metadata label naming the config source of the
+// recurring jobs stored by ApplySchedules.
+const ManagedByKey = "worm.managed-by"
+
+// ScheduleDef declares a recurring job in a schedule config file:
+//
+//	{"schedules": [{
+//		"name": "nightly-report",
+//		"worker": "report",
+//		"cron": "0 0 2 * * *",
+//		"payload": {"format": "pdf"},
+//		"window": {"from": "1h", "to": "4h"},
+//		"metadata": {"team": "billing"},
+//		"disabled": false
+//	}]}
+type ScheduleDef struct {
+	// Name is the schedule ID of the job.
+	Name   string `json:"name"`
+	Worker string `json:"worker"`
+	Cron   string `json:"cron"`
+	// Payload is stored as the job data, JSON encoded.
+	Payload  json.RawMessage   `json:"payload,omitempty"`
+	Window   *WindowDef        `json:"window,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Disabled bool              `json:"disabled,omitempty"`
+}
+
+// WindowDef is the Window of a ScheduleDef, durations since midnight like
+// "1h30m".
+type WindowDef struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ScheduleSync lists the schedule IDs changed by ApplySchedules.
+type ScheduleSync struct {
+	Created []string `json:"created"`
+	Updated []string `json:"updated"`
+	Deleted []string `json:"deleted"`
+}
+
+// ParseSchedules decodes a JSON schedule config file, see ScheduleDef.
+func ParseSchedules(b []byte) ([]ScheduleDef, error) {
+	var file struct {
+		Schedules []ScheduleDef `json:"schedules"`
+	}
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, err
+	}
+	return file.Schedules, nil
+}
+
+// LoadSchedules reads the schedule config file at path and applies it with
+// ApplySchedules, path being the source. unmarshal decodes files in other
+// formats than JSON, e.g. yaml.Unmarshal of gopkg.in/yaml.v3; nil for JSON.
+func (h *Worm) LoadSchedules(path string, unmarshal func([]byte, interface{}) error) (*ScheduleSync, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if unmarshal != nil {
+		var v interface{}
+		if err := unmarshal(b, &v); err != nil {
+			return nil, err
+		}
+		if b, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	defs, err := ParseSchedules(b)
+	if err != nil {
+		return nil, fmt.Errorf("worm: schedule config %s: %s", path, err)
+	}
+	return h.ApplySchedules(path, defs)
+}
+
+// ApplySchedules reconciles the recurring jobs declared by source, e.g. a
+// config file checked into the repository, with the stored ones: each
+// definition is stored or updated like SchedUpsert and enabled or disabled,
+// and the schedules stored by source before but missing from defs are
+// deleted. The jobs are labeled with source under ManagedByKey; schedules
+// stored otherwise are never deleted. Nothing is applied when a definition
+// is invalid.
+func (h *Worm) ApplySchedules(source string, defs []ScheduleDef) (*ScheduleSync, error) {
+	if len(source) < 1 {
+		return nil, errors.New("worm: empty schedule source")
+	}
+	opts := make([][]JobOption, len(defs))
+	names := make(map[string]bool)
+	for i, def := range defs {
+		if len(def.Name) < 1 || len(def.Worker) < 1 || len(def.Cron) < 1 {
+			return nil, fmt.Errorf("worm: schedule %d: name, worker and cron are required", i)
+		}
+		if names[def.Name] {
+			return nil, fmt.Errorf("worm: schedule %s: declared twice", def.Name)
+		}
+		names[def.Name] = true
+		wk, ok := h.target(def.Worker)
+		if !ok {
+			return nil, fmt.Errorf("worm: schedule %s: worker %s not registered", def.Name, def.Worker)
+		}
+		if err := wk.validate(def.Payload); err != nil {
+			return nil, fmt.Errorf("worm: schedule %s: %s", def.Name, err)
+		}
+		if def.Window != nil {
+			from, err := time.ParseDuration(def.Window.From)
+			if err != nil {
+				return nil, fmt.Errorf("worm: schedule %s: window from: %s", def.Name, err)
+			}
+			to, err := time.ParseDuration(def.Window.To)
+			if err != nil {
+				return nil, fmt.Errorf("worm: schedule %s: window to: %s", def.Name, err)
+			}
+			opts[i] = append(opts[i], Window(from, to))
+		}
+		if _, err := h.schedule(def.Cron, newJobOptions(def.Cron, opts[i])); err != nil {
+			return nil, fmt.Errorf("worm: schedule %s: %s", def.Name, err)
+		}
+	}
+
+	x := &ScheduleSync{}
+	for i, def := range defs {
+		_, err := h.scheduled(def.Name)
+		created := err != nil
+		jobID, err := h.SchedUpsert(def.Name, def.Worker, def.Payload, def.Cron, opts[i]...)
+		if err != nil {
+			return x, fmt.Errorf("worm: schedule %s: %s", def.Name, err)
+		}
+		if err := h.manage(jobID, source, def); err != nil {
+			return x, fmt.Errorf("worm: schedule %s: %s", def.Name, err)
+		}
+		if created {
+			x.Created = append(x.Created, def.Name)
+		} else {
+			x.Updated = append(x.Updated, def.Name)
+		}
+	}
+
+	managed, err := h.managedSchedules(source)
+	if err != nil {
+		return x, err
+	}
+	for _, scheduleID := range managed {
+		if names[scheduleID] {
+			continue
+		}
+		if err := h.DeleteSchedule(scheduleID); err != nil {
+			return x, fmt.Errorf("worm: schedule %s: %s", scheduleID, err)
+		}
+		x.Deleted = append(x.Deleted, scheduleID)
+	}
+	log.Printf("ApplySchedules : source [%s] created [%d] updated [%d] deleted [%d]",
+		source, len(x.Created), len(x.Updated), len(x.Deleted))
+	return x, nil
+}
+
+// manage labels the recurring job with source and the metadata of def, and
+// sets its disabled state.
+func (h *Worm) manage(jobID, source string, def ScheduleDef) error {
+	md := JobMetadata{ManagedByKey: source}
+	for k, v := range def.Metadata {
+		if k != ManagedByKey {
+			md[k] = v
+		}
+	}
+	var disabledAt interface{}
+	if def.Disabled {
+		disabledAt = h.now()
+	}
+	o := <-h.waitc
+	_, err := h.dbExec(`
+		UPDATE worm SET metadata=?,disabled_at=CASE WHEN ? THEN IFNULL(disabled_at,?) END
+		WHERE id=?;
+	`, md, def.Disabled, disabledAt, jobID)
+	h.waitc <- o
+	return err
+}
+
+// managedSchedules returns the schedule IDs of the recurring jobs labeled
+// with source.
+func (h *Worm) managedSchedules(source string) ([]string, error) {
+	var jobs []struct {
+		ScheduleID string      `db:"schedule_id"`
+		Metadata   JobMetadata `db:"metadata"`
+	}
+	o := <-h.waitc
+	err := h.dbSelect(&jobs, `
+		SELECT schedule_id, metadata FROM worm
+		WHERE cron<>'' AND schedule_id<>'' AND deleted_at IS NULL;
+	`)
+	h.waitc <- o
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, job := range jobs {
+		if job.Metadata[ManagedByKey] == source {
+			ids = append(ids, job.ScheduleID)
+		}
+	}
+	return ids, nil
+}
+
+// LoadSchedules _
+func LoadSchedules(path string, unmarshal func([]byte, interface{}) error) (*ScheduleSync, error) {
+	return defaultWorm.LoadSchedules(path, unmarshal)
+}
+
+// ApplySchedules _
+func ApplySchedules(source string, defs []ScheduleDef) (*ScheduleSync, error) {
+	return defaultWorm.ApplySchedules(source, defs)
+}
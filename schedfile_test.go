@@ -0,0 +1,116 @@
+package worm
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadSchedules(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	d := &dataDoer{}
+	h.MustRegister("data", d)
+	h.MustRegister("count", &countDoer{})
+	h.MustRegister("checked", &testDoer{name: "checked"}, Validate(func(data []byte) error {
+		if string(data) == `"bad"` {
+			return errors.New("bad payload")
+		}
+		return nil
+	}))
+	if err := h.RegisterCron("in-code", "@hourly", "count", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "schedules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "schedules.json")
+	write := func(s string) {
+		if err := ioutil.WriteFile(path, []byte(s), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`{"schedules": [
+		{"name": "report", "worker": "data", "cron": "0 0 * * * *", "payload": {"format": "pdf"},
+			"metadata": {"team": "billing"}},
+		{"name": "cleanup", "worker": "count", "cron": "@daily"}
+	]}`)
+	x, err := h.LoadSchedules(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(x.Created) != 2 || len(x.Updated) != 0 || len(x.Deleted) != 0 {
+		t.Fatalf("first load : got [%+v]", x)
+	}
+	h.Tick(start.Add(time.Hour))
+	if string(d.data) != `{"format": "pdf"}` {
+		t.Fatalf("payload : got [%s]", d.data)
+	}
+	report, err := h.Schedule("report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, err := h.Detail(report.JobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Metadata[ManagedByKey] != path || job.Metadata["team"] != "billing" {
+		t.Fatalf("metadata : got [%v]", job.Metadata)
+	}
+
+	// the next deploy drops cleanup and pauses the report, decoded by a
+	// custom unmarshal.
+	write(`{"schedules": [
+		{"name": "report", "worker": "data", "cron": "0 */30 * * * *", "disabled": true}
+	]}`)
+	x, err = h.LoadSchedules(path, json.Unmarshal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(x.Created) != 0 || len(x.Updated) != 1 || len(x.Deleted) != 1 || x.Deleted[0] != "cleanup" {
+		t.Fatalf("second load : got [%+v]", x)
+	}
+	list, err := h.Schedules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := map[string]*Schedule{}
+	for _, s := range list {
+		ids[s.ID] = s
+	}
+	if len(ids) != 2 || ids["in-code"] == nil || ids["report"] == nil {
+		t.Fatalf("schedules : got [%v]", ids)
+	}
+	if ids["report"].Spec != "0 */30 * * * *" || ids["report"].DisabledAt == nil || ids["report"].JobID != report.JobID {
+		t.Fatalf("report : got [%+v]", ids["report"])
+	}
+
+	for _, defs := range [][]ScheduleDef{
+		{{Name: "a", Worker: "data", Cron: "@hourly"}, {Name: "a", Worker: "data", Cron: "@daily"}},
+		{{Name: "a", Worker: "missing", Cron: "@hourly"}},
+		{{Name: "a", Worker: "data", Cron: "bad spec"}},
+		{{Name: "a", Worker: "data", Cron: "@hourly", Window: &WindowDef{From: "1h", To: "1h"}}},
+		{{Worker: "data", Cron: "@hourly"}},
+		// the payload of a later definition is checked before storing any.
+		{{Name: "new", Worker: "data", Cron: "@hourly"}, {Name: "b", Worker: "checked", Cron: "@hourly", Payload: json.RawMessage(`"bad"`)}},
+	} {
+		if _, err := h.ApplySchedules(path, defs); err == nil {
+			t.Fatalf("expected error for [%+v]", defs)
+		}
+	}
+	// invalid definitions change nothing.
+	if _, err := h.Schedule("report"); err != nil {
+		t.Fatalf("report : expected kept got [%v]", err)
+	}
+	if _, err := h.Schedule("new"); err != ErrNotFound {
+		t.Fatalf("new : expected ErrNotFound got [%v]", err)
+	}
+}
@@ -0,0 +1,237 @@
+package worm
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+// maxNextRuns limits NextRuns results.
+const maxNextRuns = 1000
+
+// NextRuns returns the next n firings of cronSpec, in CronDefault format,
+// after from. The list is shorter if the schedule stops firing.
+func NextRuns(cronSpec string, n int, from time.Time) ([]time.Time, error) {
+	return nextRunsFormat(cronSpec, n, from, CronDefault)
+}
+
+// nextRunsFormat is NextRuns parsing cron specs in format.
+func nextRunsFormat(cronSpec string, n int, from time.Time, format CronFormat) ([]time.Time, error) {
+	if n < 1 || n > maxNextRuns {
+		return nil, errors.New("worm: n out of range 1-1000")
+	}
+	schedule, err := parseSpec(cronSpec, from, format)
+	if err != nil {
+		return nil, err
+	}
+	return nextRuns(schedule, n, from), nil
+}
+
+// nextRuns returns the next n firings of schedule after from.
+func nextRuns(schedule cron.Schedule, n int, from time.Time) []time.Time {
+	list := make([]time.Time, 0, n)
+	t := from
+	for i := 0; i < n; i++ {
+		t = schedule.Next(t)
+		// cron returns zero time when nothing matches in 5 years.
+		if t.IsZero() {
+			break
+		}
+		list = append(list, t)
+	}
+	return list
+}
+
+// CronSpec is the parsed form of a cron spec.
+type CronSpec struct {
+	Spec string `json:"spec"`
+	// Fields are second, minute, hour, dom, month and dow. Empty for @every.
+	Fields []CronField `json:"fields,omitempty"`
+	// Every is the delay of @every specs.
+	Every   time.Duration `json:"every,omitempty"`
+	Summary string        `json:"summary"`
+}
+
+// CronField is a single field of a cron spec.
+type CronField struct {
+	Name string `json:"name"`
+	// Any is true when the field was a wildcard.
+	Any    bool  `json:"any"`
+	Values []int `json:"values"`
+}
+
+// starBit marks wildcard fields in cron.SpecSchedule.
+const starBit = 1 << 63
+
+var (
+	cronFields = []struct {
+		name     string
+		min, max int
+	}{
+		{"second", 0, 59},
+		{"minute", 0, 59},
+		{"hour", 0, 23},
+		{"dom", 1, 31},
+		{"month", 1, 12},
+		{"dow", 0, 6},
+	}
+	monthNames = []string{"", "January", "February", "March", "April", "May",
+		"June", "July", "August", "September", "October", "November", "December"}
+	dowNames = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday",
+		"Friday", "Saturday"}
+)
+
+// Explain parses cronSpec, in CronDefault format, and describes it. Fields
+// are only filled for cron specs.
+func Explain(cronSpec string) (*CronSpec, error) {
+	return explainFormat(cronSpec, CronDefault)
+}
+
+// explainFormat is Explain parsing cron specs in format.
+func explainFormat(cronSpec string, format CronFormat) (*CronSpec, error) {
+	schedule, err := parseSpec(cronSpec, time.Now(), format)
+	if err != nil {
+		return nil, err
+	}
+	x := &CronSpec{Spec: cronSpec}
+	switch s := schedule.(type) {
+	case cron.ConstantDelaySchedule:
+		x.Every = s.Delay
+		x.Summary = "every " + s.Delay.String()
+	case *rrule:
+		x.Summary = s.summary()
+	case *repeat:
+		x.Summary = s.summary()
+	case *cron.SpecSchedule:
+		bits := []uint64{s.Second, s.Minute, s.Hour, s.Dom, s.Month, s.Dow}
+		for i, f := range cronFields {
+			field := CronField{Name: f.name}
+			for v := f.min; v <= f.max; v++ {
+				if bits[i]&(1<<uint(v)) > 0 {
+					field.Values = append(field.Values, v)
+				}
+			}
+			// steps like */15 also set the star bit.
+			field.Any = bits[i]&starBit > 0 && len(field.Values) == f.max-f.min+1
+			x.Fields = append(x.Fields, field)
+		}
+		// like cron, restricted day of month and week fields match either.
+		either := s.Dom&starBit == 0 && s.Dow&starBit == 0
+		x.Summary = summary(x.Fields, either)
+	default:
+		return nil, errors.New("worm: unsupported schedule")
+	}
+	return x, nil
+}
+
+// summary returns a human readable description of the fields.
+func summary(fields []CronField, either bool) string {
+	sec, min, hour := fields[0], fields[1], fields[2]
+	dom, month, dow := fields[3], fields[4], fields[5]
+
+	var parts []string
+	switch {
+	case single(sec) && single(min) && single(hour):
+		at := fmt.Sprintf("at %02d:%02d", hour.Values[0], min.Values[0])
+		if sec.Values[0] != 0 {
+			at += fmt.Sprintf(":%02d", sec.Values[0])
+		}
+		if dom.Any && dow.Any {
+			parts = append(parts, "every day")
+		}
+		parts = append(parts, days(dom, dow, either), at)
+	default:
+		switch {
+		case sec.Any:
+			parts = append(parts, "every second")
+		case isZero(sec) && min.Any:
+			parts = append(parts, "every minute")
+			sec.Any = true
+		case isZero(sec) && isZero(min) && hour.Any:
+			parts = append(parts, "every hour")
+			sec.Any, min.Any = true, true
+		case isZero(sec):
+			sec.Any = true
+		}
+		for _, f := range []CronField{sec, min, hour} {
+			if !f.Any {
+				parts = append(parts, f.Name+" "+ranges(f.Values, nil))
+			}
+		}
+		parts = append(parts, days(dom, dow, either))
+	}
+	if !month.Any {
+		parts = append(parts, "in "+ranges(month.Values, monthNames))
+	}
+
+	list := parts[:0]
+	for _, p := range parts {
+		if p != "" {
+			list = append(list, p)
+		}
+	}
+	return strings.Join(list, " ")
+}
+
+// days describes the day of month and day of week fields, either when
+// days matching any of them run.
+func days(dom, dow CronField, either bool) string {
+	switch {
+	case !dow.Any && !dom.Any:
+		join := " and "
+		if either {
+			join = " or "
+		}
+		return "on day " + ranges(dom.Values, nil) + join + ranges(dow.Values, dowNames)
+	case !dom.Any:
+		return "on day " + ranges(dom.Values, nil) + " of the month"
+	case dow.Any:
+		return ""
+	}
+	switch ranges(dow.Values, nil) {
+	case "1-5":
+		return "every weekday"
+	case "0,6":
+		return "on weekends"
+	}
+	return "on " + ranges(dow.Values, dowNames)
+}
+
+// ranges joins values compressing consecutive runs, e.g. "1-5,7".
+func ranges(values []int, names []string) string {
+	name := func(v int) string {
+		if names != nil {
+			return names[v]
+		}
+		return strconv.Itoa(v)
+	}
+	var list []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		switch {
+		case j-i >= 2:
+			list = append(list, name(values[i])+"-"+name(values[j]))
+		case j > i:
+			list = append(list, name(values[i]), name(values[j]))
+		default:
+			list = append(list, name(values[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(list, ",")
+}
+
+func single(f CronField) bool {
+	return !f.Any && len(f.Values) == 1
+}
+
+func isZero(f CronField) bool {
+	return single(f) && f.Values[0] == 0
+}
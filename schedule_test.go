@@ -0,0 +1,75 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextRuns(t *testing.T) {
+	from := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	list, err := NextRuns("0 0 3 * * 1-5", 3, from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2016-01-01 is a friday.
+	expected := []time.Time{
+		time.Date(2016, 1, 1, 3, 0, 0, 0, time.UTC),
+		time.Date(2016, 1, 4, 3, 0, 0, 0, time.UTC),
+		time.Date(2016, 1, 5, 3, 0, 0, 0, time.UTC),
+	}
+	if len(list) != len(expected) {
+		t.Fatalf("expected %d runs got [%v]", len(expected), list)
+	}
+	for i := range expected {
+		if !list[i].Equal(expected[i]) {
+			t.Errorf("run %d : expected [%s] got [%s]", i, expected[i], list[i])
+		}
+	}
+
+	if _, err := NextRuns("bad spec", 3, from); err == nil {
+		t.Errorf("bad spec : expected error")
+	}
+	if _, err := NextRuns("* * * * * *", 0, from); err == nil {
+		t.Errorf("zero runs : expected error")
+	}
+}
+
+func TestExplain(t *testing.T) {
+	table := []struct {
+		spec    string
+		summary string
+	}{
+		{"0 0 3 * * 1-5", "every weekday at 03:00"},
+		{"@daily", "every day at 00:00"},
+		{"0 30 9 1 * *", "on day 1 of the month at 09:30"},
+		{"0 * * * * *", "every minute"},
+		{"0 0 * * * 0,6", "every hour on weekends"},
+		{"0 */15 * * * *", "minute 0,15,30,45"},
+		{"0 0 12 * 1,7 *", "every day at 12:00 in January,July"},
+		{"@every 1h30m", "every 1h30m0s"},
+		{"0 0 3 15 * 1", "on day 15 or Monday at 03:00"},
+		{"0 0 3 */2 * 1", "on day 1,3,5,7,9,11,13,15,17,19,21,23,25,27,29,31 and Monday at 03:00"},
+	}
+	for _, x := range table {
+		spec, err := Explain(x.spec)
+		if err != nil {
+			t.Errorf("spec [%s] : err [%s]", x.spec, err)
+			continue
+		}
+		if spec.Summary != x.summary {
+			t.Errorf("spec [%s] : expected [%s] got [%s]", x.spec, x.summary, spec.Summary)
+		}
+	}
+
+	spec, err := Explain("0 0 3 * * 1-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dow := spec.Fields[5]
+	if dow.Name != "dow" || dow.Any || len(dow.Values) != 5 {
+		t.Errorf("expected dow 1-5 got [%+v]", dow)
+	}
+	if _, err := Explain("bad spec"); err == nil {
+		t.Errorf("bad spec : expected error")
+	}
+}
@@ -0,0 +1,80 @@
+package worm
+
+import (
+	"errors"
+	"io"
+	"log"
+)
+
+// errRemote is returned by the stand-in doers of scheduler-only hubs.
+var errRemote = errors.New("worm: worker runs on another hub")
+
+// WithSchedulerOnly makes the hub a scheduling tier: each firing of its
+// recurring jobs queues a single execution of their worker and data, the
+// recurring job being its parent, for the hubs sharing the database to
+// run. The hub runs no job itself, and the workers of its schedules and
+// queued jobs need not be registered on it; registered workers still
+// validate the data. Combine with WithLeaderElection to fire each schedule
+// once when several scheduler hubs run.
+func WithSchedulerOnly() Option {
+	return func(h *Worm) {
+		h.schedulerOnly = true
+	}
+}
+
+// remoteDoer stands for the workers not registered on scheduler-only hubs.
+type remoteDoer string
+
+// Name returns the worker name.
+func (d remoteDoer) Name() string {
+	return string(d)
+}
+
+// Run never runs the job, see runAs.
+func (d remoteDoer) Run(data []byte, logOutput io.Writer) (int, error) {
+	return StatusStart, errRemote
+}
+
+// target returns the worker jobs of workerName are stored for: the
+// registered one or, on scheduler-only hubs, a stand-in.
+func (h *Worm) target(workerName string) (*worker, bool) {
+	h.RLock()
+	wk, ok := h.workers[workerName]
+	h.RUnlock()
+	if ok || !h.schedulerOnly {
+		return wk, ok
+	}
+	return &worker{name: workerName, doer: remoteDoer(workerName)}, true
+}
+
+// enqueueFiring queues the single execution of a firing of the recurring
+// job on scheduler-only hubs.
+func (h *Worm) enqueueFiring(jobID string) {
+	var job struct {
+		Worker   string `db:"worker_name"`
+		Disabled bool   `db:"disabled"`
+	}
+	o := <-h.waitc
+	err := h.dbGet(&job, `
+		SELECT worker_name, disabled_at IS NOT NULL AS "disabled"
+		FROM worm WHERE id=? AND cron<>'' AND deleted_at IS NULL;
+	`, jobID)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("enqueueFiring : select : err [%s] job id [%s]", err, jobID)
+		return
+	}
+	if job.Disabled {
+		return
+	}
+	data, err := h.payload(jobID)
+	if err != nil {
+		log.Printf("enqueueFiring : payload : err [%s] job id [%s]", err, jobID)
+		return
+	}
+	opts := newJobOptions(once, nil)
+	opts.parentID = jobID
+	if _, err := h.queue(job.Worker, data, opts); err != nil {
+		log.Printf("enqueueFiring : queue [%s] : err [%s] job id [%s]", job.Worker, err, jobID)
+	}
+}
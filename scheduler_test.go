@@ -0,0 +1,80 @@
+package worm
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSchedulerOnly(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start), WithSchedulerOnly())
+	defer closeTestWorm(t, h)
+
+	// the worker is registered on the execution hubs only.
+	if err := h.RegisterCron("report", "0 0 * * * *", "data", []byte(`"v1"`)); err != nil {
+		t.Fatal(err)
+	}
+	report, err := h.Schedule("report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := h.Tick(start.Add(time.Hour)); err != nil || n != 1 {
+		t.Fatalf("tick : expected 1 firing got [%d] err [%v]", n, err)
+	}
+	if err := h.TriggerSchedule("report"); err != nil {
+		t.Fatal(err)
+	}
+	queued, err := h.Queue("data", []byte(`"v2"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := h.Tick(start.Add(time.Hour)); n != 0 {
+		t.Fatalf("tick : expected no runs got [%d]", n)
+	}
+
+	parent, err := h.Detail(report.JobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parent.Children) != 2 {
+		t.Fatalf("children : expected 2 got [%v]", parent.Children)
+	}
+	if runs, err := h.Runs(report.JobID); err != nil || len(runs) != 0 {
+		t.Fatalf("runs : expected none got [%d] err [%v]", len(runs), err)
+	}
+	for _, id := range append(parent.Children, queued) {
+		job, err := h.Detail(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Worker != "data" || job.Status != StatusStart || job.Cron != "" {
+			t.Fatalf("job [%s] : got [%+v]", id, job)
+		}
+	}
+
+	// an execution hub on the same database runs the queued jobs.
+	other, err := New(filepath.Join(h.logDir, "worm.db"), h.logDir,
+		WithInstanceID("other"), WithManualTick(start.Add(time.Hour)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	d := &dataDoer{}
+	other.MustRegister("data", d)
+	if n, err := other.Tick(start.Add(time.Hour)); err != nil || n != 3 {
+		t.Fatalf("other tick : expected 3 runs got [%d] err [%v]", n, err)
+	}
+	if string(d.data) != `"v2"` {
+		t.Fatalf("other tick : got data [%s]", d.data)
+	}
+	for _, id := range parent.Children {
+		job, err := h.Detail(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status != StatusOK || job.ParentID != report.JobID {
+			t.Fatalf("firing [%s] : got [%+v]", id, job)
+		}
+	}
+}
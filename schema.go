@@ -0,0 +1,179 @@
+package worm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// schema is the supported subset of a JSON Schema.
+type schema struct {
+	Type                 schemaType         `json:"type"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	Items                *schema            `json:"items"`
+	Enum                 []interface{}      `json:"enum"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+}
+
+// schemaType is a type keyword, a single name or a list of names.
+type schemaType []string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *schemaType) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*t = schemaType{one}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(b, &list); err != nil {
+		return errors.New("type must be a string or a list of strings")
+	}
+	*t = list
+	return nil
+}
+
+// schemaErrors are the errors found validating one document.
+type schemaErrors []string
+
+// Error implements error.
+func (e schemaErrors) Error() string {
+	return strings.Join(e, "; ")
+}
+
+// parseSchema parses a JSON Schema document.
+func parseSchema(b []byte) (*schema, error) {
+	var s schema
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("invalid schema: %s", err)
+	}
+	return &s, nil
+}
+
+// validate checks the JSON document data.
+func (s *schema) validate(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return schemaErrors{fmt.Sprintf("invalid json: %s", err)}
+	}
+	var errs schemaErrors
+	s.check("$", v, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// check appends to errs the violations of v at path.
+func (s *schema) check(path string, v interface{}, errs *schemaErrors) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if len(s.Type) > 0 && !s.Type.match(v) {
+		fail("expected %s got %s", strings.Join(s.Type, " or "), jsonType(v))
+		return
+	}
+	if len(s.Enum) > 0 {
+		var found bool
+		for _, e := range s.Enum {
+			if reflect.DeepEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("value not in enum")
+		}
+	}
+
+	switch x := v.(type) {
+	case float64:
+		if s.Minimum != nil && x < *s.Minimum {
+			fail("%v less than minimum %v", x, *s.Minimum)
+		}
+		if s.Maximum != nil && x > *s.Maximum {
+			fail("%v greater than maximum %v", x, *s.Maximum)
+		}
+	case string:
+		n := utf8.RuneCountInString(x)
+		if s.MinLength != nil && n < *s.MinLength {
+			fail("length %d less than minLength %d", n, *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("length %d greater than maxLength %d", n, *s.MaxLength)
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range x {
+				s.Items.check(fmt.Sprintf("%s[%d]", path, i), item, errs)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := x[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p, ok := s.Properties[k]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					fail("unexpected property %q", k)
+				}
+				continue
+			}
+			p.check(path+"."+k, x[k], errs)
+		}
+	}
+}
+
+// match reports whether v is of one of the types.
+func (t schemaType) match(v interface{}) bool {
+	got := jsonType(v)
+	for _, name := range t {
+		if name == got {
+			return true
+		}
+		if name == "number" && got == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type name of a decoded value.
+func jsonType(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if x == float64(int64(x)) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "unknown"
+}
@@ -0,0 +1,112 @@
+package worm
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"time"
+)
+
+// ErrNoSearch is returned by SearchLogs on hubs without WithLogSearch.
+var ErrNoSearch = errors.New("worm: log search not enabled")
+
+// maxIndexed limits the bytes of a log indexed for search.
+const maxIndexed = 1 << 20
+
+// LogMatch is a run whose log matches a SearchLogs query.
+type LogMatch struct {
+	JobID      string     `db:"job_id" json:"job_id"`
+	RunID      int64      `db:"run_id" json:"run_id"`
+	Worker     string     `db:"worker_name" json:"worker_name"`
+	FinishedAt *time.Time `db:"finished_at" json:"finished_at"`
+	// Snippet is the matching text of the log, matches in brackets.
+	Snippet string `db:"snippet" json:"snippet"`
+}
+
+// WithLogSearch indexes the logs of the finished runs, their first MB, in
+// a SQLite full-text index queried by SearchLogs. The index uses FTS5 when
+// the sqlite3 driver is built with the sqlite_fts5 tag, FTS4 otherwise.
+func WithLogSearch() Option {
+	return func(h *Worm) {
+		h.logSearch = true
+	}
+}
+
+// initLogSearch creates the full-text index of the logs.
+func (h *Worm) initLogSearch() error {
+	for _, fts := range []string{"fts5", "fts4"} {
+		_, err := h.Db.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS worm_log_fts USING ` + fts + `(content);`)
+		if err == nil {
+			h.fts = fts
+			return nil
+		}
+		log.Printf("initLogSearch : %s : err [%s]", fts, err)
+	}
+	return errors.New("worm: sqlite full-text search not available")
+}
+
+// indexLog adds the log file of the run to the full-text index.
+func (h *Worm) indexLog(runID int64, name string) {
+	f, err := os.Open(name)
+	if err != nil {
+		log.Printf("indexLog : open : err [%s] run id [%d]", err, runID)
+		return
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(f, maxIndexed))
+	f.Close()
+	if err != nil {
+		log.Printf("indexLog : read : err [%s] run id [%d]", err, runID)
+		return
+	}
+	if len(b) < 1 {
+		return
+	}
+	o := <-h.waitc
+	_, err = h.dbExec(`INSERT INTO worm_log_fts (rowid,content) VALUES (?,?);`, runID, string(b))
+	h.waitc <- o
+	if err != nil {
+		log.Printf("indexLog : insert : err [%s] run id [%d]", err, runID)
+	}
+}
+
+// SearchLogs returns up to limit runs, newest first, of the jobs matching
+// filter whose log matches query, a SQLite full-text query such as
+// `"error 429"` or `timeout OR refused`. Filter sorts don't apply.
+func (h *Worm) SearchLogs(query string, filter JobFilter, limit int) ([]*LogMatch, error) {
+	if len(h.fts) < 1 {
+		return nil, ErrNoSearch
+	}
+	snippet := `snippet(worm_log_fts,'[',']','...',0,12)`
+	if h.fts == "fts5" {
+		snippet = `snippet(worm_log_fts,0,'[',']','...',12)`
+	}
+	where, args := filter.where()
+	var list []*LogMatch
+	o := <-h.waitc
+	err := h.dbSelect(&list, `
+		SELECT
+			r.job_id AS "job_id",
+			r.id AS "run_id",
+			w.worker_name AS "worker_name",
+			r.finished_at AS "finished_at",
+			`+snippet+` AS "snippet"
+		FROM worm_log_fts
+		JOIN worm_run AS r ON r.id=worm_log_fts.rowid
+		JOIN worm AS w ON w.id=r.job_id
+		WHERE worm_log_fts MATCH ? AND r.job_id IN (SELECT id FROM worm WHERE `+where+`)
+		ORDER BY r.id DESC LIMIT ?;
+	`, append(append([]interface{}{query}, args...), limit)...)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("SearchLogs : err [%s]", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+// SearchLogs _
+func SearchLogs(query string, filter JobFilter, limit int) ([]*LogMatch, error) {
+	return defaultWorm.SearchLogs(query, filter, limit)
+}
@@ -0,0 +1,80 @@
+package worm
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// echoDoer writes its data to the log.
+type echoDoer struct{}
+
+func (d *echoDoer) Name() string {
+	return "echo"
+}
+
+func (d *echoDoer) Run(data []byte, w io.Writer) (int, error) {
+	w.Write(data)
+	return StatusOK, nil
+}
+
+func TestSearchLogs(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start), WithLogSearch())
+	defer closeTestWorm(t, h)
+	h.MustRegister("echo", &echoDoer{})
+	h.MustRegister("print", &printDoer{n: 3})
+
+	var ids []string
+	for _, data := range []string{
+		"GET /orders returned error code 429, retrying",
+		"all good",
+		"upstream error code 500",
+	} {
+		jobID, err := h.Queue("echo", []byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, jobID)
+	}
+	if _, err := h.Queue("print", nil); err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start)
+
+	list, err := h.SearchLogs(`"error code" 429`, JobFilter{}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].JobID != ids[0] || list[0].Worker != "echo" || list[0].FinishedAt == nil {
+		t.Fatalf("429 : got [%v]", list)
+	}
+	if !strings.Contains(list[0].Snippet, "[429]") {
+		t.Fatalf("snippet : got [%s]", list[0].Snippet)
+	}
+	if list, err := h.SearchLogs("error", JobFilter{}, 10); err != nil || len(list) != 2 || list[0].JobID != ids[2] {
+		t.Fatalf("error : got [%v] err [%v]", list, err)
+	}
+	if list, err := h.SearchLogs("error", JobFilter{ID: ids[0]}, 10); err != nil || len(list) != 1 {
+		t.Fatalf("filtered : got [%v] err [%v]", list, err)
+	}
+
+	if err := h.Delete(ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Purge(time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if list, err := h.SearchLogs("429", JobFilter{}, 10); err != nil || len(list) != 0 {
+		t.Fatalf("purged : got [%v] err [%v]", list, err)
+	}
+}
+
+func TestSearchLogsDisabled(t *testing.T) {
+	h := newTestWorm(t)
+	defer closeTestWorm(t, h)
+	if _, err := h.SearchLogs("x", JobFilter{}, 10); err != ErrNoSearch {
+		t.Fatalf("expected ErrNoSearch got [%v]", err)
+	}
+}
@@ -0,0 +1,17 @@
+package worm
+
+import "errors"
+
+// errSemaphore is returned by Queue and Sched for semaphores without room.
+var errSemaphore = errors.New("worm: semaphore max must be positive")
+
+// Semaphore makes the job hold the semaphore key while it runs: at most max
+// jobs holding key run at once across the hubs sharing the database,
+// whatever their worker. Jobs over the limit stay due and run when a holder
+// finishes; recurring jobs skip the firing.
+func Semaphore(key string, max int) JobOption {
+	return func(o *jobOptions) {
+		o.semaphore = key
+		o.semaphoreMax = max
+	}
+}
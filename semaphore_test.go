@@ -0,0 +1,66 @@
+package worm
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+// blockDoer runs until released.
+type blockDoer struct {
+	name    string
+	started chan string
+	release chan struct{}
+}
+
+func (d *blockDoer) Name() string {
+	return d.name
+}
+
+func (d *blockDoer) Run(data []byte, w io.Writer) (int, error) {
+	d.started <- string(data)
+	<-d.release
+	return StatusOK, nil
+}
+
+func TestSemaphore(t *testing.T) {
+	h := newTestWorm(t, WithPollInterval(10*time.Millisecond))
+	defer closeTestWorm(t, h)
+
+	started := make(chan string, 10)
+	release := make(chan struct{})
+	invoice := &blockDoer{name: "invoice", started: started, release: release}
+	refund := &blockDoer{name: "refund", started: started, release: release}
+	h.MustRegister("invoice", invoice)
+	h.MustRegister("refund", refund)
+
+	billing := Semaphore("billing-db", 2)
+	var ids []string
+	for _, x := range []struct{ worker, data string }{
+		{"invoice", "a"}, {"refund", "b"}, {"invoice", "c"},
+	} {
+		jobID, err := h.Queue(x.worker, []byte(x.data), billing)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, jobID)
+	}
+	for i := 0; i < 2; i++ {
+		<-started
+	}
+	select {
+	case data := <-started:
+		t.Fatalf("expected 2 jobs holding the semaphore, job [%s] started", data)
+	case <-time.After(100 * time.Millisecond):
+	}
+	release <- struct{}{}
+	<-started
+	close(release)
+	for _, jobID := range ids {
+		waitStatus(t, h, jobID, StatusOK)
+	}
+
+	if _, err := h.Queue("invoice", nil, Semaphore("billing-db", 0)); err != errSemaphore {
+		t.Fatalf("expected errSemaphore got [%v]", err)
+	}
+}
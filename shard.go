@@ -0,0 +1,278 @@
+package worm
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// shardTables are the tables moved to the month shards and their job ID
+// column.
+var shardTables = []struct {
+	name  string
+	jobID string
+}{
+	{"worm", "id"},
+	{"worm_run", "job_id"},
+	{"worm_audit", "job_id"},
+	{"worm_note", "job_id"},
+}
+
+// shardable selects the jobs of the main database moved to the shards:
+// finished single executions created before the bound, except the steps of
+// workflows still running, which advance reads.
+const shardable = `cron='' AND finished_at IS NOT NULL AND deleted_at IS NULL AND created_at<?
+	AND NOT (IFNULL(workflow_id,'')<>'' AND EXISTS (
+		SELECT 1 FROM main.worm AS step
+		WHERE step.workflow_id=worm.workflow_id AND step.finished_at IS NULL
+	))`
+
+// WithMonthShards keeps the main database small by moving, on every sweep,
+// the finished single-execution jobs of past months, their runs, audit and
+// notes, to one SQLite file per month of creation in dir, named
+// worm-2016-01.db. Detail and Query read the shards too, the other APIs
+// see the main database only.
+func WithMonthShards(dir string) Option {
+	return func(h *Worm) {
+		h.shardDir = dir
+	}
+}
+
+// shardFile returns the shard of the month starting at start.
+func (h *Worm) shardFile(start time.Time) string {
+	return filepath.Join(h.shardDir, "worm-"+start.Format("2006-01")+".db")
+}
+
+// shardMonths moves the jobs finished and created before the month of now
+// to their month shards. Returns the moved job count.
+func (h *Worm) shardMonths(now time.Time) (int, error) {
+	if len(h.shardDir) < 1 {
+		return 0, nil
+	}
+	before := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	o := <-h.waitc
+	defer func() {
+		h.waitc <- o
+	}()
+	var months []string
+	err := h.dbSelect(&months, `
+		SELECT DISTINCT substr(created_at,1,7) FROM worm WHERE `+shardable+`;
+	`, before)
+	if err != nil {
+		return 0, err
+	}
+	var total int
+	for _, m := range months {
+		start, err := time.Parse("2006-01", m)
+		if err != nil {
+			return total, err
+		}
+		n, err := h.moveShard(start)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// moveShard moves the jobs of the month starting at start to its shard in
+// one transaction. Must be called holding waitc.
+func (h *Worm) moveShard(start time.Time) (int, error) {
+	ctx := context.Background()
+	// ATTACH applies to a single connection of the pool.
+	conn, err := h.Db.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS shard;`, h.shardFile(start)); err != nil {
+		return 0, err
+	}
+	defer func() {
+		if _, err := conn.ExecContext(ctx, `DETACH DATABASE shard;`); err != nil {
+			log.Printf("moveShard : detach : err [%s]", err)
+		}
+	}()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	n, err := h.moveShardRows(tx, start)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	return int(n), tx.Commit()
+}
+
+// moveShardRows copies the jobs of the month to the attached shard and
+// deletes them from the main database.
+func (h *Worm) moveShardRows(tx *sql.Tx, start time.Time) (int64, error) {
+	jobs := `SELECT id FROM main.worm WHERE created_at>=? AND ` + shardable
+	args := []interface{}{start, start.AddDate(0, 1, 0)}
+	var n int64
+	for _, t := range shardTables {
+		cols, err := shardSchema(tx, t.name)
+		if err != nil {
+			return 0, err
+		}
+		list := strings.Join(cols, ",")
+		res, err := tx.Exec(`
+			INSERT INTO shard.`+t.name+` (`+list+`)
+			SELECT `+list+` FROM main.`+t.name+` WHERE `+t.jobID+` IN (`+jobs+`);
+		`, args...)
+		if err != nil {
+			return 0, err
+		}
+		if t.name == "worm" {
+			if n, err = res.RowsAffected(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if len(h.fts) > 0 {
+		_, err := tx.Exec(`
+			DELETE FROM main.worm_log_fts WHERE rowid IN (
+				SELECT id FROM main.worm_run WHERE job_id IN (`+jobs+`)
+			);
+		`, args...)
+		if err != nil {
+			return 0, err
+		}
+	}
+	// the jobs query reads main.worm, cleared last.
+	for i := len(shardTables) - 1; i >= 0; i-- {
+		t := shardTables[i]
+		_, err := tx.Exec(`
+			DELETE FROM main.`+t.name+` WHERE `+t.jobID+` IN (`+jobs+`);
+		`, args...)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// shardSchema creates the table in the attached shard, or adds the columns
+// migrations added to the main one since, with the declared types so the
+// driver reads times back. Returns the column names.
+func shardSchema(tx *sql.Tx, table string) ([]string, error) {
+	type column struct {
+		name, typ string
+		pk        bool
+	}
+	read := func(schema string) ([]column, error) {
+		rows, err := tx.Query(`SELECT name,type,pk FROM pragma_table_info(?,?);`, table, schema)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		var cols []column
+		for rows.Next() {
+			var c column
+			var pk int
+			if err := rows.Scan(&c.name, &c.typ, &pk); err != nil {
+				return nil, err
+			}
+			c.pk = pk > 0
+			cols = append(cols, c)
+		}
+		return cols, rows.Err()
+	}
+	mainCols, err := read("main")
+	if err != nil {
+		return nil, err
+	}
+	shardCols, err := read("shard")
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(mainCols))
+	for i, c := range mainCols {
+		names[i] = `"` + c.name + `"`
+	}
+	if len(shardCols) < 1 {
+		defs := make([]string, len(mainCols))
+		for i, c := range mainCols {
+			defs[i] = names[i] + " " + c.typ
+			if c.pk {
+				defs[i] += " PRIMARY KEY"
+			}
+		}
+		if _, err := tx.Exec(`CREATE TABLE shard.` + table + ` (` + strings.Join(defs, ",") + `);`); err != nil {
+			return nil, err
+		}
+		if table != "worm" {
+			_, err := tx.Exec(`CREATE INDEX shard.` + table + `_job ON ` + table + ` (job_id);`)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return names, nil
+	}
+	have := make(map[string]bool)
+	for _, c := range shardCols {
+		have[c.name] = true
+	}
+	for i, c := range mainCols {
+		if have[c.name] {
+			continue
+		}
+		if _, err := tx.Exec(`ALTER TABLE shard.` + table + ` ADD COLUMN ` + names[i] + ` ` + c.typ + `;`); err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+// eachShard calls fn with the shards of the months from the one of after
+// to the one of before, newest first, until fn returns false. Zero times
+// don't bound the months.
+func (h *Worm) eachShard(after, before time.Time, fn func(db *sqlx.DB) (bool, error)) error {
+	if len(h.shardDir) < 1 {
+		return nil
+	}
+	names, err := filepath.Glob(filepath.Join(h.shardDir, "worm-[0-9][0-9][0-9][0-9]-[0-9][0-9].db"))
+	if err != nil {
+		return err
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	for _, name := range names {
+		month := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(name), "worm-"), ".db")
+		if !after.IsZero() && month < after.UTC().Format("2006-01") {
+			continue
+		}
+		if !before.IsZero() && month > before.UTC().Format("2006-01") {
+			continue
+		}
+		more, err := readShard(name, fn)
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+	}
+	return nil
+}
+
+// readShard opens the shard read-only for fn.
+func readShard(name string, fn func(db *sqlx.DB) (bool, error)) (bool, error) {
+	db, err := sqlx.Open("sqlite3", "file:"+name+"?mode=ro")
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Printf("readShard : close : err [%s] shard [%s]", err, name)
+		}
+	}()
+	return fn(db)
+}
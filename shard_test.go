@@ -0,0 +1,88 @@
+package worm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMonthShards(t *testing.T) {
+	dir, err := ioutil.TempDir("", "worm-shards")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	start := time.Date(2016, 1, 15, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start), WithMonthShards(dir))
+	defer closeTestWorm(t, h)
+	h.MustRegister("echo", &echoDoer{})
+	h.MustRegister("late", &testDoer{name: "late", status: StatusOK})
+
+	done, err := h.Queue("echo", []byte("january"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err := h.QueueAt("late", nil, time.Date(2016, 2, 2, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start)
+	if err := h.Annotate(done, "checked"); err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing moves during the month.
+	if pass, err := h.Sweep(); err != nil || pass.Sharded != 0 {
+		t.Fatalf("same month : got [%+v] err [%v]", pass, err)
+	}
+	h.Tick(time.Date(2016, 2, 2, 0, 0, 0, 0, time.UTC))
+	pass, err := h.Sweep()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pass.Sharded != 1 {
+		t.Fatalf("expected 1 sharded got [%+v]", pass)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "worm-2016-01.db")); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := h.Jobs(JobFilter{}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != pending {
+		t.Fatalf("main : got [%v]", jobs)
+	}
+	job, err := h.Detail(done)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != StatusOK || job.Attempts != 1 || len(job.Notes) != 1 || job.CreatedAt.IsZero() {
+		t.Fatalf("detail : got [%+v]", job)
+	}
+	if _, err := h.Detail("missing"); err == nil {
+		t.Fatal("expected missing job error")
+	}
+	jobs, err = h.query(time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, 2, 1, 0, 0, 0, 0, time.UTC), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("query : expected 2 jobs got [%v]", jobs)
+	}
+
+	// shards catch up with the migrations of the main database.
+	if _, err := h.Db.Exec(`ALTER TABLE worm ADD COLUMN extra TEXT DEFAULT '';`); err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(time.Date(2016, 2, 3, 0, 0, 0, 0, time.UTC))
+	if pass, err := h.Sweep(); err != nil || pass.Sharded != 1 {
+		t.Fatalf("second : got [%+v] err [%v]", pass, err)
+	}
+	if job, err := h.Detail(pending); err != nil || job.Status != StatusOK {
+		t.Fatalf("second detail : got [%+v] err [%v]", job, err)
+	}
+}
@@ -0,0 +1,53 @@
+package worm
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// StatusTampered is the status of the jobs whose payload failed the
+// signature check. They don't run.
+const StatusTampered = 125
+
+// ErrTampered is the error of the jobs whose payload, worker or ID changed
+// since the hub signed them.
+var ErrTampered = errors.New("worm: payload signature mismatch")
+
+// WithSigningKey signs the payload of every stored job with HMAC-SHA256
+// and key, and checks it before the run: jobs whose ID, worker or payload
+// were modified out of band, e.g. by another service sharing the SQLite
+// file, finish with StatusTampered without running. Jobs stored unsigned,
+// before the key was set, fail the check too. Hubs sharing the database
+// must share the key.
+func WithSigningKey(key []byte) Option {
+	return func(h *Worm) {
+		h.signingKey = key
+	}
+}
+
+// sign returns the signature of the payload of the job, empty without a
+// signing key.
+func (h *Worm) sign(jobID, workerName string, data []byte) string {
+	if len(h.signingKey) < 1 {
+		return ""
+	}
+	mac := hmac.New(sha256.New, h.signingKey)
+	mac.Write([]byte(jobID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(workerName))
+	mac.Write([]byte{0})
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify returns ErrTampered unless signature is the one of the payload of
+// the job.
+func (h *Worm) verify(jobID, workerName string, data []byte, signature string) error {
+	expected := h.sign(jobID, workerName, data)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrTampered
+	}
+	return nil
+}
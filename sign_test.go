@@ -0,0 +1,64 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSigningKey(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start), WithSigningKey([]byte("secret")))
+	defer closeTestWorm(t, h)
+	d := &dataDoer{}
+	h.MustRegister("data", d)
+
+	tampered, err := h.Queue("data", []byte(`{"amount":10}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned, err := h.Queue("data", []byte(`{"amount":20}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Db.Exec(`UPDATE worm SET data='{"amount":1000}' WHERE id=?;`, tampered); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Db.Exec(`UPDATE worm SET signature='' WHERE id=?;`, unsigned); err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start)
+	if d.data != nil {
+		t.Fatalf("tampered jobs ran with [%s]", d.data)
+	}
+	for _, jobID := range []string{tampered, unsigned} {
+		job, err := h.Detail(jobID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status != StatusTampered || job.Error != ErrTampered.Error() {
+			t.Errorf("expected tampered got status [%d] error [%s]", job.Status, job.Error)
+		}
+	}
+
+	jobID, err := h.Queue("data", []byte(`{"amount":30}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start)
+	if string(d.data) != `{"amount":30}` {
+		t.Fatalf("signed : got [%s]", d.data)
+	}
+
+	// updated schedules are signed again.
+	jobID, err = h.Sched("data", []byte(`"hourly"`), "0 0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.UpdateSchedule(jobID, []byte(`"daily"`), "0 0 0 * * *"); err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(24 * time.Hour))
+	if string(d.data) != `"daily"` {
+		t.Fatalf("updated : got [%s]", d.data)
+	}
+}
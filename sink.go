@@ -0,0 +1,84 @@
+package worm
+
+import (
+	"io"
+	"log"
+)
+
+// LogSink receives the log output of the jobs besides the log file, e.g. a
+// database table or a remote collector.
+type LogSink interface {
+	// Open returns the writer of the log of a run of job. It is closed
+	// when the run finishes.
+	Open(job *JobInfo) (io.WriteCloser, error)
+}
+
+// WithLogSink adds sinks receiving the log output of every job. A sink
+// failing to open, write or close is logged and left out of the rest of
+// the run; the job and the other sinks go on.
+func WithLogSink(sinks ...LogSink) Option {
+	return func(h *Worm) {
+		for _, s := range sinks {
+			if s != nil {
+				h.sinks = append(h.sinks, s)
+			}
+		}
+	}
+}
+
+// fanOut writes the log of a run to the log file and the sinks. Only the
+// errors of the log file reach the Doer.
+type fanOut struct {
+	file  io.Writer
+	sinks []io.WriteCloser
+	jobID string
+}
+
+// Write implements io.Writer.
+func (w *fanOut) Write(p []byte) (int, error) {
+	for i, s := range w.sinks {
+		if s == nil {
+			continue
+		}
+		if _, err := s.Write(p); err != nil {
+			log.Printf("fanOut : write sink : err [%s] job id [%s]", err, w.jobID)
+			w.closeSink(i)
+		}
+	}
+	return w.file.Write(p)
+}
+
+// Close closes the sinks.
+func (w *fanOut) Close() error {
+	for i := range w.sinks {
+		w.closeSink(i)
+	}
+	return nil
+}
+
+// closeSink closes the sink i and leaves it out.
+func (w *fanOut) closeSink(i int) {
+	s := w.sinks[i]
+	if s == nil {
+		return
+	}
+	w.sinks[i] = nil
+	if err := s.Close(); err != nil {
+		log.Printf("fanOut : close sink : err [%s] job id [%s]", err, w.jobID)
+	}
+}
+
+// logOutput returns the writer of the log of a run of job, file fanned out
+// to the sinks. It must be closed once the run finished.
+func (h *Worm) logOutput(file io.Writer, job *JobInfo) io.WriteCloser {
+	w := &fanOut{file: file, jobID: job.ID}
+	for _, s := range h.sinks {
+		sw, err := s.Open(job)
+		if err != nil {
+			log.Printf("logOutput : open sink : err [%s] job id [%s]", err, job.ID)
+			continue
+		}
+		w.sinks = append(w.sinks, sw)
+	}
+	return w
+}
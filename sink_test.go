@@ -0,0 +1,97 @@
+package worm
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// memSink keeps the logs of the jobs by job ID.
+type memSink struct {
+	logs   map[string]*bytes.Buffer
+	closed int
+}
+
+func (s *memSink) Open(job *JobInfo) (io.WriteCloser, error) {
+	b := &bytes.Buffer{}
+	s.logs[job.ID] = b
+	return &memWriter{b: b, s: s}, nil
+}
+
+type memWriter struct {
+	b *bytes.Buffer
+	s *memSink
+}
+
+func (w *memWriter) Write(p []byte) (int, error) {
+	return w.b.Write(p)
+}
+
+func (w *memWriter) Close() error {
+	w.s.closed++
+	return nil
+}
+
+// brokenSink fails to write, or to open when noOpen is set.
+type brokenSink struct {
+	noOpen bool
+	writes int
+}
+
+func (s *brokenSink) Open(job *JobInfo) (io.WriteCloser, error) {
+	if s.noOpen {
+		return nil, errors.New("unreachable")
+	}
+	return s, nil
+}
+
+func (s *brokenSink) Write(p []byte) (int, error) {
+	s.writes++
+	return 0, errors.New("connection reset")
+}
+
+func (s *brokenSink) Close() error {
+	return nil
+}
+
+// stepDoer logs a step and fails.
+type stepDoer struct{}
+
+func (d *stepDoer) Name() string {
+	return "step"
+}
+
+func (d *stepDoer) Run(data []byte, w io.Writer) (int, error) {
+	Printf(w, "step 1")
+	return 2, errors.New("boom")
+}
+
+func TestLogSink(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	mem, broken := &memSink{logs: map[string]*bytes.Buffer{}}, &brokenSink{}
+	h := newTestWorm(t, WithManualTick(start), WithLogSink(broken, &brokenSink{noOpen: true}, mem))
+	defer closeTestWorm(t, h)
+	h.MustRegister("step", &stepDoer{})
+
+	jobID, err := h.Queue("step", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start)
+	job, err := h.Detail(jobID)
+	if err != nil || job.Status != 2 || job.Error != "boom" {
+		t.Fatalf("job : got [%v] err [%v]", job, err)
+	}
+	if got := mem.logs[jobID].String(); got != "step 1\nERROR: boom\n" || mem.closed != 1 {
+		t.Fatalf("mem sink : got [%q] closed [%d]", got, mem.closed)
+	}
+	if broken.writes != 1 {
+		t.Fatalf("broken sink : expected left out after 1 write got [%d]", broken.writes)
+	}
+	var b bytes.Buffer
+	if err := h.CopyLog(&b, jobID); err != nil || b.String() != traceHeader(t, h, jobID)+"step 1\nERROR: boom\n" {
+		t.Fatalf("log file : got [%q] err [%v]", b.String(), err)
+	}
+}
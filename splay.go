@@ -0,0 +1,44 @@
+package worm
+
+import (
+	"hash/fnv"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+// splayed is a cron.Schedule firing offset after its schedule.
+type splayed struct {
+	schedule cron.Schedule
+	offset   time.Duration
+}
+
+// Next implements cron.Schedule.
+func (s splayed) Next(t time.Time) time.Time {
+	next := s.schedule.Next(t.Add(-s.offset))
+	if next.IsZero() {
+		return next
+	}
+	return next.Add(s.offset)
+}
+
+// splayOffset returns the delay of this hub recurring jobs, derived from its
+// instance ID so it stays the same across restarts. Zero without WithSplay.
+func (h *Worm) splayOffset() time.Duration {
+	if h.splay < time.Second {
+		return 0
+	}
+	f := fnv.New64a()
+	f.Write([]byte(h.instanceID))
+	seconds := int64(h.splay / time.Second)
+	return time.Duration(f.Sum64()%uint64(seconds)) * time.Second
+}
+
+// splayed delays schedule by the hub splay offset.
+func (h *Worm) splayed(schedule cron.Schedule) cron.Schedule {
+	offset := h.splayOffset()
+	if offset == 0 {
+		return schedule
+	}
+	return splayed{schedule: schedule, offset: offset}
+}
@@ -0,0 +1,43 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSplay(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start), WithInstanceID("eu-1"), WithSplay(5*time.Minute))
+	defer closeTestWorm(t, h)
+
+	offset := h.splayOffset()
+	if offset <= 0 || offset >= 5*time.Minute || offset%time.Second != 0 {
+		t.Fatalf("offset : got [%s]", offset)
+	}
+	same := &Worm{instanceID: "eu-1", splay: 5 * time.Minute}
+	if same.splayOffset() != offset {
+		t.Fatalf("offset : expected same offset for the same instance")
+	}
+	var staggered bool
+	for _, id := range []string{"us-1", "us-2", "ap-1"} {
+		x := &Worm{instanceID: id, splay: 5 * time.Minute}
+		staggered = staggered || x.splayOffset() != offset
+	}
+	if !staggered {
+		t.Fatalf("offset : expected instances staggered")
+	}
+
+	d := &countDoer{}
+	h.MustRegister("count", d)
+	if _, err := h.Sched("count", nil, "0 */10 * * * *"); err != nil {
+		t.Fatal(err)
+	}
+	// the firing at midnight moves to midnight plus offset.
+	fire := start.Add(offset)
+	if n, _ := h.Tick(fire.Add(-time.Second)); n != 0 {
+		t.Fatalf("tick before splay : expected 0 firings got [%d]", n)
+	}
+	if n, _ := h.Tick(fire); n != 1 {
+		t.Fatalf("tick : expected 1 firing got [%d]", n)
+	}
+}
@@ -0,0 +1,138 @@
+package worm
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// HubStats contains the hub statistics.
+type HubStats struct {
+	Workers []WorkerStats `json:"workers"`
+	// Sweep counts the jobs touched by the hub maintenance since start.
+	Sweep SweepStats `json:"sweep"`
+	// Database is the size of the SQLite database.
+	Database DatabaseStats `json:"database"`
+}
+
+// DatabaseStats contains the sizes in bytes of the SQLite database and of
+// its write-ahead log, zero when not in WAL mode or in memory.
+type DatabaseStats struct {
+	Size    int64 `json:"size"`
+	WALSize int64 `json:"wal_size"`
+}
+
+// WorkerStats contains the statistics of one worker.
+type WorkerStats struct {
+	WorkerInfo
+	Pending   int `json:"pending"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	// OldestPending is the age of the oldest single-execution job not
+	// finished yet, zero without any.
+	OldestPending time.Duration `json:"oldest_pending"`
+}
+
+// Stats returns job counts and health of the registered workers.
+func (h *Worm) Stats() (*HubStats, error) {
+	var rows []struct {
+		Worker   string `db:"worker_name"`
+		Status   int    `db:"status"`
+		Finished bool   `db:"finished"`
+		Count    int    `db:"count"`
+	}
+	var ages []struct {
+		Worker string  `db:"worker_name"`
+		Days   float64 `db:"days"`
+	}
+	err := h.readDB(func(db *sqlx.DB) error {
+		err := db.Select(&rows, `
+			SELECT worker_name, status, finished_at IS NOT NULL AS "finished", COUNT(*) AS "count"
+			FROM worm WHERE deleted_at IS NULL GROUP BY worker_name, status, finished;
+		`)
+		if err != nil {
+			return err
+		}
+		return db.Select(&ages, `
+			SELECT worker_name, MAX(julianday(?)-julianday(created_at)) AS "days"
+			FROM worm WHERE cron='' AND finished_at IS NULL AND deleted_at IS NULL
+			GROUP BY worker_name;
+		`, h.now())
+	})
+	if err != nil {
+		log.Printf("Stats : count : err [%s]", err)
+		return nil, err
+	}
+	// the sizes are the ones of the primary.
+	o := <-h.waitc
+	db, err := h.databaseStats()
+	h.waitc <- o
+	if err != nil {
+		log.Printf("Stats : database : err [%s]", err)
+		return nil, err
+	}
+
+	workers := h.Workers()
+	st := &HubStats{
+		Workers:  make([]WorkerStats, len(workers)),
+		Sweep:    h.sweeps.stats(),
+		Database: db,
+	}
+	index := make(map[string]*WorkerStats)
+	for i := range workers {
+		st.Workers[i].WorkerInfo = workers[i]
+		index[workers[i].Name] = &st.Workers[i]
+	}
+	for _, r := range rows {
+		ws, ok := index[r.Worker]
+		if !ok {
+			continue
+		}
+		// jobs finish with any status, waiting ones count as pending.
+		switch {
+		case r.Status == StatusWaiting || !r.Finished:
+			ws.Pending += r.Count
+		case r.Status == StatusOK:
+			ws.Succeeded += r.Count
+		default:
+			ws.Failed += r.Count
+		}
+	}
+	for _, a := range ages {
+		if ws, ok := index[a.Worker]; ok && a.Days > 0 {
+			ws.OldestPending = time.Duration(a.Days * 24 * float64(time.Hour))
+		}
+	}
+	return st, nil
+}
+
+// databaseStats returns the database and write-ahead log sizes. Must be
+// called holding waitc.
+func (h *Worm) databaseStats() (DatabaseStats, error) {
+	var st DatabaseStats
+	var pages, pageSize int64
+	if err := h.dbGet(&pages, `PRAGMA page_count;`); err != nil {
+		return st, err
+	}
+	if err := h.dbGet(&pageSize, `PRAGMA page_size;`); err != nil {
+		return st, err
+	}
+	st.Size = pages * pageSize
+	var file string
+	if err := h.dbGet(&file, `SELECT file FROM pragma_database_list WHERE name='main';`); err != nil {
+		return st, err
+	}
+	if len(file) > 0 {
+		if fi, err := os.Stat(file + "-wal"); err == nil {
+			st.WALSize = fi.Size()
+		}
+	}
+	return st, nil
+}
+
+// Stats _
+func Stats() (*HubStats, error) {
+	return defaultWorm.Stats()
+}
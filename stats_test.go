@@ -0,0 +1,53 @@
+package worm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStatsGauges(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	if _, err := h.Db.Exec(`PRAGMA journal_mode=WAL;`); err != nil {
+		t.Fatal(err)
+	}
+	h.MustRegister("slow", &testDoer{name: "slow", status: StatusOK})
+	h.MustRegister("idle", &testDoer{name: "idle", status: StatusOK})
+	// doers may fail with status 1, like StatusStart.
+	h.MustRegister("failing", &testDoer{name: "failing", status: 1, err: errors.New("boom")})
+	if err := h.PauseWorker("slow"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Queue("slow", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Queue("idle", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Queue("failing", nil); err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(90 * time.Minute))
+
+	st, err := h.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ws := range st.Workers {
+		var expected time.Duration
+		if ws.Name == "slow" {
+			expected = 90 * time.Minute
+		}
+		if d := ws.OldestPending - expected; d < -time.Second || d > time.Second {
+			t.Errorf("%s : expected oldest pending [%s] got [%s]", ws.Name, expected, ws.OldestPending)
+		}
+		if ws.Name == "failing" && (ws.Pending != 0 || ws.Failed != 1) {
+			t.Errorf("%s : expected 1 failed got [%+v]", ws.Name, ws)
+		}
+	}
+	if st.Database.Size < 1 || st.Database.WALSize < 1 {
+		t.Errorf("expected database size got [%+v]", st.Database)
+	}
+}
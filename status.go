@@ -0,0 +1,198 @@
+package worm
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// StatusUpdate is a manual status change of a job, see UpdateStatuses.
+type StatusUpdate struct {
+	JobID  string `json:"id"`
+	Status int    `json:"status"`
+	// Reason says who changed the status and why.
+	Reason string `json:"reason"`
+}
+
+// UpdateStatuses overrides the status of the jobs like SetStatus in a single
+// transaction, so bulk operations on hundreds of jobs hold the database
+// once. Unknown jobs are skipped. Returns the number of updated jobs, none
+// is updated on error. StatusWaiting is rejected with ErrConflict.
+func (h *Worm) UpdateStatuses(updates []StatusUpdate) (int, error) {
+	updated, err := h.updateStatuses(updates)
+	if err != nil {
+		log.Printf("UpdateStatuses : err [%s]", err)
+		return 0, err
+	}
+	var n int
+	for i, u := range updated {
+		if !u {
+			continue
+		}
+		n++
+		if updates[i].Status != StatusStart {
+			h.finish(updates[i].JobID, updates[i].Status)
+		}
+	}
+	log.Printf("UpdateStatuses : updated [%d] of [%d]", n, len(updates))
+	return n, nil
+}
+
+// updateStatuses applies the updates and records them in the job audit.
+// Returns which of them found their job.
+func (h *Worm) updateStatuses(updates []StatusUpdate) ([]bool, error) {
+	for _, u := range updates {
+		if u.Status == StatusWaiting {
+			return nil, ErrConflict
+		}
+	}
+	updated := make([]bool, len(updates))
+	if len(updates) < 1 {
+		return updated, nil
+	}
+	now := h.now()
+	o := <-h.waitc
+	defer func() {
+		h.waitc <- o
+	}()
+	err := retryBusy(func() error {
+		tx, err := h.Db.Beginx()
+		if err != nil {
+			return err
+		}
+		update, err := tx.Prepare(`
+			UPDATE worm
+			SET status=?,finished_at=?,updated_at=?,claimed_by='',claimed_until=NULL
+			WHERE id=? AND deleted_at IS NULL;
+		`)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		defer update.Close()
+		audit, err := tx.Prepare(`
+			INSERT INTO worm_audit (job_id,status,reason,created_at) VALUES (?,?,?,?);
+		`)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		defer audit.Close()
+		for i, u := range updates {
+			var finishedAt interface{} = now
+			if u.Status == StatusStart {
+				finishedAt = nil
+			}
+			res, err := update.Exec(u.Status, finishedAt, now, u.JobID)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+			n, err := res.RowsAffected()
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+			if updated[i] = n > 0; !updated[i] {
+				continue
+			}
+			if _, err := audit.Exec(u.JobID, u.Status, u.Reason, now); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+		return tx.Commit()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// runResult is the status update that acks a finished run, see ackRun.
+type runResult struct {
+	jobID   string
+	status  int
+	errMsg  string
+	logFile string
+	logSize int64
+	// acked is set when the update found the job claimed by this hub.
+	acked bool
+	err   error
+	done  chan struct{}
+}
+
+// ackBatch holds the run results waiting for the database.
+type ackBatch struct {
+	sync.Mutex
+	pending []*runResult
+}
+
+// ackRun stores the result of a finished run. Results of runs finishing while
+// another goroutine holds the database are written together by the next
+// one to get it, in a single transaction, so busy hubs don't serialize one
+// update per job. Returns if the update acked the job.
+func (h *Worm) ackRun(r *runResult) (bool, error) {
+	r.done = make(chan struct{})
+	h.acks.Lock()
+	h.acks.pending = append(h.acks.pending, r)
+	h.acks.Unlock()
+	o := <-h.waitc
+	h.acks.Lock()
+	batch := h.acks.pending
+	h.acks.pending = nil
+	h.acks.Unlock()
+	if len(batch) > 0 {
+		h.writeAcks(batch, h.now())
+	}
+	h.waitc <- o
+	<-r.done
+	return r.acked, r.err
+}
+
+// writeAcks writes the run results at now and signals them. Must hold waitc.
+func (h *Worm) writeAcks(batch []*runResult, now time.Time) {
+	err := retryBusy(func() error {
+		tx, err := h.Db.Beginx()
+		if err != nil {
+			return err
+		}
+		// the update is ignored if the claim was lost and the job was
+		// delivered again or a single execution already finished.
+		stmt, err := tx.Prepare(`
+			UPDATE worm
+			SET status=?,error=?,log_file=?,log_size=?,finished_at=?,updated_at=?,claimed_by='',claimed_until=NULL
+			WHERE id=? AND claimed_by=? AND (cron<>'' OR finished_at IS NULL);
+		`)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		defer stmt.Close()
+		for _, r := range batch {
+			res, err := stmt.Exec(r.status, r.errMsg, r.logFile, r.logSize, now, now, r.jobID, h.instanceID)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+			n, err := res.RowsAffected()
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+			r.acked = n > 0
+		}
+		return tx.Commit()
+	})
+	for _, r := range batch {
+		if err != nil {
+			r.acked, r.err = false, err
+		}
+		close(r.done)
+	}
+}
+
+// UpdateStatuses _
+func UpdateStatuses(updates []StatusUpdate) (int, error) {
+	return defaultWorm.UpdateStatuses(updates)
+}
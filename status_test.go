@@ -0,0 +1,125 @@
+package worm
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUpdateStatuses(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	h.MustRegister("ok", &testDoer{name: "ok"})
+	notify := &dataDoer{}
+	h.MustRegister("notify", notify)
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		jobID, err := h.Queue("ok", nil, OnFailure("notify", []byte(fmt.Sprintf("failed %d", i))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, jobID)
+	}
+	n, err := h.UpdateStatuses([]StatusUpdate{
+		{JobID: ids[0], Status: StatusOK, Reason: "ana: bulk resolve"},
+		{JobID: ids[1], Status: 5, Reason: "ana: bulk resolve"},
+		{JobID: "none", Status: StatusOK, Reason: "ana: bulk resolve"},
+		{JobID: ids[2], Status: StatusStart, Reason: "ana: bulk resolve"},
+	})
+	if err != nil || n != 3 {
+		t.Fatalf("expected 3 updates got [%d] err [%v]", n, err)
+	}
+	for i, status := range []int{StatusOK, 5, StatusStart} {
+		job, err := h.Detail(ids[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status != status || len(job.Audit) != 1 || job.Audit[0].Reason != "ana: bulk resolve" {
+			t.Fatalf("job %d : got [%+v]", i, job)
+		}
+	}
+	// completion hooks run for the finished jobs only.
+	h.Tick(start.Add(time.Minute))
+	if string(notify.data) != "failed 1" {
+		t.Fatalf("continuation : got [%s]", notify.data)
+	}
+
+	// nothing is updated when an update is not allowed.
+	_, err = h.UpdateStatuses([]StatusUpdate{
+		{JobID: ids[0], Status: 2},
+		{JobID: ids[1], Status: StatusWaiting},
+	})
+	if err != ErrConflict {
+		t.Fatalf("waiting : expected ErrConflict got [%v]", err)
+	}
+	if job, _ := h.Detail(ids[0]); job.Status != StatusOK {
+		t.Fatalf("conflict : got status [%d]", job.Status)
+	}
+}
+
+func TestAckBatch(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	h.MustRegister("ok", &testDoer{name: "ok"})
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		jobID, err := h.Queue("ok", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := h.claim(jobID); err != nil || !ok {
+			t.Fatalf("claim : got [%v] err [%v]", ok, err)
+		}
+		ids = append(ids, jobID)
+	}
+	// a stale result of a job this hub no longer holds.
+	h.release(ids[4])
+
+	// the runs finish while the database is busy.
+	o := <-h.waitc
+	acked := make([]bool, len(ids))
+	var wg sync.WaitGroup
+	for i, jobID := range ids {
+		wg.Add(1)
+		go func(i int, jobID string) {
+			defer wg.Done()
+			var err error
+			acked[i], err = h.ackRun(&runResult{jobID: jobID, status: i, errMsg: "e", logFile: "l", logSize: 1})
+			if err != nil {
+				t.Error(err)
+			}
+		}(i, jobID)
+	}
+	for {
+		h.acks.Lock()
+		n := len(h.acks.pending)
+		h.acks.Unlock()
+		if n == len(ids) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	h.waitc <- o
+	wg.Wait()
+
+	for i, jobID := range ids {
+		if acked[i] != (i < 4) {
+			t.Fatalf("job %d : got acked [%v]", i, acked[i])
+		}
+		job, err := h.Detail(jobID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i < 4 && (job.Status != i || job.Error != "e" || job.LogFile != "l") {
+			t.Fatalf("job %d : got [%+v]", i, job)
+		}
+	}
+	if h.acks.pending != nil {
+		t.Fatalf("expected no pending results got [%d]", len(h.acks.pending))
+	}
+}
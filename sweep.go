@@ -0,0 +1,169 @@
+package worm
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// SweepStats counts the jobs touched by the hub maintenance since start.
+type SweepStats struct {
+	// Sweeps is the number of sweeps, LastSweep the time of the last one.
+	Sweeps    int       `json:"sweeps"`
+	LastSweep time.Time `json:"last_sweep,omitempty"`
+	// Expired jobs finished with StatusExpired.
+	Expired int `json:"expired"`
+	// Stale jobs had their lease expire while unfinished, the claimer
+	// stopped renewing it without acking.
+	Stale int `json:"stale"`
+	// Recovered jobs were freed from hubs that stopped heartbeating.
+	Recovered int `json:"recovered"`
+	// Logs is the number of job logs removed by the log retention.
+	Logs int `json:"logs"`
+	// Sharded is the number of jobs moved to the month shards.
+	Sharded int `json:"sharded"`
+}
+
+// sweepCounters holds the SweepStats of the hub.
+type sweepCounters struct {
+	mu sync.Mutex
+	SweepStats
+}
+
+// add runs fn on the counters.
+func (c *sweepCounters) add(fn func(s *SweepStats)) {
+	c.mu.Lock()
+	fn(&c.SweepStats)
+	c.mu.Unlock()
+}
+
+// stats returns a copy of the counters.
+func (c *sweepCounters) stats() SweepStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.SweepStats
+}
+
+// Sweep runs one maintenance pass: it expires the jobs whose TTL passed,
+// frees the stale ones, closing their open runs with an error, removes the
+// logs past the log retention and moves the finished jobs of past months to
+// the month shards. Returns the jobs touched by this pass.
+// The hub sweeps every sweep interval.
+func (h *Worm) Sweep() (SweepStats, error) {
+	var pass SweepStats
+	now := h.now()
+	var expired []string
+	o := <-h.waitc
+	err := h.dbSelect(&expired, `
+		SELECT id FROM worm
+		WHERE cron='' AND finished_at IS NULL AND expires_at<=?
+		AND (claimed_until IS NULL OR claimed_until<?);
+	`, now, now)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("Sweep : select expired : err [%s]", err)
+		return pass, err
+	}
+	for _, jobID := range expired {
+		if h.expireJob(jobID) {
+			pass.Expired++
+		}
+	}
+
+	stale, err := h.freeStale(now)
+	if err != nil {
+		log.Printf("Sweep : free stale : err [%s]", err)
+		return pass, err
+	}
+	pass.Stale = stale
+
+	logs, err := h.expireLogs(now)
+	if err != nil {
+		log.Printf("Sweep : expire logs : err [%s]", err)
+		return pass, err
+	}
+	pass.Logs = logs
+
+	sharded, err := h.shardMonths(now)
+	if err != nil {
+		log.Printf("Sweep : shard months : err [%s]", err)
+		return pass, err
+	}
+	pass.Sharded = sharded
+	if pass.Expired+pass.Stale+pass.Logs+pass.Sharded > 0 {
+		log.Printf("Sweep : expired [%d] stale [%d] logs [%d] sharded [%d]", pass.Expired, pass.Stale, pass.Logs, pass.Sharded)
+	}
+	h.sweeps.add(func(s *SweepStats) {
+		s.Sweeps++
+		s.LastSweep = now
+		s.Stale += stale
+		s.Logs += logs
+		s.Sharded += sharded
+	})
+	pass.Sweeps, pass.LastSweep = 1, now
+	return pass, nil
+}
+
+// freeStale releases the unfinished jobs whose lease expired at now and
+// closes their open runs. Returns the freed job count.
+func (h *Worm) freeStale(now time.Time) (int, error) {
+	o := <-h.waitc
+	defer func() {
+		h.waitc <- o
+	}()
+	tx, err := h.Db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	err = h.loseInterrupted(tx, now, "lease expired", `claimed_by<>'' AND claimed_until<?`, now)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	_, err = tx.Exec(`
+		UPDATE worm_run SET error='stale: lease expired',finished_at=?
+		WHERE finished_at IS NULL AND job_id IN (
+			SELECT id FROM worm
+			WHERE claimed_by<>'' AND claimed_until<? AND (cron<>'' OR finished_at IS NULL)
+		);
+	`, now, now)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	res, err := tx.Exec(`
+		UPDATE worm SET claimed_by='',claimed_until=NULL
+		WHERE claimed_by<>'' AND claimed_until<? AND (cron<>'' OR finished_at IS NULL);
+	`, now)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	return int(n), tx.Commit()
+}
+
+// sweep runs Sweep every sweep interval until Close.
+func (h *Worm) sweep() {
+	ticker := time.NewTicker(h.sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.quitc:
+			return
+		case <-ticker.C:
+		}
+		if _, err := h.Sweep(); err != nil {
+			log.Printf("sweep : err [%s]", err)
+		}
+	}
+}
+
+// Sweep _
+func Sweep() (SweepStats, error) {
+	return defaultWorm.Sweep()
+}
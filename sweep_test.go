@@ -0,0 +1,62 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSweep(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	h.MustRegister("count", &countDoer{})
+
+	otp, err := h.Queue("count", nil, TTL(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stuck, err := h.Queue("count", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a hub claimed the job and hung without renewing the lease.
+	if _, err := h.Db.Exec(`
+		UPDATE worm SET claimed_by='hung',claimed_until=? WHERE id=?;
+	`, start.Add(30*time.Second), stuck); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Db.Exec(`
+		INSERT INTO worm_run (job_id,instance_id,status,started_at) VALUES (?,'hung',?,?);
+	`, stuck, StatusStart, start); err != nil {
+		t.Fatal(err)
+	}
+
+	h.Pause()
+	h.Tick(start.Add(2 * time.Minute))
+	pass, err := h.Sweep()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pass.Expired != 1 || pass.Stale != 1 {
+		t.Fatalf("sweep : got [%+v]", pass)
+	}
+	job, err := h.Detail(otp)
+	if err != nil || job.Status != StatusExpired {
+		t.Fatalf("detail : expected expired got [%+v] err [%v]", job, err)
+	}
+	runs, err := h.Runs(stuck)
+	if err != nil || len(runs) != 1 || runs[0].Error != "stale: lease expired" {
+		t.Fatalf("runs : got [%+v] err [%v]", runs, err)
+	}
+	if pass, _ := h.Sweep(); pass.Expired+pass.Stale != 0 {
+		t.Fatalf("second sweep : got [%+v]", pass)
+	}
+
+	st, err := h.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Sweep.Sweeps != 2 || st.Sweep.Expired != 1 || st.Sweep.Stale != 1 {
+		t.Fatalf("stats : got [%+v]", st.Sweep)
+	}
+}
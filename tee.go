@@ -0,0 +1,74 @@
+package worm
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// WithLogTee mirrors the log output of every job to w, e.g. os.Stdout on
+// container platforms collecting the process output. Lines are prefixed with
+// the worker and the job ID. Write errors on w don't fail the jobs.
+func WithLogTee(w io.Writer) Option {
+	return func(h *Worm) {
+		if w != nil {
+			h.sinks = append(h.sinks, &teeSink{w: w})
+		}
+	}
+}
+
+// teeSink is the LogSink of WithLogTee. It serializes the writes of the
+// running jobs to w.
+type teeSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// Open implements LogSink.
+func (s *teeSink) Open(job *JobInfo) (io.WriteCloser, error) {
+	return &lineWriter{tee: s, prefix: []byte(job.Worker + " " + job.ID + " | ")}, nil
+}
+
+// lineWriter writes the whole lines of a job, with prefix, to the shared
+// tee so the lines of concurrent jobs don't mix.
+type lineWriter struct {
+	tee    *teeSink
+	prefix []byte
+	// partial is the last line, not ended yet.
+	partial []byte
+}
+
+// Write implements io.Writer. It never fails.
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	i := bytes.LastIndexByte(w.partial, '\n')
+	if i < 0 {
+		return len(p), nil
+	}
+	w.write(w.partial[:i+1])
+	w.partial = append(w.partial[:0], w.partial[i+1:]...)
+	return len(p), nil
+}
+
+// Close implements io.Closer. It ends and writes the partial line.
+func (w *lineWriter) Close() error {
+	if len(w.partial) > 0 {
+		w.write(append(w.partial, '\n'))
+		w.partial = w.partial[:0]
+	}
+	return nil
+}
+
+// write writes the lines with prefix.
+func (w *lineWriter) write(lines []byte) {
+	var b bytes.Buffer
+	for _, line := range bytes.SplitAfter(lines, []byte{'\n'}) {
+		if len(line) > 0 {
+			b.Write(w.prefix)
+			b.Write(line)
+		}
+	}
+	w.tee.mu.Lock()
+	w.tee.w.Write(b.Bytes())
+	w.tee.mu.Unlock()
+}
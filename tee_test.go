@@ -0,0 +1,54 @@
+package worm
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLogTee(t *testing.T) {
+	var b bytes.Buffer
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start), WithLogTee(&b))
+	defer closeTestWorm(t, h)
+	h.MustRegister("print", &printDoer{n: 3})
+	h.MustRegister("fail", &testDoer{name: "fail", status: 2, err: errors.New("boom")})
+
+	printID, err := h.Queue("print", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start)
+	failID, err := h.Queue("fail", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start)
+
+	expect := "print " + printID + " | xxx\n" + "fail " + failID + " | ERROR: boom\n"
+	if b.String() != expect {
+		t.Fatalf("tee : expected [%q] got [%q]", expect, b.String())
+	}
+	var log bytes.Buffer
+	if err := h.CopyLog(&log, printID); err != nil || log.String() != traceHeader(t, h, printID)+"xxx" {
+		t.Fatalf("log file : got [%s] err [%v]", log.String(), err)
+	}
+}
+
+func TestLineWriter(t *testing.T) {
+	var b bytes.Buffer
+	w := &lineWriter{tee: &teeSink{w: &b}, prefix: []byte("> ")}
+	for _, s := range []string{"a\nb", "c\n", "\nd\ne"} {
+		if n, err := w.Write([]byte(s)); err != nil || n != len(s) {
+			t.Fatalf("write [%q] : got [%d] err [%v]", s, n, err)
+		}
+	}
+	if expect := "> a\n> bc\n> \n> d\n"; b.String() != expect {
+		t.Fatalf("expected [%q] got [%q]", expect, b.String())
+	}
+	w.Close()
+	if expect := "> a\n> bc\n> \n> d\n> e\n"; b.String() != expect {
+		t.Fatalf("expected [%q] got [%q]", expect, b.String())
+	}
+}
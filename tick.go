@@ -0,0 +1,182 @@
+package worm
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+// scheduler fires jobs on their schedules. Implemented by *cron.Cron and by
+// manualScheduler.
+type scheduler interface {
+	Schedule(schedule cron.Schedule, job cron.Job)
+	Start()
+	Stop()
+}
+
+// manualScheduler fires jobs only when ticked.
+type manualScheduler struct {
+	mu      sync.Mutex
+	now     time.Time
+	entries []*manualEntry
+}
+
+// manualEntry is a job and its next firing.
+type manualEntry struct {
+	schedule cron.Schedule
+	job      cron.Job
+	next     time.Time
+}
+
+// Schedule implements scheduler.
+func (m *manualScheduler) Schedule(schedule cron.Schedule, job cron.Job) {
+	m.mu.Lock()
+	m.entries = append(m.entries, &manualEntry{
+		schedule: schedule,
+		job:      job,
+		next:     schedule.Next(m.now),
+	})
+	m.mu.Unlock()
+}
+
+// Start implements scheduler.
+func (m *manualScheduler) Start() {}
+
+// Stop implements scheduler.
+func (m *manualScheduler) Stop() {}
+
+// time returns the time of the last tick.
+func (m *manualScheduler) time() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+// tick advances the clock to t and runs every firing due until t, in time
+// order, on the calling goroutine. Jobs scheduled by the fired jobs are run
+// too if due. Returns the number of firings.
+func (m *manualScheduler) tick(t time.Time) int {
+	var n int
+	for {
+		m.mu.Lock()
+		var due *manualEntry
+		for _, e := range m.entries {
+			if e.next.IsZero() || e.next.After(t) {
+				continue
+			}
+			if due == nil || e.next.Before(due.next) {
+				due = e
+			}
+		}
+		if due == nil {
+			if t.After(m.now) {
+				m.now = t
+			}
+			m.mu.Unlock()
+			return n
+		}
+		// replaced schedules are dropped.
+		if e, ok := due.schedule.(*cronEntry); ok && e.retired() {
+			m.remove(due)
+			m.mu.Unlock()
+			continue
+		}
+		m.now = due.next
+		due.next = due.schedule.Next(due.next)
+		m.mu.Unlock()
+
+		due.job.Run()
+		n++
+	}
+}
+
+// remove drops the entry. Must be called holding mu.
+func (m *manualScheduler) remove(entry *manualEntry) {
+	for i, e := range m.entries {
+		if e == entry {
+			m.entries = append(m.entries[:i], m.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// Tick fires every job due at t, including missed firings, and returns how
+// many ran. The jobs run on the calling goroutine in time order so when Tick
+// returns their status is stored. Only for hubs created WithManualTick.
+func (h *Worm) Tick(t time.Time) (int, error) {
+	m, ok := h.croner.(*manualScheduler)
+	if !ok {
+		return 0, errors.New("worm: hub not in manual tick mode")
+	}
+	t = t.UTC()
+	var n int
+	// jobs that fail to run stay due, each one is tried once per tick.
+	tried := make(map[string]bool)
+	for {
+		job, err := h.nextDue(t, tried)
+		if err != nil {
+			return n, err
+		}
+		if job == nil {
+			k := m.tick(t)
+			n += k
+			if k == 0 {
+				return n, nil
+			}
+			// fired jobs may have queued new ones.
+			continue
+		}
+		// recurring jobs due before the single execution fire first.
+		if k := m.tick(*job.RunAt); k > 0 {
+			n += k
+			continue
+		}
+		tried[job.ID] = true
+		h.RLock()
+		wk, ok := h.workers[job.Worker]
+		h.RUnlock()
+		if ok && !h.Paused() {
+			h.run(wk.doer, job.ID, job.Data)
+			n++
+		}
+	}
+}
+
+// nextDue returns the first single execution job due at t not tried yet,
+// nil if none.
+func (h *Worm) nextDue(t time.Time, tried map[string]bool) (*dueJob, error) {
+	jobs, err := h.due(t, len(tried)+1)
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		if tried[job.ID] {
+			continue
+		}
+		if job.RunAt == nil {
+			now := h.now()
+			job.RunAt = &now
+		}
+		return job, nil
+	}
+	return nil, nil
+}
+
+// manual reports if the hub is in manual tick mode.
+func (h *Worm) manual() bool {
+	_, ok := h.croner.(*manualScheduler)
+	return ok
+}
+
+// now returns the hub time in UTC.
+func (h *Worm) now() time.Time {
+	if m, ok := h.croner.(*manualScheduler); ok {
+		return m.time()
+	}
+	if h.clock != nil {
+		return h.clock().UTC()
+	}
+	return time.Now().UTC()
+}
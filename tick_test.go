@@ -0,0 +1,83 @@
+package worm
+
+import (
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countDoer counts its runs.
+type countDoer struct {
+	runs int32
+}
+
+func (d *countDoer) Name() string {
+	return "count"
+}
+
+func (d *countDoer) Run(data []byte, w io.Writer) (int, error) {
+	atomic.AddInt32(&d.runs, 1)
+	return StatusOK, nil
+}
+
+func TestTick(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+
+	d := &countDoer{}
+	h.MustRegister("count", d)
+	jobID, err := h.Queue("count", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Sched("count", nil, "0 */10 * * * *"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the queued job is due at once.
+	if n, err := h.Tick(start); err != nil || n != 1 {
+		t.Fatalf("tick start : got [%d] err [%v]", n, err)
+	}
+	// schedule at 10, 20 and 30 minutes.
+	if n, _ := h.Tick(start.Add(30 * time.Minute)); n != 3 {
+		t.Fatalf("tick : expected 3 firings got [%d]", n)
+	}
+	if d.runs != 4 {
+		t.Fatalf("runs : expected 4 got [%d]", d.runs)
+	}
+	job, err := h.Detail(jobID)
+	if err != nil || job.Status != StatusOK {
+		t.Fatalf("detail : got [%+v] err [%v]", job, err)
+	}
+	if !h.now().Equal(start.Add(30 * time.Minute)) {
+		t.Fatalf("now : got [%s]", h.now())
+	}
+}
+
+func TestQueueAt(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+
+	d := &countDoer{}
+	h.MustRegister("count", d)
+	jobID, err := h.QueueAt("count", nil, start.Add(10*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := h.Tick(start.Add(5 * time.Minute)); n != 0 {
+		t.Fatalf("tick +5m : expected 0 runs got [%d]", n)
+	}
+	if n, _ := h.Tick(start.Add(10 * time.Minute)); n != 1 {
+		t.Fatalf("tick +10m : expected 1 run got [%d]", n)
+	}
+	job, err := h.Detail(jobID)
+	if err != nil || job.Status != StatusOK {
+		t.Fatalf("detail : got [%+v] err [%v]", job, err)
+	}
+	if n, _ := h.Tick(start.Add(time.Hour)); n != 0 {
+		t.Fatalf("tick +1h : expected 0 runs got [%d]", n)
+	}
+}
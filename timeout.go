@@ -0,0 +1,28 @@
+package worm
+
+import (
+	"context"
+	"time"
+)
+
+// Timeout bounds the runs of the worker to d: the context a ContextDoer
+// receives is done after d. Doers without context are not interrupted.
+// Zero runs the worker without timeout, overriding WithDefaultTimeout.
+func Timeout(d time.Duration) WorkerOption {
+	return func(w *worker) error {
+		if d <= 0 {
+			d = -1
+		}
+		w.timeout = d
+		return nil
+	}
+}
+
+// withTimeout bounds ctx to the timeout of the worker running the Doer named
+// name. The returned cancel must be called.
+func (h *Worm) withTimeout(ctx context.Context, name string) (context.Context, context.CancelFunc) {
+	if wk, ok := h.workerOf(name); ok && wk.timeout > 0 {
+		return context.WithTimeout(ctx, wk.timeout)
+	}
+	return context.WithCancel(ctx)
+}
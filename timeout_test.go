@@ -0,0 +1,63 @@
+package worm
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+// deadlineDoer waits for its context to be done, if it has a deadline.
+type deadlineDoer struct {
+	name     string
+	deadline bool
+}
+
+func (d *deadlineDoer) Name() string {
+	return d.name
+}
+
+func (d *deadlineDoer) Run(data []byte, w io.Writer) (int, error) {
+	return d.RunContext(context.Background(), data, w)
+}
+
+func (d *deadlineDoer) RunContext(ctx context.Context, data []byte, w io.Writer) (int, error) {
+	if _, d.deadline = ctx.Deadline(); !d.deadline {
+		return StatusOK, nil
+	}
+	<-ctx.Done()
+	return 2, ctx.Err()
+}
+
+func TestTimeout(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	bounded, free := &deadlineDoer{name: "bounded"}, &deadlineDoer{name: "free"}
+	h.MustRegister("bounded", bounded, Timeout(10*time.Millisecond))
+	h.MustRegister("free", free)
+
+	boundedID, err := h.Queue("bounded", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	freeID, err := h.Queue("free", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(time.Minute))
+	job, err := h.Detail(boundedID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bounded.deadline || job.Status != 2 || job.Error != context.DeadlineExceeded.Error() {
+		t.Fatalf("bounded : got [%+v]", job)
+	}
+	job, err = h.Detail(freeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if free.deadline || job.Status != StatusOK {
+		t.Fatalf("free : got [%+v]", job)
+	}
+}
@@ -0,0 +1,47 @@
+package worm
+
+import "log"
+
+// DisableSchedule mutes the recurring job: its firings are skipped, on every
+// hub sharing the database, until EnableSchedule. The flag is kept in the
+// database.
+func (h *Worm) DisableSchedule(jobID string) error {
+	return h.setDisabled(jobID, h.now())
+}
+
+// EnableSchedule fires the recurring job again after DisableSchedule.
+func (h *Worm) EnableSchedule(jobID string) error {
+	return h.setDisabled(jobID, nil)
+}
+
+// setDisabled sets disabled_at of the recurring job.
+func (h *Worm) setDisabled(jobID string, disabledAt interface{}) error {
+	o := <-h.waitc
+	res, err := h.dbExec(`
+		UPDATE worm SET disabled_at=?,updated_at=?
+		WHERE id=? AND cron<>'' AND deleted_at IS NULL;
+	`, disabledAt, h.now(), jobID)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("setDisabled : err [%s] job id [%s]", err, jobID)
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DisableSchedule _
+func DisableSchedule(jobID string) error {
+	return defaultWorm.DisableSchedule(jobID)
+}
+
+// EnableSchedule _
+func EnableSchedule(jobID string) error {
+	return defaultWorm.EnableSchedule(jobID)
+}
@@ -0,0 +1,44 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDisableSchedule(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+
+	d := &countDoer{}
+	h.MustRegister("count", d)
+	jobID, err := h.Sched("count", nil, "0 */10 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.DisableSchedule(jobID); err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(30 * time.Minute))
+	if d.runs != 0 {
+		t.Fatalf("disabled : expected 0 runs got [%d]", d.runs)
+	}
+	if err := h.EnableSchedule(jobID); err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(40 * time.Minute))
+	if d.runs != 1 {
+		t.Fatalf("enabled : expected 1 run got [%d]", d.runs)
+	}
+
+	single, err := h.Queue("count", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.DisableSchedule(single); err != ErrNotFound {
+		t.Fatalf("single execution : expected ErrNotFound got [%v]", err)
+	}
+	if err := h.EnableSchedule("none"); err != ErrNotFound {
+		t.Fatalf("unknown : expected ErrNotFound got [%v]", err)
+	}
+}
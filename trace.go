@@ -0,0 +1,45 @@
+package worm
+
+import (
+	"context"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// traceKey is the context key of the trace ID of a request.
+type traceKey struct{}
+
+// ContextWithTrace returns a copy of ctx carrying the trace ID of the
+// originating request, e.g. its X-Request-ID, for Trace.
+func ContextWithTrace(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceKey{}, traceID)
+}
+
+// TraceFrom returns the trace ID carried by ctx: the one set with
+// ContextWithTrace or, in the context a ContextDoer receives, the one of the
+// running job. Empty otherwise.
+func TraceFrom(ctx context.Context) string {
+	if traceID, ok := ctx.Value(traceKey{}).(string); ok && len(traceID) > 0 {
+		return traceID
+	}
+	if info, ok := FromContext(ctx); ok {
+		return info.TraceID
+	}
+	return ""
+}
+
+// Trace stores with the job the trace ID carried by ctx, so the job can be
+// traced back to the request that queued it. Jobs queued with ChildOf
+// inherit the trace ID of their parent, the others get a new one.
+func Trace(ctx context.Context) JobOption {
+	return func(o *jobOptions) {
+		if traceID := TraceFrom(ctx); len(traceID) > 0 {
+			o.traceID = traceID
+		}
+	}
+}
+
+// newTraceID returns the trace ID of a job queued without one.
+func newTraceID() string {
+	return uuid.NewV4().String()
+}
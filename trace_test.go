@@ -0,0 +1,96 @@
+package worm
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+// traceHeader returns the log header of the job.
+func traceHeader(t *testing.T, h *Worm, jobID string) string {
+	job, err := h.Detail(jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "trace id: " + job.TraceID + "\n"
+}
+
+// traceDoer records the trace ID of its runs.
+type traceDoer struct {
+	traces []string
+}
+
+func (d *traceDoer) Name() string {
+	return "trace"
+}
+
+func (d *traceDoer) Run(data []byte, w io.Writer) (int, error) {
+	return d.RunContext(context.Background(), data, w)
+}
+
+func (d *traceDoer) RunContext(ctx context.Context, data []byte, w io.Writer) (int, error) {
+	d.traces = append(d.traces, TraceFrom(ctx))
+	return StatusOK, nil
+}
+
+func TestTrace(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	var events []Event
+	h := newTestWorm(t, WithManualTick(start), WithEventHandler(func(e Event) {
+		events = append(events, e)
+	}))
+	defer closeTestWorm(t, h)
+	doer := &traceDoer{}
+	h.MustRegister("trace", doer)
+	h.MustRegister("spawn", &spawnDoer{h: h, worker: "trace"})
+
+	ctx := ContextWithTrace(context.Background(), "req-1")
+	parentID, err := h.Queue("spawn", nil, Trace(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start)
+	h.Tick(start.Add(time.Minute))
+	if len(doer.traces) != 1 || doer.traces[0] != "req-1" {
+		t.Fatalf("child context : got [%v]", doer.traces)
+	}
+	parent, err := h.Detail(parentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent.TraceID != "req-1" || len(parent.Children) != 1 {
+		t.Fatalf("parent : got [%+v]", parent)
+	}
+	child, err := h.Detail(parent.Children[0])
+	if err != nil || child.TraceID != "req-1" {
+		t.Fatalf("child : got [%+v] err [%v]", child, err)
+	}
+	for _, e := range events {
+		if e.TraceID != "req-1" {
+			t.Fatalf("event : got [%+v]", e)
+		}
+	}
+	var b bytes.Buffer
+	if err := h.CopyLog(&b, parentID); err != nil || b.String() != "trace id: req-1\n" {
+		t.Fatalf("log : got [%q] err [%v]", b.String(), err)
+	}
+
+	// jobs queued without a trace ID get a new one.
+	var ids []string
+	for i := 0; i < 2; i++ {
+		jobID, err := h.Queue("trace", nil, Trace(context.Background()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		job, err := h.Detail(jobID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, job.TraceID)
+	}
+	if len(ids[0]) < 1 || ids[0] == ids[1] {
+		t.Fatalf("generated : got [%v]", ids)
+	}
+}
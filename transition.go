@@ -0,0 +1,94 @@
+package worm
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrConflict is returned when a job changed since it was read or its
+// current state does not allow the status change.
+var ErrConflict = errors.New("worm: job status conflict")
+
+// transitions are the state changes allowed to single execution jobs besides
+// the run results. Recurring jobs move freely.
+var transitions = map[string][]string{
+	StateWaiting: {StatePending},
+	StateFailed:  {StateWaiting, StatePending},
+}
+
+// jobState returns the state of a job with status finished at finishedAt.
+func jobState(status int, finishedAt *time.Time) string {
+	switch {
+	case status == StatusWaiting:
+		return StateWaiting
+	case finishedAt == nil:
+		return StatePending
+	case status == StatusOK:
+		return StateSucceeded
+	}
+	return StateFailed
+}
+
+// allowed reports if a single execution job can move from state to state.
+func allowed(from, to string) bool {
+	for _, x := range transitions[from] {
+		if x == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transition moves the job to the waiting or pending state, clearing its
+// result. set adds column assignments, like ",data=?", with args. Returns
+// ErrConflict when the transition is not allowed or the job changed
+// concurrently.
+func (h *Worm) transition(jobID, to, set string, args ...interface{}) error {
+	var job struct {
+		Status     int        `db:"status"`
+		Cron       string     `db:"cron"`
+		FinishedAt *time.Time `db:"finished_at"`
+		Version    int64      `db:"version"`
+	}
+	status := StatusStart
+	if to == StateWaiting {
+		status = StatusWaiting
+	}
+	o := <-h.waitc
+	defer func() {
+		h.waitc <- o
+	}()
+	err := h.dbGet(&job, `
+		SELECT status, IFNULL(cron,'') AS "cron", finished_at, IFNULL(version,0) AS "version"
+		FROM worm WHERE id=?;
+	`, jobID)
+	if err != nil {
+		return err
+	}
+	if len(job.Cron) < 1 && !allowed(jobState(job.Status, job.FinishedAt), to) {
+		return ErrConflict
+	}
+	return h.move(jobID, job.Version, status, set, args...)
+}
+
+// move updates the job like transition if the row is still at version, see
+// the worm_version trigger. Timestamps can't tell concurrent changes apart,
+// hub time stands still between ticks. Must hold waitc.
+func (h *Worm) move(jobID string, version int64, status int, set string, args ...interface{}) error {
+	q := `UPDATE worm SET status=?,error='',finished_at=NULL,updated_at=?,version=IFNULL(version,0)+1` + set +
+		` WHERE id=? AND IFNULL(version,0)=?;`
+	params := append([]interface{}{status, h.now()}, args...)
+	params = append(params, jobID, version)
+	res, err := h.dbExec(q, params...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n < 1 {
+		return ErrConflict
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package worm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAllowed(t *testing.T) {
+	table := []struct {
+		from, to string
+		ok       bool
+	}{
+		{StateWaiting, StatePending, true},
+		{StateFailed, StatePending, true},
+		{StateFailed, StateWaiting, true},
+		{StateSucceeded, StatePending, false},
+		{StatePending, StateWaiting, false},
+	}
+	for _, x := range table {
+		if allowed(x.from, x.to) != x.ok {
+			t.Errorf("[%s] to [%s] : expected [%v]", x.from, x.to, x.ok)
+		}
+	}
+}
+
+func TestTransition(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	h.MustRegister("ok", &testDoer{name: "ok"})
+	h.MustRegister("fail", &testDoer{name: "fail", status: 2, err: errors.New("boom")})
+
+	okID, _ := h.Queue("ok", nil)
+	failID, _ := h.Queue("fail", nil)
+	h.Tick(start.Add(time.Minute))
+
+	if err := h.transition(okID, StatePending, ""); err != ErrConflict {
+		t.Fatalf("succeeded job : expected ErrConflict got [%v]", err)
+	}
+	if err := h.transition(failID, StatePending, ""); err != nil {
+		t.Fatalf("failed job : err [%s]", err)
+	}
+	job, err := h.Detail(failID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != StatusStart || job.Error != "" || job.UpdatedAt == nil {
+		t.Fatalf("failed job : got [%+v]", job)
+	}
+	if err := h.transition(failID, StateWaiting, ""); err != ErrConflict {
+		t.Fatalf("pending job : expected ErrConflict got [%v]", err)
+	}
+}
+
+func TestTransitionVersion(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	h.MustRegister("fail", &testDoer{name: "fail", status: 2, err: errors.New("boom")})
+	jobID, _ := h.Queue("fail", nil)
+	h.Tick(start.Add(time.Minute))
+
+	version := func() int64 {
+		var v int64
+		if err := h.Db.Get(&v, `SELECT version FROM worm WHERE id=?;`, jobID); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	stale := version()
+	// a concurrent change at the same hub time.
+	if err := h.transition(jobID, StatePending, ""); err != nil {
+		t.Fatal(err)
+	}
+	if version() <= stale {
+		t.Fatalf("expected version after [%d] got [%d]", stale, version())
+	}
+	o := <-h.waitc
+	err := h.move(jobID, stale, StatusStart, "")
+	h.waitc <- o
+	if err != ErrConflict {
+		t.Fatalf("stale version : expected ErrConflict got [%v]", err)
+	}
+	// any update moves the version.
+	current := version()
+	if _, err := h.Db.Exec(`UPDATE worm SET data='x' WHERE id=?;`, jobID); err != nil {
+		t.Fatal(err)
+	}
+	if version() != current+1 {
+		t.Fatalf("update : expected version [%d] got [%d]", current+1, version())
+	}
+}
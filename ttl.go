@@ -0,0 +1,48 @@
+package worm
+
+import (
+	"log"
+	"time"
+)
+
+// TTL discards the single execution job if it did not start within d of
+// being due: it finishes with StatusExpired instead of running late, like an
+// OTP message nobody waits for anymore.
+func TTL(d time.Duration) JobOption {
+	return func(o *jobOptions) {
+		o.ttl = d
+	}
+}
+
+// expireJob finishes the job with StatusExpired if its TTL passed and nobody
+// runs it. No completion hooks run. Returns true if the job expired.
+func (h *Worm) expireJob(jobID string) bool {
+	now := h.now()
+	o := <-h.waitc
+	res, err := h.dbExec(`
+		UPDATE worm SET status=?,error='expired',finished_at=?,updated_at=?
+		WHERE id=? AND cron='' AND finished_at IS NULL AND expires_at<=?
+		AND (claimed_until IS NULL OR claimed_until<?);
+	`, StatusExpired, now, now, jobID, now, now)
+	var job struct {
+		Worker  string `db:"worker_name"`
+		TraceID string `db:"trace_id"`
+	}
+	if err == nil {
+		err = h.dbGet(&job, `SELECT worker_name,IFNULL(trace_id,'') AS "trace_id" FROM worm WHERE id=?;`, jobID)
+	}
+	h.waitc <- o
+	if err != nil {
+		log.Printf("expireJob : err [%s] job id [%s]", err, jobID)
+		return false
+	}
+	if n, err := res.RowsAffected(); err != nil || n < 1 {
+		return false
+	}
+	log.Printf("expireJob : worker [%s] job expired : job id [%s]", job.Worker, jobID)
+	h.sweeps.add(func(s *SweepStats) {
+		s.Expired++
+	})
+	h.emit(Event{Type: EventExpired, Worker: job.Worker, JobID: jobID, Status: StatusExpired, TraceID: job.TraceID})
+	return true
+}
@@ -0,0 +1,50 @@
+package worm
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTTL(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	expired := make(chan Event, 10)
+	h := newTestWorm(t, WithManualTick(start), WithEventHandler(func(e Event) {
+		if e.Type == EventExpired {
+			expired <- e
+		}
+	}))
+	defer closeTestWorm(t, h)
+
+	d := &countDoer{}
+	h.MustRegister("count", d)
+	otp, err := h.Queue("count", nil, TTL(5*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh, err := h.QueueAt("count", nil, start.Add(10*time.Minute), TTL(5*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the hub is paused for 10 minutes.
+	h.Pause()
+	h.Tick(start.Add(10 * time.Minute))
+	h.Resume()
+	waitStatus(t, h, otp, StatusExpired)
+	waitStatus(t, h, fresh, StatusOK)
+	if e := <-expired; e.JobID != otp || e.Status != StatusExpired {
+		t.Fatalf("event : got [%+v]", e)
+	}
+	if atomic.LoadInt32(&d.runs) != 1 {
+		t.Fatalf("runs : expected 1 got [%d]", d.runs)
+	}
+
+	// retried jobs run regardless of the TTL.
+	if err := h.Retry(otp); err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(11 * time.Minute))
+	if atomic.LoadInt32(&d.runs) != 2 {
+		t.Fatalf("retry : expected 2 runs got [%d]", d.runs)
+	}
+}
@@ -0,0 +1,159 @@
+package worm
+
+import (
+	"database/sql"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+// cronEntry is the schedule of a recurring job. UpdateSchedule replaces it,
+// replaced entries never fire again.
+type cronEntry struct {
+	schedule cron.Schedule
+	// opts keeps the window and calendar of the job.
+	opts *jobOptions
+	// done is 1 once replaced.
+	done int32
+
+	// mu guards nexts, the last firings returned to the scheduler, and
+	// dueAt, the due time of the last firing.
+	mu    sync.Mutex
+	nexts [2]time.Time
+	dueAt time.Time
+}
+
+// Next implements cron.Schedule. Replaced entries return zero time, which
+// the schedulers never fire.
+func (e *cronEntry) Next(t time.Time) time.Time {
+	if e.retired() {
+		return time.Time{}
+	}
+	next := e.schedule.Next(t)
+	e.mu.Lock()
+	e.nexts[0], e.nexts[1] = e.nexts[1], next
+	e.mu.Unlock()
+	return next
+}
+
+// fire records the due time of the firing at now: the latest firing given
+// to the scheduler not after now. Schedulers may ask for the following one
+// before or after running the job.
+func (e *cronEntry) fire(now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.dueAt = time.Time{}
+	for _, t := range e.nexts {
+		if !t.IsZero() && !t.After(now) && t.After(e.dueAt) {
+			e.dueAt = t
+		}
+	}
+}
+
+// due returns the due time of the last firing, zero before the first one.
+func (e *cronEntry) due() time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.dueAt
+}
+
+func (e *cronEntry) retire() {
+	atomic.StoreInt32(&e.done, 1)
+}
+
+func (e *cronEntry) retired() bool {
+	return atomic.LoadInt32(&e.done) == 1
+}
+
+// UpdateSchedule replaces the data and the schedule spec of a recurring job
+// scheduled by this hub. The job keeps its ID and run history; the new spec
+// applies from now and the data from the next firing.
+func (h *Worm) UpdateSchedule(jobID string, data []byte, spec string) error {
+	h.RLock()
+	prev, ok := h.crons[jobID]
+	h.RUnlock()
+	if !ok {
+		return ErrNotFound
+	}
+	return h.updateSchedule(jobID, "", data, spec, prev.opts)
+}
+
+// updateSchedule stores the worker, data and spec of the recurring job and
+// crons it on this hub with opts. Empty workerName keeps the stored one.
+func (h *Worm) updateSchedule(jobID, workerName string, data []byte, spec string, opts *jobOptions) error {
+	schedule, err := h.schedule(spec, opts)
+	if err != nil {
+		return err
+	}
+
+	var job struct {
+		Worker  string `db:"worker_name"`
+		BlobKey string `db:"blob_key"`
+	}
+	o := <-h.waitc
+	err = h.dbGet(&job, `
+		SELECT worker_name, IFNULL(blob_key,'') AS "blob_key" FROM worm
+		WHERE id=? AND cron<>'' AND deleted_at IS NULL;
+	`, jobID)
+	h.waitc <- o
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if len(workerName) < 1 {
+		workerName = job.Worker
+	}
+	wk, ok := h.target(workerName)
+	if !ok {
+		return ErrNotFound
+	}
+	if err := wk.validate(data); err != nil {
+		return err
+	}
+	var hash string
+	if wk.dedup > 0 {
+		hash = payloadHash(data)
+	}
+
+	stored, blobKey := data, ""
+	if h.blobbed(data) {
+		key := newBlobKey(jobID)
+		if err := h.blobs.Put(key, data); err != nil {
+			return err
+		}
+		stored, blobKey = nil, key
+	}
+	signature := h.sign(jobID, workerName, data)
+	o = <-h.waitc
+	// stand-in workers of scheduler-only hubs keep the stored redaction.
+	_, err = h.dbExec(`
+		UPDATE worm SET worker_name=?,data=?,blob_key=?,cron=?,payload_hash=?,signature=?,
+			redact=COALESCE(NULLIF(?,''),redact),updated_at=?
+		WHERE id=?;
+	`, workerName, stored, blobKey, spec, hash, signature, redactList(wk), h.now(), jobID)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("UpdateSchedule : err [%s] job id [%s]", err, jobID)
+		if len(blobKey) > 0 {
+			h.blobs.Delete(blobKey)
+		}
+		return err
+	}
+	if len(job.BlobKey) > 0 {
+		if err := h.blobs.Delete(job.BlobKey); err != nil {
+			log.Printf("UpdateSchedule : delete blob : err [%s] job id [%s]", err, jobID)
+		}
+	}
+	h.cronRun(wk.doer, jobID, stored, schedule, opts)
+	return nil
+}
+
+// UpdateSchedule _
+func UpdateSchedule(jobID string, data []byte, spec string) error {
+	return defaultWorm.UpdateSchedule(jobID, data, spec)
+}
@@ -0,0 +1,52 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdateSchedule(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+
+	d := &dataDoer{}
+	h.MustRegister("data", d)
+	jobID, err := h.Sched("data", []byte(`"hourly"`), "0 0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := h.Tick(start.Add(time.Hour)); n != 1 || string(d.data) != `"hourly"` {
+		t.Fatalf("tick : got [%d] firings data [%s]", n, d.data)
+	}
+
+	if err := h.UpdateSchedule(jobID, []byte(`"quarter"`), "0 */15 * * * *"); err != nil {
+		t.Fatal(err)
+	}
+	// the old hourly firing at 2:00 is gone, the new spec fires 4 times.
+	if n, _ := h.Tick(start.Add(2 * time.Hour)); n != 4 || string(d.data) != `"quarter"` {
+		t.Fatalf("tick : got [%d] firings data [%s]", n, d.data)
+	}
+	job, err := h.Detail(jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Cron != "0 */15 * * * *" || string(job.Data) != `"quarter"` {
+		t.Fatalf("detail : got cron [%s] data [%s]", job.Cron, job.Data)
+	}
+	runs, err := h.Runs(jobID)
+	if err != nil || len(runs) != 5 {
+		t.Fatalf("runs : expected 5 got [%d] err [%v]", len(runs), err)
+	}
+
+	if err := h.UpdateSchedule(jobID, nil, "bad spec"); err == nil {
+		t.Fatalf("expected spec error")
+	}
+	single, err := h.Queue("data", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.UpdateSchedule(single, nil, "@hourly"); err != ErrNotFound {
+		t.Fatalf("single execution : expected ErrNotFound got [%v]", err)
+	}
+}
@@ -0,0 +1,64 @@
+package worm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError is returned by Queue and Sched when the job data is
+// rejected by the worker validators.
+type ValidationError struct {
+	Worker string   `json:"worker"`
+	Errors []string `json:"errors"`
+}
+
+// Error implements error.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("worm: invalid data for worker %s: %s", e.Worker, strings.Join(e.Errors, "; "))
+}
+
+// Validate registers fn to check the data of every new job of the worker.
+func Validate(fn func(data []byte) error) WorkerOption {
+	return func(w *worker) error {
+		w.validators = append(w.validators, fn)
+		return nil
+	}
+}
+
+// ValidateSchema checks the data of new jobs against a JSON Schema. Only a
+// subset of the draft 4 keywords is supported: type, properties, required,
+// items, enum, minimum, maximum, minLength, maxLength and
+// additionalProperties false.
+func ValidateSchema(schema []byte) WorkerOption {
+	return func(w *worker) error {
+		s, err := parseSchema(schema)
+		if err != nil {
+			return fmt.Errorf("worm: worker %s: %s", w.name, err)
+		}
+		w.validators = append(w.validators, s.validate)
+		return nil
+	}
+}
+
+// validate runs the worker validators on data.
+func (w *worker) validate(data []byte) error {
+	var list []string
+	for _, fn := range w.validators {
+		err := fn(data)
+		if err == nil {
+			continue
+		}
+		if sErr, ok := err.(schemaErrors); ok {
+			list = append(list, sErr...)
+			continue
+		}
+		list = append(list, err.Error())
+	}
+	if len(list) > 0 {
+		return &ValidationError{
+			Worker: w.name,
+			Errors: list,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package worm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateSchema(t *testing.T) {
+	w := &worker{name: "mailer"}
+	err := ValidateSchema([]byte(`{
+		"type": "object",
+		"required": ["to", "retries"],
+		"additionalProperties": false,
+		"properties": {
+			"to": {"type": "string", "minLength": 3},
+			"retries": {"type": "integer", "minimum": 0, "maximum": 5},
+			"tags": {"type": "array", "items": {"enum": ["a", "b"]}}
+		}
+	}`))(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var table = []struct {
+		Data   string
+		Errors int
+	}{
+		{`{"to":"abc","retries":1}`, 0},
+		{`{"to":"abc","retries":1,"tags":["a","b"]}`, 0},
+		{`{"to":"ab","retries":9}`, 2},
+		{`{"retries":1.5,"extra":true}`, 3},
+		{`{"to":"abc","retries":1,"tags":["c"]}`, 1},
+		{`[]`, 1},
+		{`not json`, 1},
+	}
+	for _, x := range table {
+		err := w.validate([]byte(x.Data))
+		if x.Errors == 0 {
+			if err != nil {
+				t.Errorf("%s : err [%s]", x.Data, err)
+			}
+			continue
+		}
+		vErr, ok := err.(*ValidationError)
+		if !ok {
+			t.Errorf("%s : expected ValidationError got [%v]", x.Data, err)
+			continue
+		}
+		if len(vErr.Errors) != x.Errors || vErr.Worker != "mailer" {
+			t.Errorf("%s : expected %d errors got [%v]", x.Data, x.Errors, vErr.Errors)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	h := newTestWorm(t)
+	defer closeTestWorm(t, h)
+	h.MustRegister("a", &testDoer{name: "a"}, Validate(func(data []byte) error {
+		if len(data) < 1 {
+			return errors.New("empty")
+		}
+		return nil
+	}))
+
+	if _, err := h.Queue("a", nil); err == nil {
+		t.Fatalf("queue empty : expected error")
+	}
+	if _, err := h.Queue("a", []byte("x")); err != nil {
+		t.Fatalf("queue : err [%s]", err)
+	}
+}
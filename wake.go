@@ -0,0 +1,13 @@
+package worm
+
+// Wake runs the pending single execution jobs now instead of on their next
+// firing. It lets a process react at once to an external notification that
+// another process queued jobs in the shared database.
+func (h *Worm) Wake() {
+	h.claimPending()
+}
+
+// Wake _
+func Wake() {
+	defaultWorm.Wake()
+}
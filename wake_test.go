@@ -0,0 +1,28 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWake(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	d := &countDoer{}
+	h.MustRegister("count", d)
+
+	jobID, err := h.Queue("count", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// no tick, the job runs on Wake.
+	h.Wake()
+	for i := 0; i < 100; i++ {
+		if job, _ := h.Detail(jobID); job.Status == StatusOK {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected job run on wake")
+}
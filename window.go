@@ -0,0 +1,76 @@
+package worm
+
+import (
+	"errors"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+// errWindow is returned by Queue and Sched for windows out of the day.
+var errWindow = errors.New("worm: invalid window, from and to must differ and be within 0-24h")
+
+// window is the time of day a job may run, from until to. Windows where
+// from is after to span midnight.
+type window struct {
+	from, to time.Duration
+}
+
+// Window makes the job run only between from and to, offsets from midnight
+// in hub time (UTC). Work due outside the window is deferred to the window
+// start; Window(22*time.Hour, 6*time.Hour) runs only at night.
+func Window(from, to time.Duration) JobOption {
+	return func(o *jobOptions) {
+		o.window = &window{from: from, to: to}
+	}
+}
+
+// valid reports if the window is within the day.
+func (w *window) valid() bool {
+	day := 24 * time.Hour
+	return w.from >= 0 && w.from < day && w.to >= 0 && w.to < day && w.from != w.to
+}
+
+// contains reports if t is within the window.
+func (w *window) contains(t time.Time) bool {
+	t = t.UTC()
+	d := t.Sub(midnight(t))
+	if w.from < w.to {
+		return d >= w.from && d < w.to
+	}
+	return d >= w.from || d < w.to
+}
+
+// next returns t if within the window, else the next window start.
+func (w *window) next(t time.Time) time.Time {
+	t = t.UTC()
+	if w.contains(t) {
+		return t
+	}
+	start := midnight(t).Add(w.from)
+	if start.Before(t) {
+		start = start.AddDate(0, 0, 1)
+	}
+	return start
+}
+
+// midnight returns the start of the day of t.
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// windowed is a cron.Schedule deferring firings outside a window to the
+// window start. Firings deferred to the same start fire once.
+type windowed struct {
+	schedule cron.Schedule
+	window   *window
+}
+
+// Next implements cron.Schedule.
+func (s windowed) Next(t time.Time) time.Time {
+	next := s.schedule.Next(t)
+	if next.IsZero() {
+		return next
+	}
+	return s.window.next(next)
+}
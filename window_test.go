@@ -0,0 +1,76 @@
+package worm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowNext(t *testing.T) {
+	night := &window{from: 22 * time.Hour, to: 6 * time.Hour}
+	day := func(h, m int) time.Time {
+		return time.Date(2016, 1, 1, h, m, 0, 0, time.UTC)
+	}
+	for _, tc := range []struct {
+		t, next time.Time
+	}{
+		{day(23, 0), day(23, 0)},
+		{day(5, 59), day(5, 59)},
+		{day(6, 0), day(22, 0)},
+		{day(12, 30), day(22, 0)},
+		{day(22, 0), day(22, 0)},
+	} {
+		if got := night.next(tc.t); !got.Equal(tc.next) {
+			t.Fatalf("next [%s] : expected [%s] got [%s]", tc.t, tc.next, got)
+		}
+	}
+	office := &window{from: 9 * time.Hour, to: 17 * time.Hour}
+	if got := office.next(day(18, 0)); !got.Equal(day(9, 0).AddDate(0, 0, 1)) {
+		t.Fatalf("next : expected next day 09:00 got [%s]", got)
+	}
+	// windows are in hub time whatever the location of t.
+	east := time.FixedZone("UTC+8", 8*60*60)
+	if got := night.next(day(23, 0).In(east)); !got.Equal(day(23, 0)) {
+		t.Fatalf("next east : expected [%s] got [%s]", day(23, 0), got)
+	}
+	if got := office.next(day(3, 0).In(east)); !got.Equal(day(9, 0)) {
+		t.Fatalf("next east : expected [%s] got [%s]", day(9, 0), got)
+	}
+	if (&window{from: time.Hour, to: time.Hour}).valid() {
+		t.Fatalf("expected empty window invalid")
+	}
+}
+
+func TestWindow(t *testing.T) {
+	start := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+
+	d := &countDoer{}
+	h.MustRegister("count", d)
+	night := Window(22*time.Hour, 6*time.Hour)
+	jobID, err := h.Queue("count", nil, night)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// hourly firings during the day collapse at 22:00.
+	if _, err := h.Sched("count", nil, "0 0 * * * *", night); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := h.Tick(start.Add(9*time.Hour + 59*time.Minute)); n != 0 {
+		t.Fatalf("tick 21:59 : expected 0 runs got [%d]", n)
+	}
+	if n, _ := h.Tick(start.Add(10 * time.Hour)); n != 2 {
+		t.Fatalf("tick 22:00 : expected 2 runs got [%d]", n)
+	}
+	job, err := h.Detail(jobID)
+	if err != nil || job.Status != StatusOK {
+		t.Fatalf("detail : got [%+v] err [%v]", job, err)
+	}
+	if n, _ := h.Tick(start.Add(11 * time.Hour)); n != 1 {
+		t.Fatalf("tick 23:00 : expected 1 run got [%d]", n)
+	}
+
+	if _, err := h.Queue("count", nil, Window(-time.Hour, time.Hour)); err != errWindow {
+		t.Fatalf("expected errWindow got [%v]", err)
+	}
+}
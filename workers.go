@@ -0,0 +1,180 @@
+package worm
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// worker holds a registered Doer.
+type worker struct {
+	name string
+	doer Doer
+
+	// validators check the data of new jobs.
+	validators []func([]byte) error
+	// dedup drops jobs with the same data queued within the window.
+	dedup time.Duration
+	// compensation is the worker that undoes failed jobs.
+	compensation string
+	// fallbackTo is the worker failed jobs are routed to.
+	fallbackTo string
+	// maxPending limits the pending jobs of the worker when greater than
+	// zero.
+	maxPending int
+	// weight is the share of the worker in fair dispatch.
+	weight int
+	// migrators upgrade payloads by version, read from versionField.
+	migrators    map[int]func([]byte) ([]byte, error)
+	versionField string
+	// config is the default configuration of the runs.
+	config []byte
+	// redact are the data fields masked in the job listings.
+	redact map[string]bool
+	// delivery is the guarantee on interrupted runs.
+	delivery Guarantee
+	// maxRetries is how many times failed jobs run again.
+	maxRetries int
+	// timeout bounds the runs when greater than zero.
+	timeout time.Duration
+	// logRetention is how long the logs of the worker jobs are kept when
+	// greater than zero, see WithLogRetention.
+	logRetention time.Duration
+	// queue is the queue of the worker, see OnQueue.
+	queue string
+	// concurrency and rate limit the runs of the worker when greater than
+	// zero, see acquire.
+	concurrency int
+	rate        int
+	per         time.Duration
+
+	// mu guards the health, pause and run slot state.
+	mu        sync.RWMutex
+	healthErr error
+	checkedAt time.Time
+	paused    bool
+	// pausedUntil ends the pause of quarantined workers.
+	pausedUntil time.Time
+	// failures counts the consecutive failed jobs.
+	failures int
+	// quarantine pauses the worker for coolDown after threshold consecutive
+	// failures when threshold is greater than zero.
+	threshold int
+	coolDown  time.Duration
+	// running counts the runs holding a slot, starts are their start times
+	// within the rate period.
+	running int
+	starts  []time.Time
+}
+
+// WorkerOption configures a worker on Register.
+type WorkerOption func(*worker) error
+
+// WorkerInfo describes a registered worker.
+type WorkerInfo struct {
+	Name        string    `json:"name"`
+	Healthy     bool      `json:"healthy"`
+	HealthError string    `json:"health_error,omitempty"`
+	CheckedAt   time.Time `json:"checked_at,omitempty"`
+	Paused      bool      `json:"paused"`
+	// PausedUntil is the end of the quarantine of paused workers.
+	PausedUntil time.Time `json:"paused_until,omitempty"`
+	// Delivery is the delivery guarantee of the worker jobs.
+	Delivery string `json:"delivery"`
+	Queue    string `json:"queue"`
+	// MaxRetries, Timeout, LogRetention, Concurrency and RateLimit per
+	// RatePeriod are the policies of the worker, hub defaults included. Zero
+	// when off.
+	MaxRetries   int           `json:"max_retries,omitempty"`
+	Timeout      time.Duration `json:"timeout,omitempty"`
+	LogRetention time.Duration `json:"log_retention,omitempty"`
+	Concurrency  int           `json:"concurrency,omitempty"`
+	RateLimit    int           `json:"rate_limit,omitempty"`
+	RatePeriod   time.Duration `json:"rate_period,omitempty"`
+	// Running is the number of runs of the worker on this hub.
+	Running int `json:"running"`
+}
+
+// info returns the public description of the worker at now.
+func (w *worker) info(now time.Time) WorkerInfo {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	x := WorkerInfo{
+		Name:      w.name,
+		Healthy:   w.healthErr == nil,
+		CheckedAt: w.checkedAt,
+		Paused:    w.paused && (w.pausedUntil.IsZero() || w.pausedUntil.After(now)),
+		Delivery:  w.delivery.String(),
+		Queue:     w.queueName(),
+
+		MaxRetries:   w.maxRetries,
+		Timeout:      w.timeout,
+		LogRetention: w.logRetention,
+		Concurrency:  w.concurrency,
+		RateLimit:    w.rate,
+		RatePeriod:   w.per,
+		Running:      w.running,
+	}
+	if x.Paused {
+		x.PausedUntil = w.pausedUntil
+	}
+	if w.healthErr != nil {
+		x.HealthError = w.healthErr.Error()
+	}
+	return x
+}
+
+// workerOf returns the worker registered as name or, failing that, the
+// worker whose doer has that name.
+func (h *Worm) workerOf(name string) (*worker, bool) {
+	h.RLock()
+	defer h.RUnlock()
+	if wk, ok := h.workers[name]; ok {
+		return wk, true
+	}
+	for _, wk := range h.workers {
+		if wk.doer.Name() == name {
+			return wk, true
+		}
+	}
+	return nil, false
+}
+
+// Workers returns the registered workers sorted by name.
+func (h *Worm) Workers() []WorkerInfo {
+	now := h.now()
+	h.RLock()
+	list := make([]WorkerInfo, 0, len(h.workers))
+	for _, wk := range h.workers {
+		list = append(list, wk.info(now))
+	}
+	h.RUnlock()
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Deregister removes the worker from this worm. New jobs for it are rejected,
+// jobs already scheduled keep running.
+func (h *Worm) Deregister(workerName string) error {
+	h.Lock()
+	defer h.Unlock()
+	_, ok := h.workers[workerName]
+	if !ok {
+		return errors.New("worm: doer not found")
+	}
+	delete(h.workers, workerName)
+	return nil
+}
+
+// Workers _
+func Workers() []WorkerInfo {
+	return defaultWorm.Workers()
+}
+
+// Deregister _
+func Deregister(workerName string) error {
+	return defaultWorm.Deregister(workerName)
+}
@@ -0,0 +1,29 @@
+package worm
+
+import "testing"
+
+func TestWorkers(t *testing.T) {
+	h := newTestWorm(t)
+	defer closeTestWorm(t, h)
+
+	h.MustRegister("b", &testDoer{name: "b"})
+	h.MustRegister("a", &testDoer{name: "a"})
+
+	list := h.Workers()
+	if len(list) != 2 || list[0].Name != "a" || list[1].Name != "b" {
+		t.Fatalf("workers : got [%v]", list)
+	}
+
+	if err := h.Deregister("a"); err != nil {
+		t.Fatalf("deregister : err [%s]", err)
+	}
+	if err := h.Deregister("a"); err == nil {
+		t.Fatalf("deregister twice : expected error")
+	}
+	if _, err := h.Queue("a", []byte("{}")); err == nil {
+		t.Fatalf("queue deregistered : expected error")
+	}
+	if list := h.Workers(); len(list) != 1 {
+		t.Fatalf("workers : got [%v]", list)
+	}
+}
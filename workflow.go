@@ -0,0 +1,379 @@
+package worm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Step is a named job of a workflow.
+type Step struct {
+	Name   string
+	Worker string
+	Data   []byte
+	// After lists the steps that must succeed before this one runs.
+	After []string
+	// FanIn runs the step once the After steps finished, failed or not,
+	// with a FanIn of their results as data.
+	FanIn bool
+}
+
+// FanIn is the data of fan in steps.
+type FanIn struct {
+	// Data is the data of the step.
+	Data    []byte        `json:"data"`
+	Results []*StepResult `json:"results"`
+}
+
+// StepResult is the result of a step a fan in step waited for.
+type StepResult struct {
+	Step   string `json:"step"`
+	JobID  string `json:"job_id"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Workflow and step states.
+const (
+	StateWaiting   = "waiting"
+	StatePending   = "pending"
+	StateRunning   = "running"
+	StateSucceeded = "succeeded"
+	StateFailed    = "failed"
+)
+
+// WorkflowInfo is the status of a workflow.
+type WorkflowInfo struct {
+	ID string `json:"id"`
+	// State is running until every step succeeded or some step failed.
+	State string      `json:"state"`
+	Steps []*StepInfo `json:"steps"`
+}
+
+// StepInfo is the status of a workflow step.
+type StepInfo struct {
+	Name   string   `json:"name"`
+	JobID  string   `json:"job_id"`
+	Worker string   `json:"worker"`
+	After  []string `json:"after,omitempty"`
+	State  string   `json:"state"`
+	Status int      `json:"status"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// Workflow stores the steps as a unit and runs each one after the steps it
+// depends on succeed. Steps must form a DAG. Returns the workflow ID.
+func (h *Worm) Workflow(steps []Step) (string, error) {
+	order, err := sortSteps(steps)
+	if err != nil {
+		return "", err
+	}
+	for _, s := range order {
+		if _, err := h.accept(s.Worker, s.Data); err != nil {
+			return "", fmt.Errorf("worm: step [%s] : %s", s.Name, err)
+		}
+	}
+
+	workflowID := h.ids.NewID()
+	for _, s := range order {
+		opts := newJobOptions(once, nil)
+		opts.workflowID = workflowID
+		opts.step = s.Name
+		opts.after = s.After
+		opts.fanIn = s.FanIn
+		opts.waiting = true
+		if _, _, err := h.store(s.Worker, s.Data, opts); err != nil {
+			h.dropWorkflow(workflowID)
+			return "", fmt.Errorf("worm: step [%s] : %s", s.Name, err)
+		}
+	}
+	if err := h.advance(workflowID); err != nil {
+		return "", err
+	}
+	return workflowID, nil
+}
+
+// sortSteps validates the steps and returns them in dependency order.
+func sortSteps(steps []Step) ([]Step, error) {
+	if len(steps) < 1 {
+		return nil, errors.New("worm: workflow without steps")
+	}
+	byName := make(map[string]Step, len(steps))
+	for _, s := range steps {
+		if len(s.Name) < 1 || strings.Contains(s.Name, ",") {
+			return nil, fmt.Errorf("worm: invalid step name [%s]", s.Name)
+		}
+		if _, ok := byName[s.Name]; ok {
+			return nil, fmt.Errorf("worm: duplicated step [%s]", s.Name)
+		}
+		byName[s.Name] = s
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	marks := make(map[string]int, len(steps))
+	order := make([]Step, 0, len(steps))
+	var visit func(s Step) error
+	visit = func(s Step) error {
+		switch marks[s.Name] {
+		case visiting:
+			return fmt.Errorf("worm: workflow cycle at step [%s]", s.Name)
+		case visited:
+			return nil
+		}
+		marks[s.Name] = visiting
+		for _, name := range s.After {
+			dep, ok := byName[name]
+			if !ok {
+				return fmt.Errorf("worm: step [%s] after unknown step [%s]", s.Name, name)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		marks[s.Name] = visited
+		order = append(order, s)
+		return nil
+	}
+	for _, s := range steps {
+		if err := visit(s); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// dropWorkflow deletes the stored steps of a workflow that failed to store.
+func (h *Worm) dropWorkflow(workflowID string) {
+	o := <-h.waitc
+	_, err := h.dbExec(`DELETE FROM worm WHERE workflow_id=?;`, workflowID)
+	h.waitc <- o
+	if err != nil {
+		log.Printf("dropWorkflow : err [%s] workflow id [%s]", err, workflowID)
+	}
+}
+
+// workflowStep is a stored workflow step.
+type workflowStep struct {
+	ID         string     `db:"id"`
+	Worker     string     `db:"worker_name"`
+	Status     int        `db:"status"`
+	Error      string     `db:"error"`
+	Step       string     `db:"step"`
+	After      string     `db:"after_steps"`
+	FanIn      bool       `db:"fan_in"`
+	FinishedAt *time.Time `db:"finished_at"`
+}
+
+// state returns the state of the step.
+func (s *workflowStep) state() string {
+	return jobState(s.Status, s.FinishedAt)
+}
+
+// after returns the names of the steps s depends on.
+func (s *workflowStep) after() []string {
+	if len(s.After) < 1 {
+		return nil
+	}
+	return strings.Split(s.After, ",")
+}
+
+// steps returns the stored steps of the workflow.
+func (h *Worm) steps(workflowID string) ([]*workflowStep, error) {
+	var list []*workflowStep
+	o := <-h.waitc
+	err := h.dbSelect(&list, `
+		SELECT
+			id,
+			worker_name,
+			status,
+			IFNULL(error,'') AS "error",
+			IFNULL(step,'') AS "step",
+			IFNULL(after_steps,'') AS "after_steps",
+			IFNULL(fan_in,0) AS "fan_in",
+			finished_at
+		FROM worm WHERE workflow_id=? ORDER BY created_at, rowid;
+	`, workflowID)
+	h.waitc <- o
+	if err != nil {
+		return nil, err
+	}
+	if len(list) < 1 {
+		return nil, errors.New("worm: workflow not found")
+	}
+	return list, nil
+}
+
+// advance schedules the waiting steps of the workflow whose dependencies
+// are done. Steps of workers not registered on this hub are left to poll.
+func (h *Worm) advance(workflowID string) error {
+	list, err := h.steps(workflowID)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]*workflowStep, len(list))
+	for _, s := range list {
+		byName[s.Step] = s
+	}
+	for _, s := range list {
+		if s.Status != StatusWaiting {
+			continue
+		}
+		ready := true
+		for _, name := range s.after() {
+			switch byName[name].state() {
+			case StateSucceeded:
+			case StateFailed:
+				ready = ready && s.FanIn
+			default:
+				ready = false
+			}
+		}
+		if !ready {
+			continue
+		}
+		now := h.now()
+		set, args := ",run_at=?", []interface{}{now}
+		if s.FanIn {
+			data, err := h.fanIn(s, byName)
+			if err != nil {
+				return err
+			}
+			set, args = set+`,data=?,blob_key='',signature=?`, append(args, data, h.sign(s.ID, s.Worker, data))
+		}
+		// only one hub moves the step out of waiting.
+		err := h.transition(s.ID, StatePending, set, args...)
+		if err == ErrConflict {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		h.RLock()
+		wk, ok := h.workers[s.Worker]
+		h.RUnlock()
+		if !ok {
+			continue
+		}
+		h.dispatch(wk.doer, s.ID, nil, now)
+	}
+	return nil
+}
+
+// fanIn returns the data of the fan in step s with the results of the steps
+// it waited for.
+func (h *Worm) fanIn(s *workflowStep, byName map[string]*workflowStep) ([]byte, error) {
+	data, err := h.payload(s.ID)
+	if err != nil {
+		return nil, err
+	}
+	x := FanIn{Data: data}
+	for _, name := range s.after() {
+		dep := byName[name]
+		x.Results = append(x.Results, &StepResult{
+			Step:   dep.Step,
+			JobID:  dep.ID,
+			Status: dep.Status,
+			Error:  dep.Error,
+		})
+	}
+	return json.Marshal(x)
+}
+
+// FanOut stores the children and the join step as a workflow. Join runs once
+// every child finished, failed or not, with a FanIn of their results as
+// data. Unnamed children are named child-N and an unnamed join is named
+// join. Returns the workflow ID.
+func (h *Worm) FanOut(children []Step, join Step) (string, error) {
+	steps := make([]Step, 0, len(children)+1)
+	join.After, join.FanIn = nil, true
+	if len(join.Name) < 1 {
+		join.Name = "join"
+	}
+	for i, child := range children {
+		if len(child.Name) < 1 {
+			child.Name = fmt.Sprintf("child-%d", i)
+		}
+		steps = append(steps, child)
+		join.After = append(join.After, child.Name)
+	}
+	if len(children) < 1 {
+		return "", errors.New("worm: fan out without children")
+	}
+	return h.Workflow(append(steps, join))
+}
+
+// WorkflowStatus returns the status of the workflow and its steps.
+func (h *Worm) WorkflowStatus(workflowID string) (*WorkflowInfo, error) {
+	list, err := h.steps(workflowID)
+	if err != nil {
+		return nil, err
+	}
+	x := &WorkflowInfo{
+		ID:    workflowID,
+		State: StateSucceeded,
+	}
+	for _, s := range list {
+		info := &StepInfo{
+			Name:   s.Step,
+			JobID:  s.ID,
+			Worker: s.Worker,
+			After:  s.after(),
+			State:  s.state(),
+			Status: s.Status,
+			Error:  s.Error,
+		}
+		x.Steps = append(x.Steps, info)
+		switch {
+		case info.State == StateFailed:
+			x.State = StateFailed
+		case info.State != StateSucceeded && x.State != StateFailed:
+			x.State = StateRunning
+		}
+	}
+	return x, nil
+}
+
+// ResumeWorkflow runs the failed steps of the workflow again. The steps
+// after them run once they succeed.
+func (h *Worm) ResumeWorkflow(workflowID string) error {
+	list, err := h.steps(workflowID)
+	if err != nil {
+		return err
+	}
+	for _, s := range list {
+		if s.state() != StateFailed {
+			continue
+		}
+		if err := h.transition(s.ID, StateWaiting, ""); err != nil {
+			log.Printf("ResumeWorkflow : reset failed step : err [%s] job id [%s]", err, s.ID)
+			return err
+		}
+	}
+	return h.advance(workflowID)
+}
+
+// Workflow _
+func Workflow(steps []Step) (string, error) {
+	return defaultWorm.Workflow(steps)
+}
+
+// WorkflowStatus _
+func WorkflowStatus(workflowID string) (*WorkflowInfo, error) {
+	return defaultWorm.WorkflowStatus(workflowID)
+}
+
+// FanOut _
+func FanOut(children []Step, join Step) (string, error) {
+	return defaultWorm.FanOut(children, join)
+}
+
+// ResumeWorkflow _
+func ResumeWorkflow(workflowID string) error {
+	return defaultWorm.ResumeWorkflow(workflowID)
+}
@@ -0,0 +1,154 @@
+package worm
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// dataDoer keeps the data of its last run.
+type dataDoer struct {
+	data []byte
+}
+
+func (d *dataDoer) Name() string {
+	return "data"
+}
+
+func (d *dataDoer) Run(data []byte, w io.Writer) (int, error) {
+	d.data = data
+	return StatusOK, nil
+}
+
+func TestSortSteps(t *testing.T) {
+	order, err := sortSteps([]Step{
+		{Name: "d", After: []string{"b", "c"}},
+		{Name: "b", After: []string{"a"}},
+		{Name: "c", After: []string{"a"}},
+		{Name: "a"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pos := map[string]int{}
+	for i, s := range order {
+		pos[s.Name] = i
+	}
+	if pos["a"] > pos["b"] || pos["a"] > pos["c"] || pos["b"] > pos["d"] || pos["c"] > pos["d"] {
+		t.Fatalf("invalid order [%+v]", order)
+	}
+
+	table := [][]Step{
+		nil,
+		{{Name: "a"}, {Name: "a"}},
+		{{Name: ""}},
+		{{Name: "a", After: []string{"x"}}},
+		{{Name: "a", After: []string{"b"}}, {Name: "b", After: []string{"a"}}},
+	}
+	for i, steps := range table {
+		if _, err := sortSteps(steps); err == nil {
+			t.Errorf("case %d : expected error", i)
+		}
+	}
+}
+
+func TestWorkflow(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	flaky := &testDoer{name: "flaky", status: 2, err: errors.New("boom")}
+	h.MustRegister("ok", &testDoer{name: "ok"})
+	h.MustRegister("flaky", flaky)
+
+	workflowID, err := h.Workflow([]Step{
+		{Name: "extract", Worker: "ok"},
+		{Name: "transform", Worker: "flaky", After: []string{"extract"}},
+		{Name: "report", Worker: "ok", After: []string{"extract"}},
+		{Name: "load", Worker: "ok", After: []string{"transform", "report"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := func(state string, steps map[string]string) {
+		info, err := h.WorkflowStatus(workflowID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.State != state {
+			t.Errorf("workflow : expected [%s] got [%s]", state, info.State)
+		}
+		for _, s := range info.Steps {
+			if s.State != steps[s.Name] {
+				t.Errorf("step [%s] : expected [%s] got [%s]", s.Name, steps[s.Name], s.State)
+			}
+		}
+	}
+	expect(StateRunning, map[string]string{
+		"extract": StatePending, "transform": StateWaiting, "report": StateWaiting, "load": StateWaiting,
+	})
+
+	h.Tick(start.Add(time.Minute))
+	expect(StateFailed, map[string]string{
+		"extract": StateSucceeded, "transform": StateFailed, "report": StateSucceeded, "load": StateWaiting,
+	})
+
+	flaky.status, flaky.err = StatusOK, nil
+	if err := h.ResumeWorkflow(workflowID); err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(2 * time.Minute))
+	expect(StateSucceeded, map[string]string{
+		"extract": StateSucceeded, "transform": StateSucceeded, "report": StateSucceeded, "load": StateSucceeded,
+	})
+
+	if _, err := h.Workflow([]Step{{Name: "a", Worker: "none"}}); err == nil {
+		t.Errorf("unknown worker : expected error")
+	}
+	if _, err := h.WorkflowStatus("none"); err == nil {
+		t.Errorf("unknown workflow : expected error")
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestWorm(t, WithManualTick(start))
+	defer closeTestWorm(t, h)
+	join := &dataDoer{}
+	h.MustRegister("ok", &testDoer{name: "ok"})
+	h.MustRegister("fail", &testDoer{name: "fail", status: 2, err: errors.New("boom")})
+	h.MustRegister("join", join)
+
+	workflowID, err := h.FanOut([]Step{
+		{Worker: "ok", Data: []byte("1")},
+		{Worker: "fail", Data: []byte("2")},
+		{Worker: "ok", Data: []byte("3")},
+	}, Step{Worker: "join", Data: []byte("report")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Tick(start.Add(time.Minute))
+
+	var x FanIn
+	if err := json.Unmarshal(join.data, &x); err != nil {
+		t.Fatalf("join data [%s] : err [%s]", join.data, err)
+	}
+	if string(x.Data) != "report" || len(x.Results) != 3 {
+		t.Fatalf("fan in : got [%+v]", x)
+	}
+	if r := x.Results[1]; r.Step != "child-1" || r.Status != 2 || r.Error != "boom" {
+		t.Errorf("child-1 : got [%+v]", r)
+	}
+	info, err := h.WorkflowStatus(workflowID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := info.Steps[len(info.Steps)-1]; s.Name != "join" || s.State != StateSucceeded {
+		t.Errorf("join : got [%+v]", s)
+	}
+
+	if _, err := h.FanOut(nil, Step{Worker: "join"}); err == nil {
+		t.Errorf("no children : expected error")
+	}
+}
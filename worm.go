@@ -24,12 +24,15 @@
 package worm
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -38,18 +41,17 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	"github.com/robfig/cron"
-	uuid "github.com/satori/go.uuid"
 )
 
 // Connect starts a default worm hub.
-func Connect(connectURL, logDir string) error {
+func Connect(connectURL, logDir string, opts ...Option) error {
 	var err error
-	defaultWorm, err = New(connectURL, logDir)
+	defaultWorm, err = New(connectURL, logDir, opts...)
 	return err
 }
 
 // New connects to sqlite database and returns a new Worm hub.
-func New(connectURL, logDir string) (*Worm, error) {
+func New(connectURL, logDir string, opts ...Option) (*Worm, error) {
 	if len(logDir) < 1 {
 		return nil, errors.New("log directory not set")
 	}
@@ -58,19 +60,66 @@ func New(connectURL, logDir string) (*Worm, error) {
 		return nil, err
 	}
 
-	c := cron.New()
 	x := &Worm{
-		doers:  make(map[string]Doer),
-		Db:     db,
-		croner: c,
-		logDir: logDir,
-		waitc:  make(chan struct{}, 1),
+		workers:        make(map[string]*worker),
+		crons:          make(map[string]*cronEntry),
+		Db:             db,
+		croner:         cron.New(),
+		logDir:         logDir,
+		waitc:          make(chan struct{}, 1),
+		quitc:          make(chan struct{}),
+		healthInterval: 30 * time.Second,
+		instanceID:     defaultInstanceID(),
+		lease:          30 * time.Second,
+
+		heartbeatInterval: 10 * time.Second,
+		pollInterval:      time.Second,
+		sweepInterval:     time.Minute,
+		codec:             JSONCodec{},
+		ids:               UUIDGenerator{},
+	}
+	for _, opt := range opts {
+		opt(x)
+	}
+	if x.logSearch {
+		if err := x.initLogSearch(); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	if len(x.replicaURL) > 0 {
+		x.replica, err = sqlx.Connect("sqlite3", x.replicaURL)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
 	}
 	x.waitc <- struct{}{}
-	c.Start()
+	if x.poolSize > 0 {
+		x.startPool(x.poolSize)
+	}
+	x.croner.Start()
+	x.background(x.checkHealth)
+	if !x.manual() {
+		x.background(x.poll)
+		x.background(x.sweep)
+	}
+	x.background(x.heartbeat)
+	if x.election {
+		x.background(x.elect)
+	}
 	return x, nil
 }
 
+// background runs fn on its own goroutine. Close waits for it to return.
+func (h *Worm) background(fn func()) {
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		fn()
+	}()
+}
+
 var (
 	defaultWorm *Worm
 )
@@ -80,119 +129,553 @@ const (
 	StatusStart = 1
 	// StatusOK success status code.
 	StatusOK = 0
+	// StatusWaiting is the status of stored jobs that wait for other jobs
+	// before they are scheduled. Negative statuses are reserved by worm.
+	StatusWaiting = -1
+	// StatusCanceled is the status of jobs canceled before they ran.
+	StatusCanceled = -2
+	// StatusExpired is the status of jobs whose TTL passed before they ran.
+	StatusExpired = -3
 )
 
 // Worm struct.
 type Worm struct {
-	doers  map[string]Doer
-	croner *cron.Cron
-	Db     *sqlx.DB
+	workers map[string]*worker
+	croner  scheduler
+	Db      *sqlx.DB
 
 	// waitc channel make all the database operations without concurrency.
 	// future implementations would have connection pooling.
 	// see: https://godoc.org/github.com/mxk/go-sqlite/sqlite3#hdr-Concurrency
-	waitc  chan struct{}
+	waitc chan struct{}
+	// acks are the run results waiting for waitc.
+	acks   ackBatch
 	logDir string
+
+	blobs         BlobStore
+	blobThreshold int
+	codec         Codec
+	ids           IDGenerator
+	// cronFormat is the layout of the accepted cron specs.
+	cronFormat CronFormat
+	// sinks receive the job logs besides the log file.
+	sinks []LogSink
+	// logSearch indexes the run logs in the fts full-text table.
+	logSearch bool
+	fts       string
+	// shardDir holds the month shards, disabled when empty.
+	shardDir string
+	// replica serves the read queries when not nil.
+	replicaURL string
+	replica    *sqlx.DB
+	// signingKey signs the payloads when not empty.
+	signingKey []byte
+
+	// quitc is closed on Close to stop background loops.
+	quitc          chan struct{}
+	wg             sync.WaitGroup
+	healthInterval time.Duration
+
+	// instanceID identifies this hub on the jobs it claims.
+	instanceID string
+	lease      time.Duration
+	// version of the application, recorded on every run.
+	version string
+	// splay is the upper bound of the recurring jobs delay of this hub.
+	splay time.Duration
+
+	// visibility enables visibility timeout delivery when greater than zero.
+	visibility time.Duration
+
+	heartbeatInterval time.Duration
+	// pollInterval is how often due single executions are looked for.
+	pollInterval time.Duration
+	// sweepInterval is how often Sweep runs, sweeps counts what it did.
+	sweepInterval time.Duration
+	sweeps        sweepCounters
+	// logs bigger than logMinSize are removed logRetention after the job
+	// finished when logRetention is greater than zero.
+	logRetention time.Duration
+	logMinSize   int64
+	// timeout and maxRetries are the worker defaults, see inherit.
+	timeout    time.Duration
+	maxRetries int
+	// clock returns the hub time when set, see now. location is the time
+	// zone of Local.
+	clock    func() time.Time
+	location *time.Location
+
+	// pool runs the jobs on poolSize goroutines when set, fair dispatches
+	// them round-robin across workers.
+	pool     *pool
+	poolSize int
+	fair     bool
+
+	// election enables leader election, leader is 1 while this hub holds
+	// the scheduler lease.
+	election bool
+	leader   int32
+	// paused is 1 while the hub does not start executions.
+	paused int32
+	// maxPending limits the pending jobs of the hub when greater than zero.
+	maxPending int
+	// queues are the worker queues this hub runs, all when nil.
+	queues map[string]bool
+	// schedulerOnly hubs queue the firings of recurring jobs and run none.
+	schedulerOnly bool
+	// onEvent receives the hub events.
+	onEvent func(Event)
+	// crons are the schedules of the recurring jobs stored by this hub.
+	crons map[string]*cronEntry
 	sync.RWMutex
 }
 
 // Register register the worker for this worm. Must be called at init time.
-func (h *Worm) Register(workerName string, doer Doer) error {
+func (h *Worm) Register(workerName string, doer Doer, opts ...WorkerOption) error {
 	if doer == nil {
 		return errors.New("nil worker")
 	}
-	_, ok := h.doers[workerName]
+	wk := &worker{
+		name: workerName,
+		doer: doer,
+		// unset, see inherit.
+		maxRetries: -1,
+	}
+	for _, opt := range opts {
+		if err := opt(wk); err != nil {
+			return err
+		}
+	}
+	h.inherit(wk)
+	wk.paused, wk.pausedUntil = h.workerPaused(workerName)
+	h.Lock()
+	defer h.Unlock()
+	_, ok := h.workers[workerName]
 	if ok {
 		return errors.New("worm: worker already registered")
 	}
-	h.doers[workerName] = doer
+	h.workers[workerName] = wk
 	return nil
 }
 
 // MustRegister register the worker interface for this worm.
-func (h *Worm) MustRegister(workerName string, doer Doer) {
-	err := h.Register(workerName, doer)
+func (h *Worm) MustRegister(workerName string, doer Doer, opts ...WorkerOption) {
+	err := h.Register(workerName, doer, opts...)
 	if err != nil {
 		panic(err)
 	}
 }
 
-// store stores the work data on database.
-func (h *Worm) store(workerName string, data []byte) (Doer, string, error) {
-	h.RLock()
-	doer, ok := h.doers[workerName]
-	h.RUnlock()
-	if !ok {
-		return doer, "", errors.New("worm: doer not found")
+// store stores the work data on database. Returns errDuplicate and the
+// existing job ID when the worker dedup window drops the job.
+func (h *Worm) store(workerName string, data []byte, opts *jobOptions) (Doer, string, error) {
+	if len(opts.semaphore) > 0 && opts.semaphoreMax < 1 {
+		return nil, "", errSemaphore
+	}
+	wk, err := h.accept(workerName, data)
+	if err != nil {
+		return nil, "", err
+	}
+	doer := wk.doer
+
+	jobID := opts.id
+	if len(jobID) < 1 {
+		jobID = h.ids.NewID()
+	}
+
+	status := StatusStart
+	if opts.waiting {
+		status = StatusWaiting
+	}
+
+	now := h.now()
+	if opts.cron == once && opts.runAt.IsZero() {
+		opts.runAt = now
+	}
+	// recurring jobs run on their schedule.
+	var runAt, expiresAt interface{}
+	if opts.cron == once {
+		runAt = opts.runAt
+		if opts.ttl > 0 {
+			expiresAt = opts.runAt.Add(opts.ttl)
+		}
+	}
+	var hash string
+	// workflow steps are never dropped as duplicates.
+	if wk.dedup > 0 && len(opts.workflowID) < 1 {
+		hash = payloadHash(data)
+	}
+
+	conts, err := encodeContinuations(opts.continuations)
+	if err != nil {
+		return doer, "", err
+	}
+
+	if len(opts.traceID) < 1 {
+		opts.traceID = newTraceID()
+	}
+	var scheduleID string
+	if opts.cron != once {
+		scheduleID = opts.scheduleID
+		if len(scheduleID) < 1 {
+			scheduleID = h.ids.NewID()
+		}
 	}
 
-	jobID := uuid.NewV4().String()
+	signature := h.sign(jobID, workerName, data)
+	var blobKey string
+	if h.blobbed(data) {
+		key := newBlobKey(jobID)
+		if err := h.blobs.Put(key, data); err != nil {
+			return doer, "", err
+		}
+		blobKey, data = key, nil
+	}
 
 	o := <-h.waitc
-	_, err := h.Db.Exec(`
-	INSERT INTO worm (id,worker_name,status,data,created_at)
-	VALUES (?,?,?,?,?);
-	`, jobID, workerName, StatusStart, data, time.Now().UTC())
+	var dupID string
+	if len(hash) > 0 {
+		dupID, err = h.duplicate(workerName, hash, now.Add(-wk.dedup))
+	}
+	if err == nil && len(dupID) < 1 && opts.cron == once {
+		err = h.full(wk)
+	}
+	if err == nil && len(dupID) < 1 {
+		_, err = h.dbExec(`
+		INSERT INTO worm (id,worker_name,status,data,blob_key,cron,external_id,payload_hash,workflow_id,step,after_steps,fan_in,group_id,parent_id,continuations,run_at,expires_at,semaphore,semaphore_max,metadata,signature,trace_id,schedule_id,redact,created_at,updated_at)
+		VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?);
+		`, jobID, workerName, status, data, blobKey, opts.cron, opts.externalID, hash,
+			opts.workflowID, opts.step, strings.Join(opts.after, ","), opts.fanIn, opts.groupID, opts.parentID,
+			conts, runAt, expiresAt, opts.semaphore, opts.semaphoreMax, opts.metadata, signature, opts.traceID, scheduleID, redactList(wk), now, now)
+	}
 	h.waitc <- o
+	if len(dupID) > 0 {
+		err = errDuplicate
+		jobID = dupID
+	}
 	if err != nil {
+		if len(blobKey) > 0 {
+			h.blobs.Delete(blobKey)
+		}
+		if err == errDuplicate {
+			return doer, jobID, err
+		}
 		return doer, "", err
 	}
+	h.emit(Event{Type: EventQueued, Worker: workerName, JobID: jobID, TraceID: opts.traceID})
 	return doer, jobID, nil
 }
 
-// Queue will cron the job for execution on cronformat.
-func (h *Worm) Queue(workerName string, data []byte) (string, error) {
-	return h.Sched(workerName, data, nowCron(time.Now()))
+// accept returns the worker if it can take a new job with data.
+func (h *Worm) accept(workerName string, data []byte) (*worker, error) {
+	wk, ok := h.target(workerName)
+	if !ok {
+		return nil, errors.New("worm: doer not found")
+	}
+	if err := wk.healthy(); err != nil {
+		return nil, err
+	}
+	if err := wk.validate(data); err != nil {
+		return nil, err
+	}
+	return wk, nil
+}
+
+// errDuplicate is returned by store for jobs dropped by the dedup window.
+var errDuplicate = errors.New("worm: duplicated job")
+
+// Queue stores the job for a single execution as soon as possible.
+func (h *Worm) Queue(workerName string, data []byte, opts ...JobOption) (string, error) {
+	return h.queue(workerName, data, newJobOptions(once, opts))
+}
+
+// QueueAt stores the job for a single execution at t. Past times run as
+// soon as possible.
+func (h *Worm) QueueAt(workerName string, data []byte, t time.Time, opts ...JobOption) (string, error) {
+	o := newJobOptions(once, opts)
+	o.runAt = t.UTC()
+	return h.queue(workerName, data, o)
 }
 
-// Sched will cron the job for execution on cronformat.
-func (h *Worm) Sched(workerName string, data []byte, cronformat string) (string, error) {
-	doer, jobID, err := h.store(workerName, data)
+// Sched will cron the job for execution on cronformat. Besides cron specs
+// it accepts RRULE recurrence rules and ISO 8601 repeating intervals.
+// Returns the job ID, Detail has the schedule ID.
+func (h *Worm) Sched(workerName string, data []byte, cronformat string, opts ...JobOption) (string, error) {
+	return h.sched(workerName, data, cronformat, newJobOptions(cronformat, opts))
+}
+
+// once marks a job queued for a single execution.
+const once = ""
+
+// sched stores the job and crons its execution on spec.
+func (h *Worm) sched(workerName string, data []byte, spec string, opts *jobOptions) (string, error) {
+	schedule, err := h.schedule(spec, opts)
 	if err != nil {
 		return "", err
 	}
+	if opts.plan != nil {
+		return "", h.dryRun(workerName, data, schedule.Next(h.now()), opts)
+	}
+	doer, jobID, err := h.store(workerName, data, opts)
+	if err == errDuplicate {
+		log.Printf("sched : worker [%s] duplicated job dropped : job id [%s]", workerName, jobID)
+		return jobID, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	// big payloads are not kept in memory, run loads them.
+	if h.blobbed(data) {
+		data = nil
+	}
+	h.cronRun(doer, jobID, data, schedule, opts)
+	return jobID, nil
+}
+
+// schedule parses spec and applies the hub splay and the job window and
+// calendar.
+func (h *Worm) schedule(spec string, opts *jobOptions) (cron.Schedule, error) {
+	schedule, err := h.parseSpec(spec, h.now())
+	if err != nil {
+		return nil, err
+	}
+	schedule = h.splayed(schedule)
+	if opts.window != nil {
+		if !opts.window.valid() {
+			return nil, errWindow
+		}
+		schedule = windowed{schedule: schedule, window: opts.window}
+	}
+	if opts.calendar != nil {
+		schedule = calendared{schedule: schedule, calendar: opts.calendar}
+	}
+	return schedule, nil
+}
 
-	err = h.croner.AddFunc(cronformat, func() {
+// cronRun crons the execution of a stored recurring job on schedule,
+// replacing its previous schedule if any.
+func (h *Worm) cronRun(doer Doer, jobID string, data []byte, schedule cron.Schedule, opts *jobOptions) {
+	e := &cronEntry{schedule: schedule, opts: opts}
+	h.Lock()
+	if prev, ok := h.crons[jobID]; ok {
+		prev.retire()
+	}
+	h.crons[jobID] = e
+	h.Unlock()
+	h.croner.Schedule(e, cron.FuncJob(func() {
+		if e.retired() || !h.IsLeader() {
+			return
+		}
+		e.fire(h.now())
+		if h.schedulerOnly {
+			h.enqueueFiring(jobID)
+			return
+		}
+		if h.pool != nil && !h.manual() {
+			h.pool.push(doer, jobID, data)
+			return
+		}
+		h.run(doer, jobID, data)
+	}))
+}
 
-		// prepare log file.
+// run claims the job and executes it with doer. The job is skipped when
+// another claimer owns it. Nil data is loaded from the database.
+func (h *Worm) run(doer Doer, jobID string, data []byte) {
+	h.runAs(doer, jobID, data, false)
+}
 
-		lName, lOut, err := newLog(h.logDir, doer.Name(), jobID)
+// runAs is run recording if the run is manual.
+func (h *Worm) runAs(doer Doer, jobID string, data []byte, manual bool) {
+	if h.schedulerOnly || h.Paused() {
+		return
+	}
+	if wk, ok := h.workerOf(doer.Name()); ok {
+		if !h.serves(wk) {
+			return
+		}
+		if !wk.acquire(h.now()) {
+			log.Printf("run : worker [%s] at its limits : job id [%s]", wk.name, jobID)
+			return
+		}
+		defer wk.release()
+	}
+	ok, err := h.claim(jobID)
+	if err != nil {
+		log.Printf("run : claim : err [%s] job id [%s]", err, jobID)
+		return
+	}
+	if !ok {
+		h.expireJob(jobID)
+		return
+	}
+	stop := h.keepLease(jobID)
+
+	// firings record when they were due, see Drift.
+	var dueAt time.Time
+	if !manual {
+		h.RLock()
+		if e, ok := h.crons[jobID]; ok {
+			dueAt = e.due()
+		}
+		h.RUnlock()
+	}
+	runID, err := h.startRun(jobID, manual, dueAt)
+	if err != nil {
+		stop()
+		h.release(jobID)
+		log.Printf("run : start run : err [%s] job id [%s]", err, jobID)
+		return
+	}
+	// signed payloads are checked as stored, not as queued.
+	var tampered error
+	if data == nil || len(h.signingKey) > 0 {
+		data, err = h.payload(jobID)
+		if err == ErrTampered {
+			tampered, err = err, nil
+		}
 		if err != nil {
-			log.Printf("run job : err [%s]", err)
+			stop()
+			h.finishRun(runID, StatusStart, err.Error())
+			h.release(jobID)
+			log.Printf("run : load payload : err [%s] job id [%s]", err, jobID)
 			return
 		}
-		defer func() {
-			if err := lOut.Close(); err != nil {
-				log.Printf("run : close log output file : err [%s]", err)
-			}
-		}()
+	}
 
-		var errMsg string
-		status, jobErr := doer.Run(data, lOut)
-		if jobErr != nil {
-			log.Printf("task fail: %s", jobErr)
+	// prepare log file.
 
-			errMsg = fmt.Sprintf("%s", jobErr)
-			Printf(lOut, "ERROR: %s", jobErr)
+	lName, lOut, err := newLog(h.logDir, doer.Name(), jobID, runID)
+	if err != nil {
+		stop()
+		h.finishRun(runID, StatusStart, err.Error())
+		h.release(jobID)
+		log.Printf("run job : err [%s]", err)
+		return
+	}
+	defer func() {
+		if err := lOut.Close(); err != nil {
+			log.Printf("run : close log output file : err [%s]", err)
+		}
+	}()
+
+	ctx, ack := withAck(newJobContext(jobID), jobID, lName)
+	ctx = h.withJobInfo(ctx, jobID)
+	info, _ := FromContext(ctx)
+	// sinks get the trace ID with the JobInfo.
+	if len(info.TraceID) > 0 {
+		Printf(lOut, "trace id: %s", info.TraceID)
+	}
+	out := h.logOutput(lOut, info)
+	h.emit(Event{Type: EventStarted, Worker: doer.Name(), JobID: jobID, TraceID: info.TraceID})
+	var errMsg string
+	var status int
+	var jobErr error
+	if tampered != nil {
+		status, jobErr = StatusTampered, tampered
+	} else if data, jobErr = h.migrate(doer.Name(), data); jobErr != nil {
+		status = StatusDecode
+	} else {
+		ctx = h.withConfig(ctx, doer.Name())
+		var cancel context.CancelFunc
+		ctx, cancel = h.withTimeout(ctx, doer.Name())
+		status, jobErr = perform(ctx, doer, data, out)
+		cancel()
+	}
+	stop()
+	if jobErr != nil {
+		log.Printf("task fail: %s", jobErr)
+
+		errMsg = fmt.Sprintf("%s", jobErr)
+		Printf(out, "ERROR: %s", jobErr)
+	}
+	out.Close()
+	var lSize int64
+	if fi, err := lOut.Stat(); err == nil {
+		lSize = fi.Size()
+	}
+	h.finishRunLog(runID, status, errMsg, lName, lSize)
+	if len(h.fts) > 0 {
+		h.indexLog(runID, lName)
+	}
+	acked, err := h.ackRun(&runResult{
+		jobID:   jobID,
+		status:  status,
+		errMsg:  errMsg,
+		logFile: lName,
+		logSize: lSize,
+	})
+	if err != nil {
+		log.Printf("run : update status : err [%s] job id [%s]", err, jobID)
+		return
+	}
+	if !acked {
+		if !h.committed(ack) {
+			log.Printf("run : ack : err [%s] job id [%s]", ErrConflict, jobID)
+			return
 		}
+		// the Doer committed the ack in its own transaction.
+		status = StatusOK
 		o := <-h.waitc
-		_, err = h.Db.Exec(`
-			UPDATE worm SET status=?,error=?,log_file=? WHERE id=?;
-		`, status, errMsg, lName, jobID)
+		_, err := h.dbExec(`UPDATE worm SET log_size=? WHERE id=?;`, lSize, jobID)
 		h.waitc <- o
 		if err != nil {
-			log.Printf("Sched : update status : err [%s] job id [%s]", err, jobID)
+			log.Printf("run : update log size : err [%s] job id [%s]", err, jobID)
 		}
-	})
+	}
+	if h.retryFailed(doer, jobID, status, info.Attempt) {
+		return
+	}
+	h.finish(jobID, status)
+}
+
+// finish runs the completion hooks of an acked job.
+func (h *Worm) finish(jobID string, status int) {
+	var job struct {
+		Worker        string `db:"worker_name"`
+		Error         string `db:"error"`
+		WorkflowID    string `db:"workflow_id"`
+		Continuations string `db:"continuations"`
+		TraceID       string `db:"trace_id"`
+	}
+	o := <-h.waitc
+	err := h.dbGet(&job, `
+		SELECT
+			worker_name,
+			IFNULL(error,'') AS "error",
+			IFNULL(workflow_id,'') AS "workflow_id",
+			IFNULL(continuations,'') AS "continuations",
+			IFNULL(trace_id,'') AS "trace_id"
+		FROM worm WHERE id=?;
+	`, jobID)
+	h.waitc <- o
 	if err != nil {
-		return "", err
+		log.Printf("finish : select : err [%s] job id [%s]", err, jobID)
+		return
+	}
+	e := Event{Type: EventSucceeded, Worker: job.Worker, JobID: jobID, Status: status, TraceID: job.TraceID}
+	if status != StatusOK {
+		e.Type, e.Error = EventFailed, job.Error
+	}
+	h.emit(e)
+	if status != StatusOK {
+		h.compensate(jobID, job.Worker, job.WorkflowID, status, job.Error)
+		h.fallback(jobID, job.Worker)
+	}
+	h.countFailure(jobID, job.Worker, status, job.Error)
+	h.continueWith(jobID, status, job.Continuations)
+	// failed steps can release fan in steps too.
+	if len(job.WorkflowID) > 0 {
+		if err := h.advance(job.WorkflowID); err != nil {
+			log.Printf("finish : advance workflow : err [%s] job id [%s]", err, jobID)
+		}
 	}
-	return jobID, nil
 }
 
-// newLog generates a log output for job. Must be closed.
-func newLog(dir string, workerName, jobID string) (string, *os.File, error) {
-	fname := filepath.Clean(fmt.Sprintf("%s/%s_%s.log", dir, workerName, jobID))
+// newLog generates the log output of a run of job. Every run gets its own
+// log so the firings of recurring jobs keep their output. Must be closed.
+func newLog(dir string, workerName, jobID string, runID int64) (string, *os.File, error) {
+	fname := filepath.Clean(fmt.Sprintf("%s/%s_%s_%d.log", dir, workerName, jobID, runID))
 	f, err := os.Create(fname)
 	if err != nil {
 		return fname, nil, err
@@ -201,26 +684,87 @@ func newLog(dir string, workerName, jobID string) (string, *os.File, error) {
 }
 
 // Detail return the job detail by id.
-func (h *Worm) Detail(ID string) (*Job, error) {
+func (h *Worm) Detail(ID string, opts ...DetailOption) (*Job, error) {
+	var x detailOptions
+	for _, opt := range opts {
+		opt(&x)
+	}
 	var d Job
-	o := <-h.waitc
-	err := h.Db.Get(&d, `
+	err := h.readDB(func(db *sqlx.DB) error {
+		return readDetail(db, &d, ID)
+	})
+	if err == sql.ErrNoRows {
+		// finished jobs of past months may live in a shard.
+		err = h.eachShard(time.Time{}, time.Time{}, func(db *sqlx.DB) (bool, error) {
+			err := readDetail(db, &d, ID)
+			if err == sql.ErrNoRows {
+				return true, nil
+			}
+			return false, err
+		})
+		if err == nil && len(d.ID) < 1 {
+			err = sql.ErrNoRows
+		}
+	}
+	if err == nil && len(d.Cron) > 0 {
+		err = h.scheduleInfo(&d)
+	}
+	if err != nil {
+		log.Printf("job err [%s]", err)
+		return nil, err
+	}
+	h.redactJobs(&d)
+	// jobs not run yet or whose log was removed have no excerpt.
+	if x.excerpt > 0 && len(d.LogFile) > 0 {
+		d.LogHead, d.LogTail, err = readExcerpt(d.LogFile, x.excerpt)
+		if err != nil && !os.IsNotExist(err) {
+			log.Printf("Detail : log excerpt : err [%s] job id [%s]", err, ID)
+		}
+	}
+	return &d, nil
+}
+
+// readDetail reads the job and its children, audit and notes from db.
+func readDetail(db *sqlx.DB, d *Job, ID string) error {
+	err := db.Get(d, `
 		SELECT
 			id,
 			worker_name,
 			status,
 			IFNULL(error,'') AS "error",
-			data,
+			IFNULL(data,'') AS "data",
+			IFNULL(blob_key,'') AS "blob_key",
+			IFNULL(external_id,'') AS "external_id",
+			IFNULL(parent_id,'') AS "parent_id",
+			IFNULL(trace_id,'') AS "trace_id",
+			IFNULL(schedule_id,'') AS "schedule_id",
+			IFNULL(cron,'') AS "cron",
+			IFNULL(redact,'') AS "redact",
+			(SELECT COUNT(*) FROM worm_run WHERE worm_run.job_id=worm.id) AS "attempts",
+			metadata,
 			log_file,
-			created_at
-		FROM worm WHERE id=?;
+			created_at,
+			updated_at
+		FROM worm WHERE id=? AND deleted_at IS NULL;
 	`, ID)
-	h.waitc <- o
-	if err != nil {
-		log.Printf("job err [%s]", err)
-		return nil, err
+	if err == nil {
+		err = db.Select(&d.Children, `
+			SELECT id FROM worm WHERE parent_id=? AND deleted_at IS NULL
+			ORDER BY created_at, rowid;
+		`, ID)
 	}
-	return &d, nil
+	if err == nil {
+		err = db.Select(&d.Audit, `
+			SELECT status, IFNULL(reason,'') AS "reason", created_at
+			FROM worm_audit WHERE job_id=? ORDER BY id;
+		`, ID)
+	}
+	if err == nil {
+		err = db.Select(&d.Notes, `
+			SELECT text, created_at FROM worm_note WHERE job_id=? ORDER BY id;
+		`, ID)
+	}
+	return err
 }
 
 // CopyLog return the job detail by id.
@@ -228,7 +772,7 @@ func (h *Worm) CopyLog(w io.Writer, jobID string) error {
 	var name string
 
 	o := <-h.waitc
-	err := h.Db.Get(&name, `
+	err := h.dbGet(&name, `
 		SELECT log_file FROM worm WHERE id=?;
 	`, jobID)
 	h.waitc <- o
@@ -236,6 +780,14 @@ func (h *Worm) CopyLog(w io.Writer, jobID string) error {
 		log.Printf("CopyLog : locate : err [%s]", err)
 		return err
 	}
+	return copyLog(w, name)
+}
+
+// copyLog copies the log file name to w.
+func copyLog(w io.Writer, name string) error {
+	if len(name) < 1 {
+		return ErrNoLog
+	}
 	f, err := os.Open(name)
 	if err != nil {
 		return err
@@ -252,68 +804,142 @@ func (h *Worm) CopyLog(w io.Writer, jobID string) error {
 	return nil
 }
 
-// Close close database connections.
+// Close stops the scheduler and close database connections.
 func (h *Worm) Close() error {
+	close(h.quitc)
+	h.wg.Wait()
+	h.croner.Stop()
+	if h.replica != nil {
+		if err := h.replica.Close(); err != nil {
+			log.Printf("Close : replica : err [%s]", err)
+		}
+	}
 	return h.Db.Close()
 }
 
 // Register _
-func Register(workerName string, doer Doer) error {
-	return defaultWorm.Register(workerName, doer)
+func Register(workerName string, doer Doer, opts ...WorkerOption) error {
+	return defaultWorm.Register(workerName, doer, opts...)
 }
 
 // MustRegister _
-func MustRegister(workerName string, doer Doer) {
-	defaultWorm.MustRegister(workerName, doer)
+func MustRegister(workerName string, doer Doer, opts ...WorkerOption) {
+	defaultWorm.MustRegister(workerName, doer, opts...)
 }
 
 // Queue _
-func Queue(workerName string, data []byte) (string, error) {
-	return defaultWorm.Queue(workerName, data)
+func Queue(workerName string, data []byte, opts ...JobOption) (string, error) {
+	return defaultWorm.Queue(workerName, data, opts...)
+}
+
+// QueueAt _
+func QueueAt(workerName string, data []byte, t time.Time, opts ...JobOption) (string, error) {
+	return defaultWorm.QueueAt(workerName, data, t, opts...)
 }
 
 // Sched _
-func Sched(workerName string, data []byte, cronformat string) (string, error) {
-	return defaultWorm.Sched(workerName, data, cronformat)
+func Sched(workerName string, data []byte, cronformat string, opts ...JobOption) (string, error) {
+	return defaultWorm.Sched(workerName, data, cronformat, opts...)
 }
 
 // Detail _
-func Detail(ID string) (*Job, error) {
-	return defaultWorm.Detail(ID)
+func Detail(ID string, opts ...DetailOption) (*Job, error) {
+	return defaultWorm.Detail(ID, opts...)
 }
 
 // Job struct for database query.
 type Job struct {
-	ID        string    `db:"id" json:"id"`
-	Worker    string    `db:"worker_name" json:"worker_name"`
-	Status    int       `db:"status" json:"status"`
-	Error     string    `db:"error" json:"error"`
-	LogFile   string    `db:"log_file" json:"log_file"`
-	Data      string    `db:"data" json:"data"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	ID         string    `db:"id" json:"id"`
+	Worker     string    `db:"worker_name" json:"worker_name"`
+	Status     int       `db:"status" json:"status"`
+	Error      string    `db:"error" json:"error"`
+	LogFile    string    `db:"log_file" json:"log_file"`
+	Data       string    `db:"data" json:"data"`
+	BlobKey    string    `db:"blob_key" json:"blob_key,omitempty"`
+	ExternalID string    `db:"external_id" json:"external_id,omitempty"`
+	ParentID   string    `db:"parent_id" json:"parent_id,omitempty"`
+	TraceID    string    `db:"trace_id" json:"trace_id,omitempty"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	// ScheduleID identifies recurring jobs as schedules, see Schedule.
+	ScheduleID string `db:"schedule_id" json:"schedule_id,omitempty"`
+	// UpdatedAt is the time of the last status change.
+	UpdatedAt *time.Time `db:"updated_at" json:"updated_at,omitempty"`
+	// Cron is the schedule of recurring jobs, empty for single executions.
+	Cron string `db:"cron" json:"cron,omitempty"`
+	// Attempts is the number of executions of the job, retries and
+	// recurring firings included.
+	Attempts int `db:"attempts" json:"attempts"`
+	// Metadata are the labels set with the Metadata option. Only set by
+	// Detail.
+	Metadata JobMetadata `db:"metadata" json:"metadata,omitempty"`
+	// Redact lists, comma separated, the data fields masked by the Redact
+	// option of the worker.
+	Redact string `db:"redact" json:"redact,omitempty"`
+	// Children are the IDs of the jobs queued by this one. Only set by
+	// Detail.
+	Children []string `db:"-" json:"children,omitempty"`
+	// Audit lists the manual status changes. Only set by Detail.
+	Audit []*AuditEntry `db:"-" json:"audit,omitempty"`
+	// Notes are the operator notes of the job. Only set by Detail.
+	Notes []*Note `db:"-" json:"notes,omitempty"`
+	// LogHead and LogTail are the start and end of the log. Only set by
+	// Detail with LogExcerpt.
+	LogHead string `db:"-" json:"log_head,omitempty"`
+	LogTail string `db:"-" json:"log_tail,omitempty"`
+	// NextRun is the next firing of recurring jobs, nil when disabled.
+	// Only set by Detail.
+	NextRun *time.Time `db:"-" json:"next_run,omitempty"`
+	// LastRun is the latest execution of recurring jobs. Only set by Detail.
+	LastRun *Run `db:"-" json:"last_run,omitempty"`
 }
 
 // Query _
 func Query(before, after time.Time, limit int) ([]*Job, error) {
-	db := DB()
-	var jobs []*Job
-	o := <-defaultWorm.waitc
-	err := db.Select(&jobs, `
+	return defaultWorm.query(before, after, limit)
+}
+
+// query returns up to limit jobs created from before to after, both
+// included, reading the month shards when the main database has fewer.
+func (h *Worm) query(before, after time.Time, limit int) ([]*Job, error) {
+	q := `
 	SELECT
 		id,
 		worker_name,
 		status,
 		IFNULL(error,'') AS "error",
 		log_file,
-		data,
-		created_at
+		IFNULL(data,'') AS "data",
+		IFNULL(blob_key,'') AS "blob_key",
+		IFNULL(external_id,'') AS "external_id",
+		IFNULL(parent_id,'') AS "parent_id",
+		IFNULL(cron,'') AS "cron",
+		IFNULL(redact,'') AS "redact",
+		(SELECT COUNT(*) FROM worm_run WHERE worm_run.job_id=worm.id) AS "attempts",
+		created_at,
+		updated_at
 	FROM worm
-	WHERE created_at BETWEEN ? AND ? LIMIT ?;
-	`, before.Format(time.RFC3339)[:10], after.Format(time.RFC3339)[:10], limit)
-	defaultWorm.waitc <- o
+	WHERE created_at BETWEEN ? AND ? AND deleted_at IS NULL LIMIT ?;
+	`
+	// stored times are UTC, see WithClock.
+	from, to := before.UTC(), after.UTC()
+	var jobs []*Job
+	err := h.readDB(func(db *sqlx.DB) error {
+		return db.Select(&jobs, q, from, to, limit)
+	})
+	if err == nil && len(jobs) < limit {
+		err = h.eachShard(before, after, func(db *sqlx.DB) (bool, error) {
+			var more []*Job
+			err := retryBusy(func() error {
+				return db.Select(&more, q, from, to, limit-len(jobs))
+			})
+			jobs = append(jobs, more...)
+			return len(jobs) < limit, err
+		})
+	}
 	if err != nil {
 		log.Printf("Query : retrieve : err [%s]", err)
 	}
+	h.redactJobs(jobs...)
 	return jobs, err
 }
 
@@ -346,12 +972,6 @@ type Doer interface {
 	Run(data []byte, logOutput io.Writer) (state int, err error)
 }
 
-// nowCron return cron string with t date. Once execution.
-func nowCron(t time.Time) string {
-	t = t.Add(1 * time.Second)
-	return fmt.Sprintf("%v %v %v %v %v *", t.Second(), t.Minute(), t.Hour(), t.Day(), int(t.Month()))
-}
-
 // Printf convenience.
 func Printf(w io.Writer, format string, args ...interface{}) {
 	fmt.Fprintf(w, format+"\n", args...)
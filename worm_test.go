@@ -1,6 +1,66 @@
 package worm
 
-import "testing"
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
 
 func TestNew(t *testing.T) {
 }
+
+// newTestWorm returns a worm on a temporary database with all the
+// migrations applied.
+func newTestWorm(t *testing.T, opts ...Option) *Worm {
+	dir, err := ioutil.TempDir("", "worm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := New(filepath.Join(dir, "worm.db"), dir, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ups, err := filepath.Glob("migration/*.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(ups)
+	for _, name := range ups {
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := h.Db.Exec(string(b)); err != nil {
+			t.Fatalf("migration %s : err [%s]", name, err)
+		}
+	}
+	return h
+}
+
+// closeTestWorm closes h and removes its log directory.
+func closeTestWorm(t *testing.T, h *Worm) {
+	if err := h.Close(); err != nil {
+		t.Errorf("close : err [%s]", err)
+	}
+	if err := os.RemoveAll(h.logDir); err != nil {
+		t.Errorf("remove : err [%s]", err)
+	}
+}
+
+// testDoer is a Doer for tests.
+type testDoer struct {
+	name   string
+	status int
+	err    error
+}
+
+func (d *testDoer) Name() string {
+	return d.name
+}
+
+func (d *testDoer) Run(data []byte, w io.Writer) (int, error) {
+	return d.status, d.err
+}
@@ -0,0 +1,80 @@
+// Package wormamqp consumes AMQP messages as worm jobs, so RabbitMQ
+// producers can target worm without changes.
+//
+//	c := wormamqp.NewConsumer(hub, map[string]string{
+//		"order.created": "invoice",
+//		"user.signup":   "mailer",
+//	})
+//	err := c.Consume(ch, "worm", 32)
+//
+// A message is acked only after its job is stored in the database, so a
+// crash between delivery and insert redelivers it.
+package wormamqp
+
+import (
+	"log"
+
+	worm "github.com/jimmy-go/worm.io"
+	"github.com/streadway/amqp"
+)
+
+// Consumer queues AMQP deliveries as jobs of the worker mapped to their
+// routing key.
+type Consumer struct {
+	hub    worm.Enqueuer
+	routes map[string]string
+}
+
+// NewConsumer returns a Consumer with routes mapping routing keys to
+// workers.
+func NewConsumer(hub worm.Enqueuer, routes map[string]string) *Consumer {
+	return &Consumer{
+		hub:    hub,
+		routes: routes,
+	}
+}
+
+// Consume queues the deliveries of the queue until the channel closes.
+// prefetch limits the unacked deliveries held at once.
+func (c *Consumer) Consume(ch *amqp.Channel, queue string, prefetch int) error {
+	if err := ch.Qos(prefetch, 0, false); err != nil {
+		return err
+	}
+	deliveries, err := ch.Consume(queue, "", false, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+	for d := range deliveries {
+		c.handle(d)
+	}
+	return nil
+}
+
+// handle queues the delivery and acks it. Deliveries without route are
+// rejected, to the dead letter exchange if any. Deliveries that fail to queue
+// are requeued.
+func (c *Consumer) handle(d amqp.Delivery) {
+	workerName, ok := c.routes[d.RoutingKey]
+	if !ok {
+		log.Printf("wormamqp : no route : routing key [%s]", d.RoutingKey)
+		if err := d.Reject(false); err != nil {
+			log.Printf("wormamqp : reject : err [%s]", err)
+		}
+		return
+	}
+	var opts []worm.JobOption
+	if len(d.MessageId) > 0 {
+		opts = append(opts, worm.ExternalID(d.MessageId))
+	}
+	if _, err := c.hub.Queue(workerName, d.Body, opts...); err != nil {
+		log.Printf("wormamqp : queue : err [%s] routing key [%s] worker [%s]", err, d.RoutingKey, workerName)
+		if err := d.Nack(false, true); err != nil {
+			log.Printf("wormamqp : nack : err [%s]", err)
+		}
+		return
+	}
+	if err := d.Ack(false); err != nil {
+		// the delivery comes back, the job has its message ID as external ID.
+		log.Printf("wormamqp : ack : err [%s] routing key [%s]", err, d.RoutingKey)
+	}
+}
@@ -0,0 +1,71 @@
+package wormamqp
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	worm "github.com/jimmy-go/worm.io"
+	"github.com/jimmy-go/worm.io/wormtest"
+	"github.com/streadway/amqp"
+)
+
+type invoice struct{}
+
+func (d *invoice) Name() string {
+	return "invoice"
+}
+
+func (d *invoice) Run(data []byte, w io.Writer) (int, error) {
+	return worm.StatusOK, nil
+}
+
+// acks records the acknowledgements by delivery tag.
+type acks map[uint64]string
+
+func (a acks) Ack(tag uint64, multiple bool) error {
+	a[tag] = "ack"
+	return nil
+}
+
+func (a acks) Nack(tag uint64, multiple bool, requeue bool) error {
+	a[tag] = fmt.Sprintf("nack requeue [%v]", requeue)
+	return nil
+}
+
+func (a acks) Reject(tag uint64, requeue bool) error {
+	a[tag] = fmt.Sprintf("reject requeue [%v]", requeue)
+	return nil
+}
+
+func TestHandle(t *testing.T) {
+	hub := wormtest.New()
+	hub.MustRegister("invoice", &invoice{})
+	c := NewConsumer(hub, map[string]string{
+		"order.created": "invoice",
+		"user.signup":   "mailer",
+	})
+	a := acks{}
+	table := []struct {
+		Key  string
+		Want string
+	}{
+		{"order.created", "ack"},
+		{"order.unknown", "reject requeue [false]"},
+		// mailer is not registered on the hub.
+		{"user.signup", "nack requeue [true]"},
+	}
+	for i, x := range table {
+		tag := uint64(i + 1)
+		c.handle(amqp.Delivery{
+			Acknowledger: a,
+			DeliveryTag:  tag,
+			RoutingKey:   x.Key,
+			Body:         []byte(`{"id":1}`),
+		})
+		if a[tag] != x.Want {
+			t.Errorf("%s : expected [%s] got [%s]", x.Key, x.Want, a[tag])
+		}
+	}
+	hub.AssertEnqueued(t, "invoice", wormtest.JSONEq(`{"id":1}`))
+}
@@ -0,0 +1,45 @@
+// Package wormcodec contains worm.Codec implementations for protobuf and
+// msgpack payloads.
+package wormcodec
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/vmihailenco/msgpack"
+)
+
+// Proto is a worm.Codec for protobuf messages. Values must implement
+// proto.Message.
+type Proto struct{}
+
+// Marshal implements worm.Codec.
+func (Proto) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("wormcodec: %T is not a proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+// Unmarshal implements worm.Codec.
+func (Proto) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("wormcodec: %T is not a proto.Message", v)
+	}
+	return proto.Unmarshal(data, m)
+}
+
+// Msgpack is a worm.Codec for msgpack payloads.
+type Msgpack struct{}
+
+// Marshal implements worm.Codec.
+func (Msgpack) Marshal(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+// Unmarshal implements worm.Codec.
+func (Msgpack) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
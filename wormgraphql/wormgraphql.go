@@ -0,0 +1,336 @@
+// Package wormgraphql serves a read only GraphQL endpoint over the jobs, runs
+// and schedules of a worm hub. Every field of the Query type is checked by
+// the wormhttp Authorizer of the handler with Read access.
+//
+//	schema, err := wormgraphql.New(hub, wormgraphql.WithAuthorizer(auth))
+//	http.Handle("/graphql", schema)
+//
+// Lists are paged with first and after, passing the cursor of the previous
+// page:
+//
+//	{
+//	  jobs(worker: "mailer", first: 20) {
+//	    jobs { id status createdAt runs { status error } }
+//	    cursor
+//	    hasMore
+//	  }
+//	}
+package wormgraphql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	worm "github.com/jimmy-go/worm.io"
+	"github.com/jimmy-go/worm.io/wormhttp"
+)
+
+// Page sizes of the list queries.
+const (
+	defaultFirst = 20
+	maxFirst     = 500
+)
+
+// Page is a page of jobs.
+type Page struct {
+	Jobs []*worm.Job
+	// Cursor is the after argument of the next page.
+	Cursor string
+	// HasMore is true when there are jobs after this page.
+	HasMore bool
+}
+
+// Option configures a Handler.
+type Option func(*Handler)
+
+// WithAuthorizer checks the fields of every query with a, as the wormhttp
+// routes do. Without it every query is allowed.
+func WithAuthorizer(a wormhttp.Authorizer) Option {
+	return func(x *Handler) {
+		x.auth = a
+	}
+}
+
+// Handler serves GraphQL queries over the jobs of a hub.
+type Handler struct {
+	hub    *worm.Worm
+	schema graphql.Schema
+	auth   wormhttp.Authorizer
+}
+
+// New returns the GraphQL Handler of hub.
+func New(hub *worm.Worm, opts ...Option) (*Handler, error) {
+	x := &Handler{hub: hub}
+	for _, opt := range opts {
+		opt(x)
+	}
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: x.query(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	x.schema = schema
+	return x, nil
+}
+
+// query returns the root query type.
+func (x *Handler) query() *graphql.Object {
+	run := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Run",
+		Fields: graphql.Fields{
+			"id":         runField(graphql.Int, func(r *worm.Run) interface{} { return r.ID }),
+			"jobId":      runField(graphql.String, func(r *worm.Run) interface{} { return r.JobID }),
+			"instance":   runField(graphql.String, func(r *worm.Run) interface{} { return r.Instance }),
+			"status":     runField(graphql.Int, func(r *worm.Run) interface{} { return r.Status }),
+			"error":      runField(graphql.String, func(r *worm.Run) interface{} { return r.Error }),
+			"startedAt":  runField(graphql.DateTime, func(r *worm.Run) interface{} { return r.StartedAt }),
+			"finishedAt": runField(graphql.DateTime, func(r *worm.Run) interface{} { return r.FinishedAt }),
+			"version":    runField(graphql.String, func(r *worm.Run) interface{} { return r.Version }),
+		},
+	})
+	job := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Job",
+		Fields: graphql.Fields{
+			"id":         jobField(graphql.String, func(j *worm.Job) interface{} { return j.ID }),
+			"worker":     jobField(graphql.String, func(j *worm.Job) interface{} { return j.Worker }),
+			"status":     jobField(graphql.Int, func(j *worm.Job) interface{} { return j.Status }),
+			"error":      jobField(graphql.String, func(j *worm.Job) interface{} { return j.Error }),
+			"data":       jobField(graphql.String, func(j *worm.Job) interface{} { return j.Data }),
+			"externalId": jobField(graphql.String, func(j *worm.Job) interface{} { return j.ExternalID }),
+			"parentId":   jobField(graphql.String, func(j *worm.Job) interface{} { return j.ParentID }),
+			"cron":       jobField(graphql.String, func(j *worm.Job) interface{} { return j.Cron }),
+			"attempts":   jobField(graphql.Int, func(j *worm.Job) interface{} { return j.Attempts }),
+			"createdAt":  jobField(graphql.DateTime, func(j *worm.Job) interface{} { return j.CreatedAt }),
+			"updatedAt":  jobField(graphql.DateTime, func(j *worm.Job) interface{} { return j.UpdatedAt }),
+			"runs": &graphql.Field{
+				Type: graphql.NewList(run),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return x.hub.Runs(p.Source.(*worm.Job).ID)
+				},
+			},
+		},
+	})
+	page := graphql.NewObject(graphql.ObjectConfig{
+		Name: "JobPage",
+		Fields: graphql.Fields{
+			"jobs": &graphql.Field{
+				Type: graphql.NewList(job),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*Page).Jobs, nil
+				},
+			},
+			"cursor": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*Page).Cursor, nil
+				},
+			},
+			"hasMore": &graphql.Field{
+				Type: graphql.Boolean,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*Page).HasMore, nil
+				},
+			},
+		},
+	})
+	pageArgs := graphql.FieldConfigArgument{
+		"worker":     &graphql.ArgumentConfig{Type: graphql.String},
+		"group":      &graphql.ArgumentConfig{Type: graphql.String},
+		"externalId": &graphql.ArgumentConfig{Type: graphql.String},
+		"after":      &graphql.ArgumentConfig{Type: graphql.String},
+		"first":      &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultFirst},
+	}
+	jobsArgs := graphql.FieldConfigArgument{
+		"createdAfter":  &graphql.ArgumentConfig{Type: graphql.DateTime},
+		"createdBefore": &graphql.ArgumentConfig{Type: graphql.DateTime},
+	}
+	for k, v := range pageArgs {
+		jobsArgs[k] = v
+	}
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"job": &graphql.Field{
+				Type: job,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: x.guard("job", wormhttp.Read, func(p graphql.ResolveParams) (interface{}, error) {
+					id, _ := p.Args["id"].(string)
+					j, err := x.hub.Detail(id)
+					if err == sql.ErrNoRows {
+						return nil, nil
+					}
+					return j, err
+				}),
+			},
+			"jobs": &graphql.Field{
+				Type: page,
+				Args: jobsArgs,
+				Resolve: x.guard("jobs", wormhttp.Read, func(p graphql.ResolveParams) (interface{}, error) {
+					return x.page(filter(p.Args), first(p.Args))
+				}),
+			},
+			"schedules": &graphql.Field{
+				Type: page,
+				Args: pageArgs,
+				Resolve: x.guard("schedules", wormhttp.Read, func(p graphql.ResolveParams) (interface{}, error) {
+					f := filter(p.Args)
+					f.Recurring = true
+					return x.page(f, first(p.Args))
+				}),
+			},
+			"runs": &graphql.Field{
+				Type: graphql.NewList(run),
+				Args: graphql.FieldConfigArgument{
+					"jobId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: x.guard("runs", wormhttp.Read, func(p graphql.ResolveParams) (interface{}, error) {
+					id, _ := p.Args["jobId"].(string)
+					return x.hub.Runs(id)
+				}),
+			},
+		},
+	})
+}
+
+// requestKey is the context key of the HTTP request of a query.
+type requestKey struct{}
+
+// guard returns fn resolving the field only for requests the authorizer
+// grants access to. Queries run without the HTTP request are denied.
+func (x *Handler) guard(field string, access wormhttp.Access, fn graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		if x.auth == nil {
+			return fn(p)
+		}
+		var r *http.Request
+		if p.Context != nil {
+			r, _ = p.Context.Value(requestKey{}).(*http.Request)
+		}
+		if r == nil {
+			return nil, wormhttp.ErrForbidden
+		}
+		if err := x.auth.Authorize(r, access); err != nil {
+			log.Printf("wormgraphql : authorize : err [%s] field [%s] access [%s]", err, field, access)
+			return nil, wormhttp.ErrForbidden
+		}
+		return fn(p)
+	}
+}
+
+// jobField returns a field of the Job type resolved by fn.
+func jobField(t graphql.Output, fn func(*worm.Job) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: t,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return fn(p.Source.(*worm.Job)), nil
+		},
+	}
+}
+
+// runField returns a field of the Run type resolved by fn.
+func runField(t graphql.Output, fn func(*worm.Run) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: t,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return fn(p.Source.(*worm.Run)), nil
+		},
+	}
+}
+
+// filter returns the job filter of the query arguments.
+func filter(args map[string]interface{}) worm.JobFilter {
+	var f worm.JobFilter
+	f.Worker, _ = args["worker"].(string)
+	f.Group, _ = args["group"].(string)
+	f.ExternalID, _ = args["externalId"].(string)
+	f.Cursor, _ = args["after"].(string)
+	f.CreatedAfter, _ = args["createdAfter"].(time.Time)
+	f.CreatedBefore, _ = args["createdBefore"].(time.Time)
+	return f
+}
+
+// first returns the page size of the query arguments.
+func first(args map[string]interface{}) int {
+	n, _ := args["first"].(int)
+	if n < 1 {
+		return defaultFirst
+	}
+	if n > maxFirst {
+		return maxFirst
+	}
+	return n
+}
+
+// page returns up to n jobs matching f.
+func (x *Handler) page(f worm.JobFilter, n int) (*Page, error) {
+	// one more job tells if there is a next page.
+	jobs, err := x.hub.Jobs(f, n+1)
+	if err != nil {
+		return nil, err
+	}
+	return newPage(jobs, n), nil
+}
+
+// newPage returns the page of the first n jobs.
+func newPage(jobs []*worm.Job, n int) *Page {
+	p := &Page{Jobs: jobs}
+	if len(jobs) > n {
+		p.Jobs, p.HasMore = jobs[:n], true
+	}
+	if len(p.Jobs) > 0 {
+		p.Cursor = p.Jobs[len(p.Jobs)-1].ID
+	}
+	return p
+}
+
+// request is a GraphQL request.
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// ServeHTTP implements http.Handler. It takes the query as JSON body of POST
+// requests or as query parameter of GET requests.
+func (x *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	switch r.Method {
+	case http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if s := r.URL.Query().Get("variables"); len(s) > 0 {
+			if err := json.Unmarshal([]byte(s), &req.Variables); err != nil {
+				http.Error(w, "invalid variables", http.StatusBadRequest)
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	res := graphql.Do(graphql.Params{
+		Schema:         x.schema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		Context:        context.WithValue(r.Context(), requestKey{}, r),
+	})
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		log.Printf("wormgraphql : encode : err [%s]", err)
+	}
+}
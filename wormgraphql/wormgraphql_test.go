@@ -0,0 +1,97 @@
+package wormgraphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	worm "github.com/jimmy-go/worm.io"
+	"github.com/jimmy-go/worm.io/wormhttp"
+)
+
+func TestNewPage(t *testing.T) {
+	jobs := []*worm.Job{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	table := []struct {
+		N       int
+		Len     int
+		Cursor  string
+		HasMore bool
+	}{
+		{2, 2, "b", true},
+		{3, 3, "c", false},
+		{5, 3, "c", false},
+	}
+	for _, x := range table {
+		p := newPage(jobs, x.N)
+		if len(p.Jobs) != x.Len || p.Cursor != x.Cursor || p.HasMore != x.HasMore {
+			t.Errorf("n [%d] : got len [%d] cursor [%s] has more [%v]", x.N, len(p.Jobs), p.Cursor, p.HasMore)
+		}
+	}
+	if p := newPage(nil, 2); p.Cursor != "" || p.HasMore {
+		t.Errorf("empty : got [%+v]", p)
+	}
+}
+
+func TestArgs(t *testing.T) {
+	after := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := filter(map[string]interface{}{
+		"worker":       "mailer",
+		"after":        "job-1",
+		"createdAfter": after,
+	})
+	if f.Worker != "mailer" || f.Cursor != "job-1" || !f.CreatedAfter.Equal(after) {
+		t.Errorf("filter : got [%+v]", f)
+	}
+	table := map[int]int{0: defaultFirst, -1: defaultFirst, 10: 10, maxFirst + 1: maxFirst}
+	for n, want := range table {
+		if got := first(map[string]interface{}{"first": n}); got != want {
+			t.Errorf("first [%d] : expected [%d] got [%d]", n, want, got)
+		}
+	}
+}
+
+func TestGuard(t *testing.T) {
+	role := func(r *http.Request) (wormhttp.Access, bool) {
+		switch r.Header.Get("X-Role") {
+		case "viewer":
+			return wormhttp.Read, true
+		}
+		return 0, false
+	}
+	resolve := func(p graphql.ResolveParams) (interface{}, error) {
+		return "ok", nil
+	}
+	request := func(role string) context.Context {
+		r := httptest.NewRequest(http.MethodGet, "/graphql", nil)
+		if len(role) > 0 {
+			r.Header.Set("X-Role", role)
+		}
+		return context.WithValue(context.Background(), requestKey{}, r)
+	}
+	table := []struct {
+		Auth    wormhttp.Authorizer
+		Context context.Context
+		Allowed bool
+	}{
+		{nil, context.Background(), true},
+		{wormhttp.Roles(role), request("viewer"), true},
+		{wormhttp.Roles(role), request(""), false},
+		{wormhttp.Roles(role), context.Background(), false},
+	}
+	for i, x := range table {
+		h := &Handler{}
+		if x.Auth != nil {
+			WithAuthorizer(x.Auth)(h)
+		}
+		v, err := h.guard("jobs", wormhttp.Read, resolve)(graphql.ResolveParams{Context: x.Context})
+		if x.Allowed && (err != nil || v != "ok") {
+			t.Errorf("case [%d] : expected allowed got [%v] err [%v]", i, v, err)
+		}
+		if !x.Allowed && (err != wormhttp.ErrForbidden || v != nil) {
+			t.Errorf("case [%d] : expected forbidden got [%v] err [%v]", i, v, err)
+		}
+	}
+}
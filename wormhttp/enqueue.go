@@ -0,0 +1,175 @@
+package wormhttp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	worm "github.com/jimmy-go/worm.io"
+)
+
+// Enqueue request headers.
+const (
+	// KeyHeader carries the producer key.
+	KeyHeader = "X-Worm-Key"
+	// TimestampHeader carries the Unix time of signed requests.
+	TimestampHeader = "X-Worm-Timestamp"
+	// SignatureHeader carries the hex HMAC of signed requests, see Sign.
+	SignatureHeader = "X-Worm-Signature"
+	// TraceHeader carries the request ID stored as the job trace ID.
+	TraceHeader = "X-Request-ID"
+)
+
+// maxSkew is how old or ahead a signed request can be, limiting replays.
+const maxSkew = 5 * time.Minute
+
+// maxPayload limits the body of enqueue requests.
+const maxPayload = 1 << 20
+
+// Producer is a client allowed to enqueue jobs.
+type Producer struct {
+	// Key identifies the producer in KeyHeader.
+	Key string
+	// Secret, when set, requires requests signed with Sign.
+	Secret string
+	// Workers the producer can queue jobs for, any when empty.
+	Workers []string
+}
+
+// can reports if the producer can queue jobs for the worker.
+func (p *Producer) can(workerName string) bool {
+	if len(p.Workers) < 1 {
+		return true
+	}
+	for _, x := range p.Workers {
+		if x == workerName {
+			return true
+		}
+	}
+	return false
+}
+
+// WithProducers restricts the enqueue route to the producers. Without it
+// enqueue requests are checked by the Authorizer with Operate access.
+func WithProducers(list ...Producer) Option {
+	return func(x *Handler) {
+		x.producers = make(map[string]*Producer, len(list))
+		for i := range list {
+			x.producers[list[i].Key] = &list[i]
+		}
+	}
+}
+
+// Sign returns the hex HMAC-SHA256 with secret of a request queueing data for
+// the worker at timestamp, in Unix seconds.
+func Sign(secret, workerName string, timestamp int64, data []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "\n" + workerName + "\n"))
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Errors of producer authentication.
+var (
+	errUnknownKey = errors.New("wormhttp: unknown producer key")
+	errSignature  = errors.New("wormhttp: invalid signature")
+	errExpired    = errors.New("wormhttp: request timestamp out of range")
+)
+
+// producer authenticates the request queueing data for the worker.
+func (x *Handler) producer(r *http.Request, workerName string, data []byte) (*Producer, error) {
+	p, ok := x.producers[r.Header.Get(KeyHeader)]
+	if !ok {
+		return nil, errUnknownKey
+	}
+	if len(p.Secret) < 1 {
+		return p, nil
+	}
+	ts, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return nil, errSignature
+	}
+	skew := time.Since(time.Unix(ts, 0))
+	if skew > maxSkew || skew < -maxSkew {
+		return nil, errExpired
+	}
+	sig, err := hex.DecodeString(r.Header.Get(SignatureHeader))
+	if err != nil {
+		return nil, errSignature
+	}
+	want, _ := hex.DecodeString(Sign(p.Secret, workerName, ts, data))
+	if !hmac.Equal(sig, want) {
+		return nil, errSignature
+	}
+	return p, nil
+}
+
+// enqueue queues the body as data of a job for the worker.
+//
+//	POST /queue?worker=&external_id=&group=
+func (x *Handler) enqueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if x.producers == nil && !x.limit(w, r, nil) {
+		return
+	}
+	// the body is the data, parameters come only from the URL.
+	q := r.URL.Query()
+	workerName := q.Get("worker")
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxPayload))
+	if err != nil {
+		http.Error(w, "can't read data", http.StatusBadRequest)
+		return
+	}
+	if x.producers != nil {
+		p, err := x.producer(r, workerName, data)
+		if err != nil {
+			// failed attempts spend the quota of the client IP.
+			if !x.limit(w, r, nil) {
+				return
+			}
+			log.Printf("wormhttp : enqueue : err [%s] worker [%s]", err, workerName)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !x.limit(w, r, p) {
+			return
+		}
+		if !p.can(workerName) {
+			log.Printf("wormhttp : enqueue : producer [%s] can't queue worker [%s]", p.Key, workerName)
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+	} else if err := x.auth.Authorize(r, Operate); err != nil {
+		log.Printf("wormhttp : authorize : err [%s] path [/queue] access [%s]", err, Operate)
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	var opts []worm.JobOption
+	if s := q.Get("external_id"); len(s) > 0 {
+		opts = append(opts, worm.ExternalID(s))
+	}
+	if s := q.Get("group"); len(s) > 0 {
+		opts = append(opts, worm.Group(s))
+	}
+	if s := r.Header.Get(TraceHeader); len(s) > 0 {
+		opts = append(opts, worm.Trace(worm.ContextWithTrace(r.Context(), s)))
+	}
+	jobID, err := x.hub.Queue(workerName, data, opts...)
+	if err != nil {
+		fail(w, "can't queue job", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, map[string]string{"id": jobID})
+}
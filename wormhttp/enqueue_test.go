@@ -0,0 +1,49 @@
+package wormhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestProducer(t *testing.T) {
+	x := New(nil, WithProducers(
+		Producer{Key: "open"},
+		Producer{Key: "signed", Secret: "s3cret", Workers: []string{"mailer"}},
+	))
+	data := []byte(`{"to":"a@b.c"}`)
+	now := time.Now().Unix()
+	table := []struct {
+		Name      string
+		Key       string
+		Timestamp int64
+		Signature string
+		Err       error
+	}{
+		{"unsigned", "open", 0, "", nil},
+		{"unknown key", "other", 0, "", errUnknownKey},
+		{"signed", "signed", now, Sign("s3cret", "mailer", now, data), nil},
+		{"missing signature", "signed", now, "", errSignature},
+		{"wrong secret", "signed", now, Sign("other", "mailer", now, data), errSignature},
+		{"wrong worker", "signed", now, Sign("s3cret", "billing", now, data), errSignature},
+		{"replayed", "signed", now - 3600, Sign("s3cret", "mailer", now-3600, data), errExpired},
+	}
+	for _, c := range table {
+		r := httptest.NewRequest(http.MethodPost, "/queue?worker=mailer", nil)
+		r.Header.Set(KeyHeader, c.Key)
+		if c.Timestamp > 0 {
+			r.Header.Set(TimestampHeader, strconv.FormatInt(c.Timestamp, 10))
+		}
+		r.Header.Set(SignatureHeader, c.Signature)
+		if _, err := x.producer(r, "mailer", data); err != c.Err {
+			t.Errorf("%s : expected [%v] got [%v]", c.Name, c.Err, err)
+		}
+	}
+
+	p := x.producers["signed"]
+	if !p.can("mailer") || p.can("billing") {
+		t.Errorf("signed : expected only mailer")
+	}
+}
@@ -0,0 +1,1470 @@
+package wormhttp
+
+import "net/http"
+
+// openAPI is the OpenAPI 3 document of the Handler routes. Keep it in sync
+// with New, TestOpenAPI checks every route is documented with its method and
+// access.
+const openAPI = `{
+	"openapi": "3.0.3",
+	"info": {
+		"title": "worm",
+		"version": "1",
+		"description": "Admin and enqueue API of a worm hub. Signed enqueue requests also send X-Worm-Timestamp and X-Worm-Signature."
+	},
+	"paths": {
+		"/job": {
+			"get": {
+				"operationId": "getJob",
+				"summary": "Job detail with children, audit and notes.",
+				"description": "Access: read.",
+				"parameters": [
+					{
+						"name": "id",
+						"in": "query",
+						"description": "Job ID.",
+						"schema": {
+							"type": "string"
+						},
+						"required": true
+					},
+					{
+						"name": "log",
+						"in": "query",
+						"description": "Includes the first and last log bytes, up to 65536.",
+						"schema": {
+							"type": "integer"
+						}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "The job.",
+						"content": {
+							"application/json": {
+								"schema": {
+									"$ref": "#/components/schemas/Job"
+								}
+							}
+						}
+					},
+					"400": {
+						"description": "Invalid parameters."
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					},
+					"404": {
+						"description": "Job not found."
+					}
+				}
+			}
+		},
+		"/jobs": {
+			"get": {
+				"operationId": "listJobs",
+				"summary": "Jobs matching the filter, newest first unless sorted.",
+				"description": "Access: read.",
+				"parameters": [
+					{
+						"name": "worker",
+						"in": "query",
+						"description": "Worker name.",
+						"schema": {
+							"type": "string"
+						}
+					},
+					{
+						"name": "group",
+						"in": "query",
+						"description": "Group ID.",
+						"schema": {
+							"type": "string"
+						}
+					},
+					{
+						"name": "external_id",
+						"in": "query",
+						"description": "External ID.",
+						"schema": {
+							"type": "string"
+						}
+					},
+					{
+						"name": "after",
+						"in": "query",
+						"description": "Created at or after, RFC 3339.",
+						"schema": {
+							"type": "string",
+							"format": "date-time"
+						}
+					},
+					{
+						"name": "before",
+						"in": "query",
+						"description": "Created before, RFC 3339.",
+						"schema": {
+							"type": "string",
+							"format": "date-time"
+						}
+					},
+					{
+						"name": "limit",
+						"in": "query",
+						"description": "Maximum jobs, 100 by default.",
+						"schema": {
+							"type": "integer"
+						}
+					},
+					{
+						"name": "sort",
+						"in": "query",
+						"description": "Sort order, created_at by default.",
+						"schema": {
+							"type": "string",
+							"enum": [
+								"created_at",
+								"updated_at",
+								"status",
+								"duration"
+							]
+						}
+					},
+					{
+						"name": "order",
+						"in": "query",
+						"description": "Sort direction, desc by default.",
+						"schema": {
+							"type": "string",
+							"enum": [
+								"asc",
+								"desc"
+							]
+						}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "The jobs.",
+						"content": {
+							"application/json": {
+								"schema": {
+									"type": "array",
+									"items": {
+										"$ref": "#/components/schemas/Job"
+									}
+								}
+							}
+						}
+					},
+					"400": {
+						"description": "Invalid parameters."
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					}
+				}
+			}
+		},
+		"/log": {
+			"get": {
+				"operationId": "getLog",
+				"summary": "Job log output.",
+				"description": "Access: read.",
+				"parameters": [
+					{
+						"name": "id",
+						"in": "query",
+						"description": "Job ID.",
+						"schema": {
+							"type": "string"
+						},
+						"required": true
+					},
+					{
+						"name": "run",
+						"in": "query",
+						"description": "Run ID, the log of one execution. Defaults to the latest.",
+						"schema": {
+							"type": "integer"
+						}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "The log.",
+						"content": {
+							"text/plain": {
+								"schema": {
+									"type": "string"
+								}
+							}
+						}
+					},
+					"400": {
+						"description": "Invalid parameters."
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					},
+					"404": {
+						"description": "Job not found."
+					}
+				}
+			}
+		},
+		"/run": {
+			"get": {
+				"operationId": "getRun",
+				"summary": "A single run of a job or schedule.",
+				"description": "Access: read.",
+				"parameters": [
+					{
+						"name": "id",
+						"in": "query",
+						"description": "Run ID.",
+						"schema": {
+							"type": "integer"
+						},
+						"required": true
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "The run.",
+						"content": {
+							"application/json": {
+								"schema": {
+									"$ref": "#/components/schemas/Run"
+								}
+							}
+						}
+					},
+					"400": {
+						"description": "Invalid parameters."
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					},
+					"404": {
+						"description": "Run not found."
+					}
+				}
+			}
+		},
+		"/logs.zip": {
+			"get": {
+				"operationId": "archiveLogs",
+				"summary": "Zip archive of the run logs of the jobs matching the filter.",
+				"description": "Access: read. Entries are named worker/job_id/run_id.log.",
+				"parameters": [
+					{
+						"name": "id",
+						"in": "query",
+						"description": "Job ID.",
+						"schema": {
+							"type": "string"
+						}
+					},
+					{
+						"name": "worker",
+						"in": "query",
+						"description": "Worker name.",
+						"schema": {
+							"type": "string"
+						}
+					},
+					{
+						"name": "group",
+						"in": "query",
+						"description": "Group ID.",
+						"schema": {
+							"type": "string"
+						}
+					},
+					{
+						"name": "external_id",
+						"in": "query",
+						"description": "External ID.",
+						"schema": {
+							"type": "string"
+						}
+					},
+					{
+						"name": "after",
+						"in": "query",
+						"description": "Job created at or after, RFC 3339.",
+						"schema": {
+							"type": "string",
+							"format": "date-time"
+						}
+					},
+					{
+						"name": "before",
+						"in": "query",
+						"description": "Job created before, RFC 3339.",
+						"schema": {
+							"type": "string",
+							"format": "date-time"
+						}
+					},
+					{
+						"name": "limit",
+						"in": "query",
+						"description": "Maximum runs, the latest, 100 by default.",
+						"schema": {
+							"type": "integer"
+						}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "The archive.",
+						"content": {
+							"application/zip": {
+								"schema": {
+									"type": "string",
+									"format": "binary"
+								}
+							}
+						}
+					},
+					"400": {
+						"description": "Invalid parameters."
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					}
+				}
+			}
+		},
+		"/changes": {
+			"get": {
+				"operationId": "readChanges",
+				"summary": "Job state transitions after a sequence number, oldest first.",
+				"description": "Access: read.",
+				"parameters": [
+					{
+						"name": "since",
+						"in": "query",
+						"description": "Seq of the last change read, 0 by default.",
+						"schema": {
+							"type": "integer"
+						}
+					},
+					{
+						"name": "limit",
+						"in": "query",
+						"description": "Maximum changes, 100 by default.",
+						"schema": {
+							"type": "integer"
+						}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "The changes.",
+						"content": {
+							"application/json": {
+								"schema": {
+									"type": "array",
+									"items": {
+										"$ref": "#/components/schemas/Change"
+									}
+								}
+							}
+						}
+					},
+					"400": {
+						"description": "Invalid parameters."
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					}
+				}
+			}
+		},
+		"/schedules": {
+			"get": {
+				"operationId": "listSchedules",
+				"summary": "Recurring jobs by schedule ID, oldest first.",
+				"description": "Access: read.",
+				"responses": {
+					"200": {
+						"description": "The schedules.",
+						"content": {
+							"application/json": {
+								"schema": {
+									"type": "array",
+									"items": {
+										"$ref": "#/components/schemas/Schedule"
+									}
+								}
+							}
+						}
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					}
+				}
+			}
+		},
+		"/schedule": {
+			"get": {
+				"operationId": "getSchedule",
+				"summary": "Recurring job by schedule ID with its next and last runs.",
+				"description": "Access: read.",
+				"parameters": [
+					{
+						"name": "id",
+						"in": "query",
+						"description": "Schedule ID.",
+						"schema": {
+							"type": "string"
+						},
+						"required": true
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "The schedule.",
+						"content": {
+							"application/json": {
+								"schema": {
+									"$ref": "#/components/schemas/Schedule"
+								}
+							}
+						}
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					},
+					"404": {
+						"description": "Schedule not found."
+					}
+				}
+			}
+		},
+		"/drift": {
+			"get": {
+				"operationId": "getDrift",
+				"summary": "Missed and late firings of the enabled schedules.",
+				"description": "Access: read.",
+				"parameters": [
+					{
+						"name": "since",
+						"in": "query",
+						"description": "Start of the period, the creation of each schedule by default.",
+						"schema": {
+							"type": "string",
+							"format": "date-time"
+						}
+					},
+					{
+						"name": "tolerance",
+						"in": "query",
+						"description": "Seconds a run may start after it was due, 0 by default.",
+						"schema": {
+							"type": "integer"
+						}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "The drift by schedule.",
+						"content": {
+							"application/json": {
+								"schema": {
+									"type": "array",
+									"items": {
+										"$ref": "#/components/schemas/ScheduleDrift"
+									}
+								}
+							}
+						}
+					},
+					"400": {
+						"description": "Invalid parameters."
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					}
+				}
+			}
+		},
+		"/stats": {
+			"get": {
+				"operationId": "getStats",
+				"summary": "Job counts and health by worker.",
+				"description": "Access: read.",
+				"responses": {
+					"200": {
+						"description": "The stats.",
+						"content": {
+							"application/json": {
+								"schema": {
+									"$ref": "#/components/schemas/HubStats"
+								}
+							}
+						}
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					}
+				}
+			}
+		},
+		"/workers": {
+			"get": {
+				"operationId": "listWorkers",
+				"summary": "Registered workers.",
+				"description": "Access: read.",
+				"responses": {
+					"200": {
+						"description": "The workers.",
+						"content": {
+							"application/json": {
+								"schema": {
+									"type": "array",
+									"items": {
+										"$ref": "#/components/schemas/WorkerInfo"
+									}
+								}
+							}
+						}
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					}
+				}
+			}
+		},
+		"/config": {
+			"get": {
+				"operationId": "getWorkerConfig",
+				"summary": "Worker configuration passed to its runs.",
+				"description": "Access: read.",
+				"parameters": [
+					{
+						"name": "worker",
+						"in": "query",
+						"description": "Worker name.",
+						"schema": {
+							"type": "string"
+						},
+						"required": true
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "The configuration, empty when not set.",
+						"content": {
+							"application/octet-stream": {
+								"schema": {
+									"type": "string",
+									"format": "binary"
+								}
+							}
+						}
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					}
+				}
+			}
+		},
+		"/configure": {
+			"post": {
+				"operationId": "setWorkerConfig",
+				"summary": "Replace the worker configuration, kept in the database.",
+				"description": "Access: operate.",
+				"parameters": [
+					{
+						"name": "worker",
+						"in": "query",
+						"description": "Worker name.",
+						"schema": {
+							"type": "string"
+						},
+						"required": true
+					}
+				],
+				"responses": {
+					"204": {
+						"description": "Done."
+					},
+					"400": {
+						"description": "Invalid parameters."
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					}
+				},
+				"requestBody": {
+					"required": true,
+					"content": {
+						"application/octet-stream": {
+							"schema": {
+								"type": "string",
+								"format": "binary"
+							}
+						}
+					}
+				}
+			}
+		},
+		"/retry": {
+			"post": {
+				"operationId": "retryJob",
+				"summary": "Run a failed job again.",
+				"description": "Access: operate.",
+				"parameters": [
+					{
+						"name": "id",
+						"in": "query",
+						"description": "Job ID.",
+						"schema": {
+							"type": "string"
+						},
+						"required": true
+					}
+				],
+				"responses": {
+					"204": {
+						"description": "Done."
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					},
+					"404": {
+						"description": "Job not found."
+					},
+					"409": {
+						"description": "The job state does not allow the operation."
+					}
+				}
+			}
+		},
+		"/cancel": {
+			"post": {
+				"operationId": "cancelJob",
+				"summary": "Cancel a pending job.",
+				"description": "Access: operate.",
+				"parameters": [
+					{
+						"name": "id",
+						"in": "query",
+						"description": "Job ID.",
+						"schema": {
+							"type": "string"
+						},
+						"required": true
+					}
+				],
+				"responses": {
+					"204": {
+						"description": "Done."
+					},
+					"400": {
+						"description": "Invalid parameters."
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					},
+					"409": {
+						"description": "The job state does not allow the operation."
+					}
+				}
+			}
+		},
+		"/trigger": {
+			"post": {
+				"operationId": "triggerSchedule",
+				"summary": "Fire a recurring job now as a manual run, its schedule doesn't change.",
+				"description": "Access: operate.",
+				"parameters": [
+					{
+						"name": "id",
+						"in": "query",
+						"description": "Schedule ID.",
+						"schema": {
+							"type": "string"
+						},
+						"required": true
+					}
+				],
+				"responses": {
+					"202": {
+						"description": "Run started."
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					},
+					"404": {
+						"description": "Schedule not found."
+					},
+					"409": {
+						"description": "Schedule disabled."
+					}
+				}
+			}
+		},
+		"/note": {
+			"post": {
+				"operationId": "annotateJob",
+				"summary": "Add an operator note to a job.",
+				"description": "Access: operate.",
+				"parameters": [
+					{
+						"name": "id",
+						"in": "query",
+						"description": "Job ID.",
+						"schema": {
+							"type": "string"
+						},
+						"required": true
+					}
+				],
+				"responses": {
+					"204": {
+						"description": "Done."
+					},
+					"400": {
+						"description": "Invalid parameters."
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					},
+					"404": {
+						"description": "Job not found."
+					}
+				},
+				"requestBody": {
+					"required": true,
+					"content": {
+						"text/plain": {
+							"schema": {
+								"type": "string"
+							}
+						}
+					}
+				}
+			}
+		},
+		"/delete": {
+			"post": {
+				"operationId": "deleteJob",
+				"summary": "Soft delete a job.",
+				"description": "Access: destroy.",
+				"parameters": [
+					{
+						"name": "id",
+						"in": "query",
+						"description": "Job ID.",
+						"schema": {
+							"type": "string"
+						},
+						"required": true
+					}
+				],
+				"responses": {
+					"204": {
+						"description": "Done."
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					},
+					"404": {
+						"description": "Job not found."
+					}
+				}
+			}
+		},
+		"/unschedule": {
+			"post": {
+				"operationId": "deleteSchedule",
+				"summary": "Soft delete a recurring job by schedule ID.",
+				"description": "Access: destroy.",
+				"parameters": [
+					{
+						"name": "id",
+						"in": "query",
+						"description": "Schedule ID.",
+						"schema": {
+							"type": "string"
+						},
+						"required": true
+					}
+				],
+				"responses": {
+					"204": {
+						"description": "Done."
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					},
+					"404": {
+						"description": "Schedule not found."
+					}
+				}
+			}
+		},
+		"/purge": {
+			"post": {
+				"operationId": "purgeJobs",
+				"summary": "Remove jobs deleted before a time.",
+				"description": "Access: destroy.",
+				"parameters": [
+					{
+						"name": "before",
+						"in": "query",
+						"description": "Deleted before, RFC 3339.",
+						"schema": {
+							"type": "string",
+							"format": "date-time"
+						},
+						"required": true
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "Purged jobs.",
+						"content": {
+							"application/json": {
+								"schema": {
+									"type": "object",
+									"properties": {
+										"purged": {
+											"type": "integer"
+										}
+									}
+								}
+							}
+						}
+					},
+					"400": {
+						"description": "Invalid parameters."
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					}
+				}
+			}
+		},
+		"/status": {
+			"post": {
+				"operationId": "setStatus",
+				"summary": "Override the status of a job.",
+				"description": "Access: destroy.",
+				"parameters": [
+					{
+						"name": "id",
+						"in": "query",
+						"description": "Job ID.",
+						"schema": {
+							"type": "string"
+						},
+						"required": true
+					},
+					{
+						"name": "status",
+						"in": "query",
+						"description": "New status.",
+						"schema": {
+							"type": "integer"
+						},
+						"required": true
+					},
+					{
+						"name": "reason",
+						"in": "query",
+						"description": "Who changed the status and why.",
+						"schema": {
+							"type": "string"
+						}
+					}
+				],
+				"responses": {
+					"204": {
+						"description": "Done."
+					},
+					"400": {
+						"description": "Invalid parameters."
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					},
+					"404": {
+						"description": "Job not found."
+					}
+				}
+			}
+		},
+		"/statuses": {
+			"post": {
+				"operationId": "setStatuses",
+				"summary": "Override the status of many jobs in a single transaction, unknown jobs are skipped.",
+				"description": "Access: destroy.",
+				"responses": {
+					"200": {
+						"description": "Number of updated jobs.",
+						"content": {
+							"application/json": {
+								"schema": {
+									"type": "object",
+									"properties": {
+										"updated": {
+											"type": "integer"
+										}
+									}
+								}
+							}
+						}
+					},
+					"400": {
+						"description": "Invalid updates."
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					},
+					"409": {
+						"description": "An update sets the waiting status, nothing was updated."
+					}
+				},
+				"requestBody": {
+					"required": true,
+					"content": {
+						"application/json": {
+							"schema": {
+								"type": "array",
+								"items": {
+									"type": "object",
+									"properties": {
+										"id": {
+											"type": "string"
+										},
+										"status": {
+											"type": "integer"
+										},
+										"reason": {
+											"type": "string",
+											"description": "Who changed the status and why."
+										}
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		},
+		"/queue": {
+			"post": {
+				"operationId": "queueJob",
+				"summary": "Queue a job, the body is its data.",
+				"description": "Access: operate or producer key.",
+				"parameters": [
+					{
+						"name": "worker",
+						"in": "query",
+						"description": "Worker name.",
+						"schema": {
+							"type": "string"
+						},
+						"required": true
+					},
+					{
+						"name": "external_id",
+						"in": "query",
+						"description": "External ID.",
+						"schema": {
+							"type": "string"
+						}
+					},
+					{
+						"name": "group",
+						"in": "query",
+						"description": "Group ID.",
+						"schema": {
+							"type": "string"
+						}
+					},
+					{
+						"name": "X-Request-ID",
+						"in": "header",
+						"description": "Trace ID of the job, generated when missing.",
+						"schema": {
+							"type": "string"
+						}
+					}
+				],
+				"responses": {
+					"201": {
+						"description": "Queued.",
+						"content": {
+							"application/json": {
+								"schema": {
+									"type": "object",
+									"properties": {
+										"id": {
+											"type": "string"
+										}
+									}
+								}
+							}
+						}
+					},
+					"400": {
+						"description": "Invalid parameters."
+					},
+					"401": {
+						"description": "Unknown producer key or invalid signature."
+					},
+					"403": {
+						"description": "Forbidden by the authorizer."
+					},
+					"429": {
+						"description": "Rate limited, see Retry-After."
+					},
+					"503": {
+						"description": "Queue full."
+					}
+				},
+				"requestBody": {
+					"content": {
+						"application/octet-stream": {
+							"schema": {
+								"type": "string",
+								"format": "binary"
+							}
+						}
+					}
+				},
+				"security": [
+					{
+						"producerKey": []
+					},
+					{}
+				]
+			}
+		}
+	},
+	"components": {
+		"schemas": {
+			"Job": {
+				"type": "object",
+				"properties": {
+					"id": {
+						"type": "string"
+					},
+					"worker_name": {
+						"type": "string"
+					},
+					"status": {
+						"type": "integer"
+					},
+					"error": {
+						"type": "string"
+					},
+					"log_file": {
+						"type": "string"
+					},
+					"data": {
+						"type": "string"
+					},
+					"blob_key": {
+						"type": "string"
+					},
+					"external_id": {
+						"type": "string"
+					},
+					"parent_id": {
+						"type": "string"
+					},
+					"trace_id": {
+						"type": "string"
+					},
+					"schedule_id": {
+						"type": "string"
+					},
+					"cron": {
+						"type": "string"
+					},
+					"created_at": {
+						"type": "string",
+						"format": "date-time"
+					},
+					"updated_at": {
+						"type": "string",
+						"format": "date-time"
+					},
+					"attempts": {
+						"type": "integer"
+					},
+					"metadata": {
+						"type": "object",
+						"additionalProperties": {
+							"type": "string"
+						}
+					},
+					"children": {
+						"type": "array",
+						"items": {
+							"type": "string"
+						}
+					},
+					"audit": {
+						"type": "array",
+						"items": {
+							"$ref": "#/components/schemas/AuditEntry"
+						}
+					},
+					"notes": {
+						"type": "array",
+						"items": {
+							"$ref": "#/components/schemas/Note"
+						}
+					},
+					"log_head": {
+						"type": "string"
+					},
+					"log_tail": {
+						"type": "string"
+					},
+					"next_run": {
+						"type": "string",
+						"format": "date-time"
+					},
+					"last_run": {
+						"$ref": "#/components/schemas/Run"
+					}
+				}
+			},
+			"Run": {
+				"type": "object",
+				"properties": {
+					"id": {
+						"type": "integer"
+					},
+					"job_id": {
+						"type": "string"
+					},
+					"instance_id": {
+						"type": "string"
+					},
+					"status": {
+						"type": "integer"
+					},
+					"error": {
+						"type": "string"
+					},
+					"started_at": {
+						"type": "string",
+						"format": "date-time"
+					},
+					"finished_at": {
+						"type": "string",
+						"format": "date-time"
+					},
+					"version": {
+						"type": "string"
+					},
+					"log_file": {
+						"type": "string"
+					},
+					"manual": {
+						"type": "boolean"
+					},
+					"due_at": {
+						"type": "string",
+						"format": "date-time"
+					}
+				}
+			},
+			"Schedule": {
+				"type": "object",
+				"properties": {
+					"id": {
+						"type": "string"
+					},
+					"job_id": {
+						"type": "string"
+					},
+					"worker_name": {
+						"type": "string"
+					},
+					"spec": {
+						"type": "string"
+					},
+					"disabled_at": {
+						"type": "string",
+						"format": "date-time"
+					},
+					"created_at": {
+						"type": "string",
+						"format": "date-time"
+					},
+					"next_run": {
+						"type": "string",
+						"format": "date-time"
+					},
+					"last_run": {
+						"$ref": "#/components/schemas/Run"
+					}
+				}
+			},
+			"ScheduleDrift": {
+				"type": "object",
+				"properties": {
+					"schedule_id": {
+						"type": "string"
+					},
+					"job_id": {
+						"type": "string"
+					},
+					"expected": {
+						"type": "integer"
+					},
+					"fired": {
+						"type": "integer"
+					},
+					"missed": {
+						"type": "array",
+						"items": {
+							"type": "string",
+							"format": "date-time"
+						}
+					},
+					"late": {
+						"type": "integer"
+					},
+					"max_delay": {
+						"type": "integer",
+						"description": "Nanoseconds."
+					}
+				}
+			},
+			"AuditEntry": {
+				"type": "object",
+				"properties": {
+					"status": {
+						"type": "integer"
+					},
+					"reason": {
+						"type": "string"
+					},
+					"created_at": {
+						"type": "string",
+						"format": "date-time"
+					}
+				}
+			},
+			"Note": {
+				"type": "object",
+				"properties": {
+					"text": {
+						"type": "string"
+					},
+					"created_at": {
+						"type": "string",
+						"format": "date-time"
+					}
+				}
+			},
+			"WorkerInfo": {
+				"type": "object",
+				"properties": {
+					"name": {
+						"type": "string"
+					},
+					"healthy": {
+						"type": "boolean"
+					},
+					"health_error": {
+						"type": "string"
+					},
+					"checked_at": {
+						"type": "string",
+						"format": "date-time"
+					},
+					"paused": {
+						"type": "boolean"
+					},
+					"paused_until": {
+						"type": "string",
+						"format": "date-time"
+					},
+					"delivery": {
+						"type": "string",
+						"enum": [
+							"at-least-once",
+							"at-most-once"
+						]
+					},
+					"queue": {
+						"type": "string"
+					},
+					"max_retries": {
+						"type": "integer"
+					},
+					"timeout": {
+						"type": "integer",
+						"description": "Nanoseconds."
+					},
+					"log_retention": {
+						"type": "integer",
+						"description": "Nanoseconds."
+					},
+					"concurrency": {
+						"type": "integer"
+					},
+					"rate_limit": {
+						"type": "integer",
+						"description": "Runs started per rate period."
+					},
+					"rate_period": {
+						"type": "integer",
+						"description": "Nanoseconds."
+					},
+					"running": {
+						"type": "integer"
+					}
+				}
+			},
+			"WorkerStats": {
+				"allOf": [
+					{
+						"$ref": "#/components/schemas/WorkerInfo"
+					},
+					{
+						"type": "object",
+						"properties": {
+							"pending": {
+								"type": "integer"
+							},
+							"succeeded": {
+								"type": "integer"
+							},
+							"failed": {
+								"type": "integer"
+							},
+							"oldest_pending": {
+								"type": "integer",
+								"description": "Age in nanoseconds of the oldest single-execution job not finished."
+							}
+						}
+					}
+				]
+			},
+			"SweepStats": {
+				"type": "object",
+				"properties": {
+					"sweeps": {
+						"type": "integer"
+					},
+					"last_sweep": {
+						"type": "string",
+						"format": "date-time"
+					},
+					"expired": {
+						"type": "integer"
+					},
+					"stale": {
+						"type": "integer"
+					},
+					"recovered": {
+						"type": "integer"
+					},
+					"logs": {
+						"type": "integer"
+					},
+					"sharded": {
+						"type": "integer"
+					}
+				}
+			},
+			"Change": {
+				"type": "object",
+				"properties": {
+					"seq": {
+						"type": "integer"
+					},
+					"job_id": {
+						"type": "string"
+					},
+					"worker_name": {
+						"type": "string"
+					},
+					"kind": {
+						"type": "string",
+						"enum": [
+							"queued",
+							"status",
+							"deleted",
+							"disabled",
+							"enabled"
+						]
+					},
+					"status": {
+						"type": "integer"
+					},
+					"state": {
+						"type": "string"
+					},
+					"error": {
+						"type": "string"
+					},
+					"finished_at": {
+						"type": "string",
+						"format": "date-time"
+					},
+					"changed_at": {
+						"type": "string",
+						"format": "date-time"
+					}
+				}
+			},
+			"HubStats": {
+				"type": "object",
+				"properties": {
+					"workers": {
+						"type": "array",
+						"items": {
+							"$ref": "#/components/schemas/WorkerStats"
+						}
+					},
+					"sweep": {
+						"$ref": "#/components/schemas/SweepStats"
+					},
+					"database": {
+						"type": "object",
+						"properties": {
+							"size": {
+								"type": "integer",
+								"description": "SQLite database size in bytes."
+							},
+							"wal_size": {
+								"type": "integer",
+								"description": "Write-ahead log size in bytes."
+							}
+						}
+					}
+				}
+			}
+		},
+		"securitySchemes": {
+			"producerKey": {
+				"type": "apiKey",
+				"in": "header",
+				"name": "X-Worm-Key"
+			}
+		}
+	}
+}
+`
+
+// openapi serves the OpenAPI document.
+func (x *Handler) openapi(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(openAPI))
+}
@@ -0,0 +1,60 @@
+package wormhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPI(t *testing.T) {
+	x := New(nil)
+	w := httptest.NewRecorder()
+	x.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected [200] got [%d]", w.Code)
+	}
+	type operation struct {
+		Description string `json:"description"`
+	}
+	var doc struct {
+		OpenAPI string                          `json:"openapi"`
+		Paths   map[string]map[string]operation `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != "3.0.3" {
+		t.Errorf("expected version [3.0.3] got [%s]", doc.OpenAPI)
+	}
+
+	// the enqueue route authorizes producers itself.
+	endpoints := append(x.endpoints, endpoint{path: "/queue", method: http.MethodPost, access: Operate})
+	documented := 0
+	for _, e := range endpoints {
+		if e.path == "/openapi.json" {
+			continue
+		}
+		documented++
+		ops, ok := doc.Paths[e.path]
+		if !ok {
+			t.Errorf("route [%s] not documented", e.path)
+			continue
+		}
+		if len(ops) != 1 {
+			t.Errorf("route [%s] : expected only [%s] got [%d] methods", e.path, e.method, len(ops))
+		}
+		op, ok := ops[strings.ToLower(e.method)]
+		if !ok {
+			t.Errorf("route [%s] : method [%s] not documented", e.path, e.method)
+			continue
+		}
+		if access := "Access: " + e.access.String(); !strings.HasPrefix(op.Description, access) {
+			t.Errorf("route [%s] : expected [%s] got [%s]", e.path, access, op.Description)
+		}
+	}
+	if len(doc.Paths) != documented {
+		t.Errorf("expected [%d] paths got [%d]", documented, len(doc.Paths))
+	}
+}
@@ -0,0 +1,110 @@
+package wormhttp
+
+import (
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxBuckets bounds the clients tracked by the rate limiter. Beyond it the
+// idle ones are dropped.
+const maxBuckets = 10000
+
+// WithRateLimit limits enqueue requests of each producer, or client IP for
+// requests not authenticated as one, to rate per second with bursts of
+// burst. Requests over the limit get a 429 response before the job is
+// stored.
+func WithRateLimit(rate float64, burst int) Option {
+	return func(x *Handler) {
+		x.limiter = &limiter{
+			rate:    rate,
+			burst:   float64(burst),
+			buckets: make(map[string]*bucket),
+			now:     time.Now,
+		}
+	}
+}
+
+// bucket is the token bucket of a client.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// limiter is a token bucket rate limiter by client.
+type limiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// allow takes a token of the client. When none is left it returns false and
+// the wait for the next one.
+func (l *limiter) allow(client string) (bool, time.Duration) {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[client]
+	if !ok {
+		if len(l.buckets) >= maxBuckets {
+			l.prune(now)
+		}
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// prune drops the buckets refilled at now. Must be called holding mu.
+func (l *limiter) prune(now time.Time) {
+	for k, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+// client returns the rate limit key of the request: the key of the
+// authenticated producer p, or the client IP when nil. Keys count only once
+// verified, so requests with a known key and a bad signature can't spend
+// the quota of the producer.
+func client(r *http.Request, p *Producer) string {
+	if p != nil {
+		return "key:" + p.Key
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// limit writes a 429 response and returns false when the request of the
+// producer p, nil for unauthenticated ones, is over the rate limit.
+func (x *Handler) limit(w http.ResponseWriter, r *http.Request, p *Producer) bool {
+	if x.limiter == nil {
+		return true
+	}
+	c := client(r, p)
+	ok, wait := x.limiter.allow(c)
+	if ok {
+		return true
+	}
+	log.Printf("wormhttp : rate limit : client [%s]", c)
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	http.Error(w, "too many requests", http.StatusTooManyRequests)
+	return false
+}
@@ -0,0 +1,85 @@
+package wormhttp
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	x := New(nil, WithProducers(Producer{Key: "app"}), WithRateLimit(1, 2))
+	x.limiter.now = func() time.Time {
+		return now
+	}
+	app := x.producers["app"]
+
+	table := []struct {
+		Name     string
+		Producer *Producer
+		Addr     string
+		OK       bool
+	}{
+		{"burst 1", app, "10.0.0.1:1000", true},
+		{"burst 2", app, "10.0.0.2:1000", true},
+		{"over", app, "10.0.0.3:1000", false},
+		{"unauthenticated by ip", nil, "10.0.0.1:1000", true},
+		{"ip burst 2", nil, "10.0.0.1:2000", true},
+		{"ip over", nil, "10.0.0.1:3000", false},
+	}
+	for _, c := range table {
+		r := httptest.NewRequest(http.MethodPost, "/queue?worker=mailer", nil)
+		r.RemoteAddr = c.Addr
+		w := httptest.NewRecorder()
+		if ok := x.limit(w, r, c.Producer); ok != c.OK {
+			t.Errorf("%s : expected ok [%v] got [%v]", c.Name, c.OK, ok)
+		}
+		if !c.OK && (w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "1") {
+			t.Errorf("%s : got [%d] retry after [%s]", c.Name, w.Code, w.Header().Get("Retry-After"))
+		}
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := x.limiter.allow("key:app"); !ok {
+		t.Errorf("refill : expected a token after a second")
+	}
+	if ok, wait := x.limiter.allow("key:app"); ok || wait != time.Second {
+		t.Errorf("refill : expected wait [1s] got [%s]", wait)
+	}
+}
+
+func TestLimiterBadSignature(t *testing.T) {
+	now := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	x := New(nil, WithProducers(Producer{Key: "signed", Secret: "s3cret"}), WithRateLimit(1, 1))
+	x.limiter.now = func() time.Time {
+		return now
+	}
+	ts := time.Now().Unix()
+	for _, c := range []struct {
+		Name string
+		Addr string
+		Code int
+	}{
+		{"first", "10.0.0.1:1000", http.StatusUnauthorized},
+		{"other ip", "10.0.0.2:1000", http.StatusUnauthorized},
+		{"ip over", "10.0.0.1:2000", http.StatusTooManyRequests},
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/queue?worker=mailer", bytes.NewReader([]byte("{}")))
+		r.RemoteAddr = c.Addr
+		r.Header.Set(KeyHeader, "signed")
+		r.Header.Set(TimestampHeader, strconv.FormatInt(ts, 10))
+		r.Header.Set(SignatureHeader, Sign("wrong", "mailer", ts, []byte("{}")))
+		w := httptest.NewRecorder()
+		x.enqueue(w, r)
+		if w.Code != c.Code {
+			t.Errorf("%s : expected [%d] got [%d]", c.Name, c.Code, w.Code)
+		}
+	}
+	// the producer quota is untouched.
+	if ok, _ := x.limiter.allow("key:signed"); !ok {
+		t.Errorf("expected the producer bucket full")
+	}
+}
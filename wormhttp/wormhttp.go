@@ -0,0 +1,565 @@
+// Package wormhttp serves the admin API of a worm hub over HTTP.
+//
+// Every route is checked by an Authorizer with the access level of the
+// operation, so a dashboard can be shared read-only with the whole team while
+// retries, cancels and destructive operations stay restricted:
+//
+//	admin := wormhttp.New(hub, wormhttp.WithAuthorizer(wormhttp.Roles(role)))
+//	http.Handle("/worm/", http.StripPrefix("/worm", admin))
+package wormhttp
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	worm "github.com/jimmy-go/worm.io"
+)
+
+// Access is the level of an operation. Each level includes the ones below.
+type Access int
+
+const (
+	// Read gets jobs, logs, stats and workers.
+	Read Access = iota
+	// Operate retries, cancels and annotates jobs.
+	Operate
+	// Destroy deletes and purges jobs and overrides their status.
+	Destroy
+)
+
+// String returns the name of the access level.
+func (a Access) String() string {
+	switch a {
+	case Read:
+		return "read"
+	case Operate:
+		return "operate"
+	case Destroy:
+		return "destroy"
+	}
+	return "access(" + strconv.Itoa(int(a)) + ")"
+}
+
+// ErrForbidden is returned by authorizers that deny a request.
+var ErrForbidden = errors.New("wormhttp: forbidden")
+
+// Authorizer decides if a request may perform an operation of the access
+// level. Denied requests get a 403 response.
+type Authorizer interface {
+	Authorize(r *http.Request, access Access) error
+}
+
+// AuthorizerFunc adapts a function to Authorizer.
+type AuthorizerFunc func(r *http.Request, access Access) error
+
+// Authorize implements Authorizer.
+func (f AuthorizerFunc) Authorize(r *http.Request, access Access) error {
+	return f(r, access)
+}
+
+// Roles returns an Authorizer granting the access level returned by role and
+// every level below it. Requests without a role, ok false, are denied.
+func Roles(role func(r *http.Request) (Access, bool)) Authorizer {
+	return AuthorizerFunc(func(r *http.Request, access Access) error {
+		granted, ok := role(r)
+		if !ok || access > granted {
+			return ErrForbidden
+		}
+		return nil
+	})
+}
+
+// allowAll is the default Authorizer.
+var allowAll = AuthorizerFunc(func(r *http.Request, access Access) error {
+	return nil
+})
+
+// Option configures a Handler.
+type Option func(*Handler)
+
+// WithAuthorizer checks every request with a. Without it every request is
+// allowed.
+func WithAuthorizer(a Authorizer) Option {
+	return func(x *Handler) {
+		x.auth = a
+	}
+}
+
+// defaultLimit is the number of jobs listed when the request sets no limit.
+const defaultLimit = 100
+
+// maxExcerpt bounds the log head and tail sizes of /job.
+const maxExcerpt = 64 << 10
+
+// Handler serves the admin API of a hub.
+//
+//	GET  /job?id=&log=                             Read
+//	GET  /jobs?worker=&group=&external_id=&after=&before=&limit=&sort=&order=  Read
+//	GET  /log?id=&run=                             Read
+//	GET  /run?id=                                  Read
+//	GET  /logs.zip?id=&worker=&group=&external_id=&after=&before=&limit=  Read
+//	GET  /changes?since=&limit=                    Read
+//	GET  /schedules                                Read
+//	GET  /schedule?id=                             Read
+//	GET  /drift?since=&tolerance=  (seconds)       Read
+//	GET  /stats                                    Read
+//	GET  /workers                                  Read
+//	GET  /config?worker=                           Read
+//	POST /configure?worker=  (body is the config)  Operate
+//	POST /retry?id=                                Operate
+//	POST /cancel?id=                               Operate
+//	POST /trigger?id=                              Operate
+//	POST /note?id=  (body is the text)             Operate
+//	POST /delete?id=                               Destroy
+//	POST /unschedule?id=                           Destroy
+//	POST /purge?before=                            Destroy
+//	POST /status?id=&status=&reason=               Destroy
+//	POST /statuses  (body is a JSON array of updates)  Destroy
+//	POST /queue?worker=&external_id=&group=        Operate or producer, rate limited
+//	GET  /openapi.json                             Read
+//
+// Times are RFC 3339.
+type Handler struct {
+	hub  *worm.Worm
+	auth Authorizer
+	mux  *http.ServeMux
+	// producers authenticate enqueue requests by key when not nil.
+	producers map[string]*Producer
+	// limiter rate limits enqueue requests when not nil.
+	limiter *limiter
+	// endpoints are the routes checked by the authorizer.
+	endpoints []endpoint
+}
+
+// endpoint is a route registered by New.
+type endpoint struct {
+	path   string
+	method string
+	access Access
+}
+
+// New returns the admin API Handler of hub.
+func New(hub *worm.Worm, opts ...Option) *Handler {
+	x := &Handler{
+		hub:  hub,
+		auth: allowAll,
+		mux:  http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(x)
+	}
+	x.route("/job", http.MethodGet, Read, x.job)
+	x.route("/jobs", http.MethodGet, Read, x.jobs)
+	x.route("/log", http.MethodGet, Read, x.log)
+	x.route("/run", http.MethodGet, Read, x.run)
+	x.route("/logs.zip", http.MethodGet, Read, x.logs)
+	x.route("/changes", http.MethodGet, Read, x.changes)
+	x.route("/schedules", http.MethodGet, Read, x.schedules)
+	x.route("/schedule", http.MethodGet, Read, x.schedule)
+	x.route("/drift", http.MethodGet, Read, x.drift)
+	x.route("/stats", http.MethodGet, Read, x.stats)
+	x.route("/workers", http.MethodGet, Read, x.workers)
+	x.route("/config", http.MethodGet, Read, x.config)
+	x.route("/configure", http.MethodPost, Operate, x.configure)
+	x.route("/retry", http.MethodPost, Operate, x.retry)
+	x.route("/cancel", http.MethodPost, Operate, x.cancel)
+	x.route("/trigger", http.MethodPost, Operate, x.trigger)
+	x.route("/note", http.MethodPost, Operate, x.note)
+	x.route("/delete", http.MethodPost, Destroy, x.delete)
+	x.route("/unschedule", http.MethodPost, Destroy, x.unschedule)
+	x.route("/purge", http.MethodPost, Destroy, x.purge)
+	x.route("/status", http.MethodPost, Destroy, x.status)
+	x.route("/statuses", http.MethodPost, Destroy, x.statuses)
+	x.mux.HandleFunc("/queue", x.enqueue)
+	x.route("/openapi.json", http.MethodGet, Read, x.openapi)
+	return x
+}
+
+// ServeHTTP implements http.Handler.
+func (x *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	x.mux.ServeHTTP(w, r)
+}
+
+// route registers fn for method requests to path authorized for access.
+func (x *Handler) route(path, method string, access Access, fn http.HandlerFunc) {
+	x.endpoints = append(x.endpoints, endpoint{path: path, method: method, access: access})
+	x.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := x.auth.Authorize(r, access); err != nil {
+			log.Printf("wormhttp : authorize : err [%s] path [%s] access [%s]", err, path, access)
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		fn(w, r)
+	})
+}
+
+func (x *Handler) job(w http.ResponseWriter, r *http.Request) {
+	var opts []worm.DetailOption
+	if s := r.FormValue("log"); len(s) > 0 {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxExcerpt {
+			http.Error(w, "invalid log", http.StatusBadRequest)
+			return
+		}
+		opts = append(opts, worm.LogExcerpt(n))
+	}
+	job, err := x.hub.Detail(r.FormValue("id"), opts...)
+	if err != nil {
+		fail(w, "can't retrieve job", err)
+		return
+	}
+	writeJSON(w, job)
+}
+
+func (x *Handler) jobs(w http.ResponseWriter, r *http.Request) {
+	filter, limit, bad := formFilter(r)
+	if len(bad) > 0 {
+		http.Error(w, "invalid "+bad, http.StatusBadRequest)
+		return
+	}
+	filter.Sort = r.FormValue("sort")
+	switch r.FormValue("order") {
+	case "", "desc":
+	case "asc":
+		filter.Ascending = true
+	default:
+		http.Error(w, "invalid order", http.StatusBadRequest)
+		return
+	}
+	jobs, err := x.hub.Jobs(filter, limit)
+	if err != nil {
+		fail(w, "can't retrieve jobs", err)
+		return
+	}
+	writeJSON(w, jobs)
+}
+
+func (x *Handler) log(w http.ResponseWriter, r *http.Request) {
+	id := r.FormValue("id")
+	if s := r.FormValue("run"); len(s) > 0 {
+		runID, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid run", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err := x.hub.CopyRunLog(w, id, runID); err != nil {
+			fail(w, "can't retrieve log", err)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if err := x.hub.CopyLog(w, id); err != nil {
+		fail(w, "can't retrieve log", err)
+	}
+}
+
+func (x *Handler) run(w http.ResponseWriter, r *http.Request) {
+	runID, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	run, err := x.hub.RunByID(runID)
+	if err != nil {
+		fail(w, "can't retrieve run", err)
+		return
+	}
+	writeJSON(w, run)
+}
+
+func (x *Handler) logs(w http.ResponseWriter, r *http.Request) {
+	filter, limit, bad := formFilter(r)
+	if len(bad) > 0 {
+		http.Error(w, "invalid "+bad, http.StatusBadRequest)
+		return
+	}
+	filter.ID = r.FormValue("id")
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="logs.zip"`)
+	// the archive streams, errors past the first write only cut it short.
+	if _, err := x.hub.ArchiveLogs(w, filter, limit); err != nil {
+		log.Printf("wormhttp : archive logs : err [%s]", err)
+	}
+}
+
+func (x *Handler) changes(w http.ResponseWriter, r *http.Request) {
+	var since int64
+	if s := r.FormValue("since"); len(s) > 0 {
+		var err error
+		since, err = strconv.ParseInt(s, 10, 64)
+		if err != nil || since < 0 {
+			http.Error(w, "invalid since", http.StatusBadRequest)
+			return
+		}
+	}
+	_, limit, bad := formFilter(r)
+	if len(bad) > 0 {
+		http.Error(w, "invalid "+bad, http.StatusBadRequest)
+		return
+	}
+	list, err := x.hub.ReadChanges(since, limit)
+	if err != nil {
+		fail(w, "can't retrieve changes", err)
+		return
+	}
+	writeJSON(w, list)
+}
+
+func (x *Handler) schedules(w http.ResponseWriter, r *http.Request) {
+	list, err := x.hub.Schedules()
+	if err != nil {
+		fail(w, "can't retrieve schedules", err)
+		return
+	}
+	writeJSON(w, list)
+}
+
+func (x *Handler) schedule(w http.ResponseWriter, r *http.Request) {
+	s, err := x.hub.Schedule(r.FormValue("id"))
+	if err != nil {
+		fail(w, "can't retrieve schedule", err)
+		return
+	}
+	writeJSON(w, s)
+}
+
+func (x *Handler) drift(w http.ResponseWriter, r *http.Request) {
+	since, err := formTime(r, "since")
+	if err != nil {
+		http.Error(w, "invalid since", http.StatusBadRequest)
+		return
+	}
+	var tolerance int
+	if s := r.FormValue("tolerance"); len(s) > 0 {
+		tolerance, err = strconv.Atoi(s)
+		if err != nil || tolerance < 0 {
+			http.Error(w, "invalid tolerance", http.StatusBadRequest)
+			return
+		}
+	}
+	report, err := x.hub.Drift(since, time.Duration(tolerance)*time.Second)
+	if err != nil {
+		fail(w, "can't retrieve drift", err)
+		return
+	}
+	writeJSON(w, report)
+}
+
+func (x *Handler) stats(w http.ResponseWriter, r *http.Request) {
+	stats, err := x.hub.Stats()
+	if err != nil {
+		fail(w, "can't retrieve stats", err)
+		return
+	}
+	writeJSON(w, stats)
+}
+
+func (x *Handler) workers(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, x.hub.Workers())
+}
+
+func (x *Handler) config(w http.ResponseWriter, r *http.Request) {
+	cfg, err := x.hub.WorkerConfig(r.FormValue("worker"))
+	if err != nil {
+		fail(w, "can't retrieve config", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if _, err := w.Write(cfg); err != nil {
+		log.Printf("wormhttp : write config : err [%s]", err)
+	}
+}
+
+func (x *Handler) configure(w http.ResponseWriter, r *http.Request) {
+	worker := r.FormValue("worker")
+	if len(worker) < 1 {
+		http.Error(w, "invalid worker", http.StatusBadRequest)
+		return
+	}
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "can't read config", http.StatusBadRequest)
+		return
+	}
+	if err := x.hub.SetWorkerConfig(worker, b); err != nil {
+		fail(w, "can't set config", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (x *Handler) retry(w http.ResponseWriter, r *http.Request) {
+	if err := x.hub.Retry(r.FormValue("id")); err != nil {
+		fail(w, "can't retry job", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (x *Handler) cancel(w http.ResponseWriter, r *http.Request) {
+	id := r.FormValue("id")
+	if len(id) < 1 {
+		// an empty filter cancels every job.
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	n, err := x.hub.CancelWhere(worm.JobFilter{ID: id})
+	if err == nil && n < 1 {
+		err = worm.ErrConflict
+	}
+	if err != nil {
+		fail(w, "can't cancel job", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (x *Handler) trigger(w http.ResponseWriter, r *http.Request) {
+	if err := x.hub.TriggerSchedule(r.FormValue("id")); err != nil {
+		fail(w, "can't trigger schedule", err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (x *Handler) note(w http.ResponseWriter, r *http.Request) {
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "can't read note", http.StatusBadRequest)
+		return
+	}
+	if err := x.hub.Annotate(r.FormValue("id"), string(b)); err != nil {
+		fail(w, "can't annotate job", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (x *Handler) delete(w http.ResponseWriter, r *http.Request) {
+	if err := x.hub.Delete(r.FormValue("id")); err != nil {
+		fail(w, "can't delete job", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (x *Handler) unschedule(w http.ResponseWriter, r *http.Request) {
+	if err := x.hub.DeleteSchedule(r.FormValue("id")); err != nil {
+		fail(w, "can't delete schedule", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (x *Handler) purge(w http.ResponseWriter, r *http.Request) {
+	before, err := formTime(r, "before")
+	if err != nil || before.IsZero() {
+		http.Error(w, "invalid before", http.StatusBadRequest)
+		return
+	}
+	n, err := x.hub.Purge(before)
+	if err != nil {
+		fail(w, "can't purge jobs", err)
+		return
+	}
+	writeJSON(w, map[string]int{"purged": n})
+}
+
+func (x *Handler) status(w http.ResponseWriter, r *http.Request) {
+	status, err := strconv.Atoi(r.FormValue("status"))
+	if err != nil {
+		http.Error(w, "invalid status", http.StatusBadRequest)
+		return
+	}
+	if err := x.hub.SetStatus(r.FormValue("id"), status, r.FormValue("reason")); err != nil {
+		fail(w, "can't set status", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (x *Handler) statuses(w http.ResponseWriter, r *http.Request) {
+	var updates []worm.StatusUpdate
+	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+		http.Error(w, "invalid updates", http.StatusBadRequest)
+		return
+	}
+	n, err := x.hub.UpdateStatuses(updates)
+	if err != nil {
+		fail(w, "can't set statuses", err)
+		return
+	}
+	writeJSON(w, map[string]int{"updated": n})
+}
+
+// formFilter parses the job filter and limit of the request. It returns
+// the name of the first invalid parameter, if any.
+func formFilter(r *http.Request) (worm.JobFilter, int, string) {
+	filter := worm.JobFilter{
+		Worker:     r.FormValue("worker"),
+		Group:      r.FormValue("group"),
+		ExternalID: r.FormValue("external_id"),
+	}
+	var err error
+	if filter.CreatedAfter, err = formTime(r, "after"); err != nil {
+		return filter, 0, "after"
+	}
+	if filter.CreatedBefore, err = formTime(r, "before"); err != nil {
+		return filter, 0, "before"
+	}
+	limit := defaultLimit
+	if s := r.FormValue("limit"); len(s) > 0 {
+		limit, err = strconv.Atoi(s)
+		if err != nil || limit < 1 {
+			return filter, 0, "limit"
+		}
+	}
+	return filter, limit, ""
+}
+
+// formTime parses the RFC 3339 form value key, zero when empty.
+func formTime(r *http.Request, key string) (time.Time, error) {
+	s := r.FormValue(key)
+	if len(s) < 1 {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// fail writes the response of a hub error.
+func fail(w http.ResponseWriter, msg string, err error) {
+	code := http.StatusInternalServerError
+	switch err {
+	case sql.ErrNoRows, worm.ErrNotFound, worm.ErrNoLog:
+		code = http.StatusNotFound
+	case worm.ErrConflict:
+		code = http.StatusConflict
+	case worm.ErrQueueFull:
+		code = http.StatusServiceUnavailable
+	case worm.ErrSort:
+		code = http.StatusBadRequest
+	default:
+		log.Printf("wormhttp : %s : err [%s]", msg, err)
+	}
+	http.Error(w, msg, code)
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("wormhttp : encode : err [%s]", err)
+	}
+}
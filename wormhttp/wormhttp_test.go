@@ -0,0 +1,151 @@
+package wormhttp
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	worm "github.com/jimmy-go/worm.io"
+)
+
+type okDoer struct{}
+
+func (d *okDoer) Name() string {
+	return "ok"
+}
+
+func (d *okDoer) Run(data []byte, w io.Writer) (int, error) {
+	return worm.StatusOK, nil
+}
+
+// newTestHub returns a hub on a migrated temporary database.
+func newTestHub(t *testing.T) (*worm.Worm, func()) {
+	dir, err := ioutil.TempDir("", "wormhttp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := worm.New(filepath.Join(dir, "worm.db"), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ups, err := filepath.Glob("../migration/*.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(ups)
+	for _, name := range ups {
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := h.Db.Exec(string(b)); err != nil {
+			t.Fatalf("migration %s : err [%s]", name, err)
+		}
+	}
+	return h, func() {
+		if err := h.Close(); err != nil {
+			t.Errorf("close : err [%s]", err)
+		}
+		os.RemoveAll(dir)
+	}
+}
+
+func TestRoles(t *testing.T) {
+	role := func(r *http.Request) (Access, bool) {
+		switch r.Header.Get("X-Role") {
+		case "viewer":
+			return Read, true
+		case "operator":
+			return Operate, true
+		case "admin":
+			return Destroy, true
+		}
+		return 0, false
+	}
+	a := Roles(role)
+	table := []struct {
+		Role   string
+		Access Access
+		OK     bool
+	}{
+		{"viewer", Read, true},
+		{"viewer", Operate, false},
+		{"operator", Operate, true},
+		{"operator", Destroy, false},
+		{"admin", Destroy, true},
+		{"", Read, false},
+	}
+	for _, x := range table {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-Role", x.Role)
+		err := a.Authorize(r, x.Access)
+		if (err == nil) != x.OK {
+			t.Errorf("role [%s] access [%s] : expected ok [%v] got [%v]", x.Role, x.Access, x.OK, err)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	h, done := newTestHub(t)
+	defer done()
+	h.MustRegister("ok", &okDoer{})
+	jobID, err := h.Queue("ok", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	viewer := Roles(func(r *http.Request) (Access, bool) {
+		return Read, true
+	})
+	ts := httptest.NewServer(New(h, WithAuthorizer(viewer)))
+	defer ts.Close()
+
+	table := []struct {
+		Method string
+		Path   string
+		Code   int
+	}{
+		{http.MethodGet, "/job?id=" + jobID, http.StatusOK},
+		{http.MethodGet, "/job?id=missing", http.StatusNotFound},
+		{http.MethodGet, "/jobs?worker=ok&limit=10", http.StatusOK},
+		{http.MethodGet, "/jobs?after=yesterday", http.StatusBadRequest},
+		{http.MethodGet, "/logs.zip?worker=ok", http.StatusOK},
+		{http.MethodGet, "/logs.zip?limit=0", http.StatusBadRequest},
+		{http.MethodGet, "/changes?since=0", http.StatusOK},
+		{http.MethodGet, "/changes?since=x", http.StatusBadRequest},
+		{http.MethodGet, "/run?id=x", http.StatusBadRequest},
+		{http.MethodGet, "/run?id=1000", http.StatusNotFound},
+		{http.MethodGet, "/schedules", http.StatusOK},
+		{http.MethodGet, "/drift?since=2016-01-01T00:00:00Z&tolerance=60", http.StatusOK},
+		{http.MethodGet, "/drift?tolerance=-1", http.StatusBadRequest},
+		{http.MethodGet, "/schedule?id=" + jobID, http.StatusNotFound},
+		{http.MethodPost, "/unschedule?id=" + jobID, http.StatusForbidden},
+		{http.MethodPost, "/trigger?id=" + jobID, http.StatusForbidden},
+		{http.MethodGet, "/stats", http.StatusOK},
+		{http.MethodPost, "/stats", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/config?worker=ok", http.StatusOK},
+		{http.MethodPost, "/configure?worker=ok", http.StatusForbidden},
+		{http.MethodPost, "/retry?id=" + jobID, http.StatusForbidden},
+		{http.MethodPost, "/purge?before=2016-01-01T00:00:00Z", http.StatusForbidden},
+		{http.MethodPost, "/statuses", http.StatusForbidden},
+	}
+	for _, x := range table {
+		req, err := http.NewRequest(x.Method, ts.URL+x.Path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != x.Code {
+			t.Errorf("%s %s : expected [%d] got [%d]", x.Method, x.Path, x.Code, res.StatusCode)
+		}
+	}
+}
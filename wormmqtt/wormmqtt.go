@@ -0,0 +1,142 @@
+// Package wormmqtt publishes the job events of a hub to an MQTT broker, so
+// dashboards subscribed via MQTT observe job progress without polling.
+//
+//	pub := wormmqtt.New(client, wormmqtt.WithTopic("plant/worm/{worker}/{type}"))
+//	defer pub.Close()
+//	hub, err := worm.New(db, logs, worm.WithEventHandler(pub.Handle))
+//
+// Payloads are the JSON encoded worm.Event.
+package wormmqtt
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	worm "github.com/jimmy-go/worm.io"
+)
+
+// DefaultTopic is the topic template of the events.
+const DefaultTopic = "worm/{worker}/{type}"
+
+// buffer is the number of events a Publisher holds before dropping them.
+const buffer = 1024
+
+// publishTimeout bounds the wait for the broker to take an event.
+const publishTimeout = 5 * time.Second
+
+// errTimeout is logged for events the broker didn't take in time.
+var errTimeout = errors.New("wormmqtt: publish timeout")
+
+// Publisher publishes hub events without blocking the hub.
+type Publisher struct {
+	client   mqtt.Client
+	topic    string
+	qos      byte
+	retained bool
+
+	events chan worm.Event
+	done   chan struct{}
+}
+
+// Option configures a Publisher.
+type Option func(*Publisher)
+
+// WithTopic sets the topic template. {worker}, {type} and {job_id} are
+// replaced by the event fields.
+func WithTopic(template string) Option {
+	return func(p *Publisher) {
+		p.topic = template
+	}
+}
+
+// WithQoS sets the MQTT quality of service, 0 by default.
+func WithQoS(qos byte) Option {
+	return func(p *Publisher) {
+		p.qos = qos
+	}
+}
+
+// WithRetained asks the broker to keep the last event of each topic for new
+// subscribers.
+func WithRetained() Option {
+	return func(p *Publisher) {
+		p.retained = true
+	}
+}
+
+// New returns a Publisher sending the events with the connected client.
+func New(client mqtt.Client, opts ...Option) *Publisher {
+	p := &Publisher{
+		client: client,
+		topic:  DefaultTopic,
+		events: make(chan worm.Event, buffer),
+		done:   make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	go p.loop()
+	return p
+}
+
+// Handle queues the event for publication. Pass it to worm.WithEventHandler.
+// Events are dropped when the buffer is full.
+func (p *Publisher) Handle(e worm.Event) {
+	select {
+	case p.events <- e:
+	default:
+		log.Printf("wormmqtt : buffer full : dropped [%s] job id [%s]", e.Type, e.JobID)
+	}
+}
+
+// Close publishes the buffered events and stops the Publisher. Handle must
+// not be called after Close.
+func (p *Publisher) Close() error {
+	close(p.events)
+	<-p.done
+	return nil
+}
+
+// loop publishes the events.
+func (p *Publisher) loop() {
+	defer close(p.done)
+	for e := range p.events {
+		if err := p.publish(e); err != nil {
+			log.Printf("wormmqtt : publish : err [%s] job id [%s]", err, e.JobID)
+		}
+	}
+}
+
+// publish sends e and waits for the broker.
+func (p *Publisher) publish(e worm.Event) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	token := p.client.Publish(p.topicOf(e), p.qos, p.retained, b)
+	if !token.WaitTimeout(publishTimeout) {
+		return errTimeout
+	}
+	return token.Error()
+}
+
+// topicOf returns the topic of e.
+func (p *Publisher) topicOf(e worm.Event) string {
+	return strings.NewReplacer(
+		"{worker}", level(e.Worker),
+		"{type}", level(e.Type),
+		"{job_id}", level(e.JobID),
+	).Replace(p.topic)
+}
+
+// level makes s a single topic level, replacing separators and wildcards.
+func level(s string) string {
+	if len(s) < 1 {
+		return "_"
+	}
+	return strings.NewReplacer("/", "_", "+", "_", "#", "_").Replace(s)
+}
@@ -0,0 +1,65 @@
+package wormmqtt
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	worm "github.com/jimmy-go/worm.io"
+)
+
+type token struct{}
+
+func (t *token) Wait() bool                     { return true }
+func (t *token) WaitTimeout(time.Duration) bool { return true }
+func (t *token) Error() error                   { return nil }
+
+type message struct {
+	topic    string
+	qos      byte
+	retained bool
+	payload  []byte
+}
+
+// fakeClient records the published messages.
+type fakeClient struct {
+	published []message
+}
+
+func (c *fakeClient) IsConnected() bool {
+	return true
+}
+
+func (c *fakeClient) Publish(topic string, qos byte, retained bool, payload interface{}) mqtt.Token {
+	c.published = append(c.published, message{topic, qos, retained, payload.([]byte)})
+	return &token{}
+}
+
+func TestPublisher(t *testing.T) {
+	c := &fakeClient{}
+	p := New(c, WithTopic("site/{worker}/{type}/{job_id}"), WithQoS(1))
+	p.Handle(worm.Event{Type: worm.EventStarted, Worker: "mail/er", JobID: "job-1"})
+	p.Handle(worm.Event{Type: worm.EventQuarantine, Worker: "mailer"})
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	topics := []string{"site/mail_er/started/job-1", "site/mailer/quarantine/_"}
+	if len(c.published) != len(topics) {
+		t.Fatalf("expected [%d] messages got [%d]", len(topics), len(c.published))
+	}
+	for i, topic := range topics {
+		m := c.published[i]
+		if m.topic != topic || m.qos != 1 || m.retained {
+			t.Errorf("message %d : expected topic [%s] got [%+v]", i, topic, m)
+		}
+	}
+	var e worm.Event
+	if err := json.Unmarshal(c.published[0].payload, &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Type != worm.EventStarted || e.JobID != "job-1" {
+		t.Errorf("payload : got [%+v]", e)
+	}
+}
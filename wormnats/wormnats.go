@@ -0,0 +1,104 @@
+// Package wormnats enqueues NATS messages as worm jobs, so services already
+// publishing to NATS feed the queue without HTTP calls.
+//
+//	b, err := wormnats.New(nc, hub, map[string]string{
+//		"orders.created": "invoice",
+//		"mail.>":         "mailer",
+//	}, wormnats.WithQueueGroup("worm"))
+//	defer b.Close()
+//
+// The message data is the job data. Requests, messages with a reply subject,
+// get the job ID or an error reply.
+package wormnats
+
+import (
+	"log"
+	"sort"
+
+	worm "github.com/jimmy-go/worm.io"
+	"github.com/nats-io/nats.go"
+)
+
+// errorPrefix starts the replies of messages that were not queued.
+const errorPrefix = "error: "
+
+// Bridge subscribes to NATS subjects and queues their messages.
+type Bridge struct {
+	hub   worm.Enqueuer
+	queue string
+	subs  []*nats.Subscription
+}
+
+// Option configures a Bridge.
+type Option func(*Bridge)
+
+// WithQueueGroup subscribes in the queue group, so the bridges of a fleet
+// queue each message once.
+func WithQueueGroup(name string) Option {
+	return func(b *Bridge) {
+		b.queue = name
+	}
+}
+
+// New subscribes to the subjects of routes, which map a subject, wildcards
+// allowed, to the worker that runs its messages.
+func New(conn *nats.Conn, hub worm.Enqueuer, routes map[string]string, opts ...Option) (*Bridge, error) {
+	b := &Bridge{hub: hub}
+	for _, opt := range opts {
+		opt(b)
+	}
+	subjects := make([]string, 0, len(routes))
+	for subject := range routes {
+		subjects = append(subjects, subject)
+	}
+	sort.Strings(subjects)
+	for _, subject := range subjects {
+		workerName := routes[subject]
+		cb := func(m *nats.Msg) {
+			b.enqueue(workerName, m)
+		}
+		var sub *nats.Subscription
+		var err error
+		if len(b.queue) > 0 {
+			sub, err = conn.QueueSubscribe(subject, b.queue, cb)
+		} else {
+			sub, err = conn.Subscribe(subject, cb)
+		}
+		if err != nil {
+			b.Close()
+			return nil, err
+		}
+		b.subs = append(b.subs, sub)
+	}
+	return b, nil
+}
+
+// enqueue queues the message for the worker and replies to requests.
+func (b *Bridge) enqueue(workerName string, m *nats.Msg) {
+	jobID, err := b.hub.Queue(workerName, m.Data)
+	if err != nil {
+		log.Printf("wormnats : queue : err [%s] subject [%s] worker [%s]", err, m.Subject, workerName)
+	}
+	if len(m.Reply) < 1 {
+		return
+	}
+	reply := []byte(jobID)
+	if err != nil {
+		reply = []byte(errorPrefix + err.Error())
+	}
+	if err := m.Respond(reply); err != nil {
+		log.Printf("wormnats : respond : err [%s] subject [%s]", err, m.Subject)
+	}
+}
+
+// Close drains the subscriptions, queueing the messages already received.
+func (b *Bridge) Close() error {
+	var first error
+	for _, sub := range b.subs {
+		if err := sub.Drain(); err != nil && first == nil {
+			first = err
+		}
+	}
+	b.subs = nil
+	return first
+}
@@ -0,0 +1,34 @@
+package wormnats
+
+import (
+	"io"
+	"testing"
+
+	worm "github.com/jimmy-go/worm.io"
+	"github.com/jimmy-go/worm.io/wormtest"
+	"github.com/nats-io/nats.go"
+)
+
+type mailer struct{}
+
+func (d *mailer) Name() string {
+	return "mailer"
+}
+
+func (d *mailer) Run(data []byte, w io.Writer) (int, error) {
+	return worm.StatusOK, nil
+}
+
+func TestEnqueue(t *testing.T) {
+	hub := wormtest.New()
+	hub.MustRegister("mailer", &mailer{})
+	b := &Bridge{hub: hub}
+
+	b.enqueue("mailer", &nats.Msg{Subject: "mail.welcome", Data: []byte(`{"to":"a@b.c"}`)})
+	hub.AssertEnqueued(t, "mailer", wormtest.JSONEq(`{"to":"a@b.c"}`))
+
+	b.enqueue("missing", &nats.Msg{Subject: "other", Data: []byte("x")})
+	if n := len(hub.Jobs("")); n != 1 {
+		t.Fatalf("expected [1] job got [%d]", n)
+	}
+}
@@ -0,0 +1,202 @@
+// Package wormsqs moves jobs between worm and AWS, for deployments migrating
+// off SQS based workers gradually.
+//
+// A Source pulls messages from an SQS queue and queues them as jobs:
+//
+//	src := wormsqs.NewSource(sqs.New(sess), queueURL, hub, "invoice")
+//	go src.Run(ctx)
+//
+// A Sink publishes the job events of the hub to an SQS queue or SNS topic:
+//
+//	sink := wormsqs.NewSNSSink(sns.New(sess), topicARN)
+//	defer sink.Close()
+//	hub, err := worm.New(db, logs, worm.WithEventHandler(sink.Handle))
+package wormsqs
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	worm "github.com/jimmy-go/worm.io"
+)
+
+// WorkerAttribute is the message attribute that overrides the worker of the
+// Source.
+const WorkerAttribute = "worker"
+
+// SQSAPI is the part of the SQS client used here, implemented by *sqs.SQS.
+type SQSAPI interface {
+	ReceiveMessageWithContext(aws.Context, *sqs.ReceiveMessageInput, ...request.Option) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessageWithContext(aws.Context, *sqs.DeleteMessageInput, ...request.Option) (*sqs.DeleteMessageOutput, error)
+	SendMessageWithContext(aws.Context, *sqs.SendMessageInput, ...request.Option) (*sqs.SendMessageOutput, error)
+}
+
+// SNSAPI is the part of the SNS client used here, implemented by *sns.SNS.
+type SNSAPI interface {
+	PublishWithContext(aws.Context, *sns.PublishInput, ...request.Option) (*sns.PublishOutput, error)
+}
+
+// Receive settings of the Source.
+const (
+	// waitSeconds long polls the queue.
+	waitSeconds = 20
+	// maxMessages is the SQS limit of messages per receive.
+	maxMessages = 10
+	// errorBackoff is the wait after a failed receive.
+	errorBackoff = 5 * time.Second
+)
+
+// Source queues the messages of an SQS queue as jobs.
+type Source struct {
+	api        SQSAPI
+	queueURL   string
+	hub        worm.Enqueuer
+	workerName string
+}
+
+// NewSource returns a Source queueing the messages of queueURL for the
+// worker, unless the message sets WorkerAttribute.
+func NewSource(api SQSAPI, queueURL string, hub worm.Enqueuer, workerName string) *Source {
+	return &Source{
+		api:        api,
+		queueURL:   queueURL,
+		hub:        hub,
+		workerName: workerName,
+	}
+}
+
+// Run pulls messages until ctx is done. A message is deleted from the queue
+// only after its job is stored; messages that fail to queue are received
+// again after the visibility timeout. The message ID is the external ID of
+// the job.
+func (s *Source) Run(ctx context.Context) error {
+	for {
+		err := s.poll(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			log.Printf("wormsqs : receive : err [%s] queue [%s]", err, s.queueURL)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(errorBackoff):
+			}
+		}
+	}
+}
+
+// poll receives one batch of messages and queues them.
+func (s *Source) poll(ctx context.Context) error {
+	out, err := s.api.ReceiveMessageWithContext(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:              aws.String(s.queueURL),
+		MaxNumberOfMessages:   aws.Int64(maxMessages),
+		WaitTimeSeconds:       aws.Int64(waitSeconds),
+		MessageAttributeNames: []*string{aws.String(WorkerAttribute)},
+	})
+	if err != nil {
+		return err
+	}
+	for _, m := range out.Messages {
+		workerName := s.workerName
+		if v, ok := m.MessageAttributes[WorkerAttribute]; ok && v != nil {
+			workerName = aws.StringValue(v.StringValue)
+		}
+		msgID := aws.StringValue(m.MessageId)
+		_, err := s.hub.Queue(workerName, []byte(aws.StringValue(m.Body)), worm.ExternalID(msgID))
+		if err != nil {
+			log.Printf("wormsqs : queue : err [%s] message id [%s] worker [%s]", err, msgID, workerName)
+			continue
+		}
+		_, err = s.api.DeleteMessageWithContext(ctx, &sqs.DeleteMessageInput{
+			QueueUrl:      aws.String(s.queueURL),
+			ReceiptHandle: m.ReceiptHandle,
+		})
+		if err != nil {
+			// the message comes back, the job has its ID as external ID.
+			log.Printf("wormsqs : delete : err [%s] message id [%s]", err, msgID)
+		}
+	}
+	return nil
+}
+
+// sinkBuffer is the number of events a Sink holds before dropping them.
+const sinkBuffer = 1024
+
+// Sink publishes hub events as JSON messages without blocking the hub.
+type Sink struct {
+	publish func(ctx context.Context, body string) error
+	events  chan worm.Event
+	done    chan struct{}
+}
+
+// NewSink returns a Sink sending the events to the SQS queue.
+func NewSink(api SQSAPI, queueURL string) *Sink {
+	return newSink(func(ctx context.Context, body string) error {
+		_, err := api.SendMessageWithContext(ctx, &sqs.SendMessageInput{
+			QueueUrl:    aws.String(queueURL),
+			MessageBody: aws.String(body),
+		})
+		return err
+	})
+}
+
+// NewSNSSink returns a Sink publishing the events to the SNS topic.
+func NewSNSSink(api SNSAPI, topicARN string) *Sink {
+	return newSink(func(ctx context.Context, body string) error {
+		_, err := api.PublishWithContext(ctx, &sns.PublishInput{
+			TopicArn: aws.String(topicARN),
+			Message:  aws.String(body),
+		})
+		return err
+	})
+}
+
+func newSink(publish func(ctx context.Context, body string) error) *Sink {
+	s := &Sink{
+		publish: publish,
+		events:  make(chan worm.Event, sinkBuffer),
+		done:    make(chan struct{}),
+	}
+	go s.loop()
+	return s
+}
+
+// Handle queues the event for publication. Pass it to worm.WithEventHandler.
+// Events are dropped when the buffer is full.
+func (s *Sink) Handle(e worm.Event) {
+	select {
+	case s.events <- e:
+	default:
+		log.Printf("wormsqs : sink : buffer full : dropped [%s] job id [%s]", e.Type, e.JobID)
+	}
+}
+
+// Close publishes the buffered events and stops the Sink. Handle must not be
+// called after Close.
+func (s *Sink) Close() error {
+	close(s.events)
+	<-s.done
+	return nil
+}
+
+// loop publishes the events.
+func (s *Sink) loop() {
+	defer close(s.done)
+	for e := range s.events {
+		b, err := json.Marshal(e)
+		if err != nil {
+			log.Printf("wormsqs : sink : marshal : err [%s]", err)
+			continue
+		}
+		if err := s.publish(context.Background(), string(b)); err != nil {
+			log.Printf("wormsqs : sink : publish : err [%s] job id [%s]", err, e.JobID)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package wormsqs
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	worm "github.com/jimmy-go/worm.io"
+	"github.com/jimmy-go/worm.io/wormtest"
+)
+
+type invoice struct{}
+
+func (d *invoice) Name() string {
+	return "invoice"
+}
+
+func (d *invoice) Run(data []byte, w io.Writer) (int, error) {
+	return worm.StatusOK, nil
+}
+
+// fakeSQS returns messages once and records deletes and sends.
+type fakeSQS struct {
+	messages []*sqs.Message
+	deleted  []string
+	sent     []string
+}
+
+func (f *fakeSQS) ReceiveMessageWithContext(ctx aws.Context, in *sqs.ReceiveMessageInput, opts ...request.Option) (*sqs.ReceiveMessageOutput, error) {
+	out := &sqs.ReceiveMessageOutput{Messages: f.messages}
+	f.messages = nil
+	return out, nil
+}
+
+func (f *fakeSQS) DeleteMessageWithContext(ctx aws.Context, in *sqs.DeleteMessageInput, opts ...request.Option) (*sqs.DeleteMessageOutput, error) {
+	f.deleted = append(f.deleted, aws.StringValue(in.ReceiptHandle))
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func (f *fakeSQS) SendMessageWithContext(ctx aws.Context, in *sqs.SendMessageInput, opts ...request.Option) (*sqs.SendMessageOutput, error) {
+	f.sent = append(f.sent, aws.StringValue(in.MessageBody))
+	return &sqs.SendMessageOutput{}, nil
+}
+
+func message(id, body, workerName string) *sqs.Message {
+	m := &sqs.Message{
+		MessageId:     aws.String(id),
+		ReceiptHandle: aws.String("rh-" + id),
+		Body:          aws.String(body),
+	}
+	if len(workerName) > 0 {
+		m.MessageAttributes = map[string]*sqs.MessageAttributeValue{
+			WorkerAttribute: {DataType: aws.String("String"), StringValue: aws.String(workerName)},
+		}
+	}
+	return m
+}
+
+func TestSource(t *testing.T) {
+	hub := wormtest.New()
+	hub.MustRegister("invoice", &invoice{})
+	api := &fakeSQS{messages: []*sqs.Message{
+		message("1", `{"order":1}`, ""),
+		message("2", `{"order":2}`, "missing"),
+	}}
+	s := NewSource(api, "https://sqs/queue", hub, "invoice")
+	if err := s.poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	hub.AssertEnqueued(t, "invoice", wormtest.JSONEq(`{"order":1}`))
+	// messages that fail to queue stay in SQS.
+	if len(api.deleted) != 1 || api.deleted[0] != "rh-1" {
+		t.Fatalf("expected only rh-1 deleted got [%v]", api.deleted)
+	}
+}
+
+func TestSink(t *testing.T) {
+	api := &fakeSQS{}
+	sink := NewSink(api, "https://sqs/events")
+	sink.Handle(worm.Event{Type: worm.EventFailed, JobID: "job-1", Status: 2})
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(api.sent) != 1 {
+		t.Fatalf("expected [1] message got [%d]", len(api.sent))
+	}
+	var e worm.Event
+	if err := json.Unmarshal([]byte(api.sent[0]), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Type != worm.EventFailed || e.JobID != "job-1" {
+		t.Fatalf("got [%+v]", e)
+	}
+}
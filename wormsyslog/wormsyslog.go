@@ -0,0 +1,220 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+// Package wormsyslog forwards the log lines of worm jobs to syslog or to
+// systemd-journald, with the job identity as structured fields, for
+// pipelines that only ingest syslog.
+//
+//	sink, err := wormsyslog.NewJournal("billing")
+//	hub, err := worm.New(dsn, logDir, worm.WithLogSink(sink))
+//
+// Lines starting with "ERROR: ", the failures worm writes to the log, are
+// sent with the error priority.
+package wormsyslog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log/syslog"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	worm "github.com/jimmy-go/worm.io"
+)
+
+// errorPrefix starts the lines of job failures.
+const errorPrefix = "ERROR: "
+
+// Syslog is a worm.LogSink writing each log line of the jobs to syslog,
+// preceded by the job fields in RFC 5424 structured data syntax:
+//
+//	[worm job_id="..." worker="..." attempt="1" meta.tenant="acme"] line
+type Syslog struct {
+	w *syslog.Writer
+}
+
+// NewSyslog connects to the syslog daemon at raddr on network, the local
+// daemon when both are empty, logging with tag at priority.
+func NewSyslog(network, raddr string, priority syslog.Priority, tag string) (*Syslog, error) {
+	w, err := syslog.Dial(network, raddr, priority, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &Syslog{w: w}, nil
+}
+
+// Open implements worm.LogSink.
+func (s *Syslog) Open(job *worm.JobInfo) (io.WriteCloser, error) {
+	var b strings.Builder
+	b.WriteString("[worm")
+	for _, f := range fields(job) {
+		b.WriteString(" " + sdName(f.key) + `="` + sdEscape(f.value) + `"`)
+	}
+	b.WriteString("] ")
+	sd := b.String()
+	return &lines{send: func(line string) error {
+		if strings.HasPrefix(line, errorPrefix) {
+			return s.w.Err(sd + line)
+		}
+		return s.w.Info(sd + line)
+	}}, nil
+}
+
+// Close closes the connection to the syslog daemon.
+func (s *Syslog) Close() error {
+	return s.w.Close()
+}
+
+// sdName replaces the characters not allowed in a structured data parameter
+// name.
+func sdName(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// sdEscape escapes a structured data parameter value.
+func sdEscape(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(v)
+}
+
+// journalSocket is the native protocol socket of journald.
+var journalSocket = "/run/systemd/journal/socket"
+
+// journal priorities.
+const (
+	priorityErr  = 3
+	priorityInfo = 6
+)
+
+// Journal is a worm.LogSink sending each log line of the jobs to
+// systemd-journald with the job fields as journal fields: WORM_JOB_ID,
+// WORM_WORKER, WORM_ATTEMPT and WORM_META_ followed by the upper case
+// metadata key.
+type Journal struct {
+	conn       *net.UnixConn
+	identifier string
+}
+
+// NewJournal connects to the local journald. Entries carry identifier as
+// SYSLOG_IDENTIFIER.
+func NewJournal(identifier string) (*Journal, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journalSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{conn: conn, identifier: identifier}, nil
+}
+
+// Open implements worm.LogSink.
+func (j *Journal) Open(job *worm.JobInfo) (io.WriteCloser, error) {
+	var common bytes.Buffer
+	writeField(&common, "SYSLOG_IDENTIFIER", j.identifier)
+	for _, f := range fields(job) {
+		writeField(&common, "WORM_"+fieldName(f.key), f.value)
+	}
+	return &lines{send: func(line string) error {
+		priority := priorityInfo
+		if strings.HasPrefix(line, errorPrefix) {
+			priority = priorityErr
+		}
+		var b bytes.Buffer
+		writeField(&b, "MESSAGE", line)
+		writeField(&b, "PRIORITY", strconv.Itoa(priority))
+		b.Write(common.Bytes())
+		_, err := j.conn.Write(b.Bytes())
+		return err
+	}}, nil
+}
+
+// Close closes the connection to journald.
+func (j *Journal) Close() error {
+	return j.conn.Close()
+}
+
+// writeField writes a field in the journal native protocol. Values with
+// new lines use the binary form.
+func writeField(b *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		b.WriteString(name + "=" + value + "\n")
+		return
+	}
+	b.WriteString(name + "\n")
+	binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value + "\n")
+}
+
+// field is a structured field of the job.
+type field struct {
+	key, value string
+}
+
+// fields returns the fields of job, metadata sorted by key.
+func fields(job *worm.JobInfo) []field {
+	list := []field{
+		{"job_id", job.ID},
+		{"worker", job.Worker},
+		{"attempt", strconv.Itoa(job.Attempt)},
+	}
+	keys := make([]string, 0, len(job.Metadata))
+	for k := range job.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		list = append(list, field{"meta." + k, job.Metadata[k]})
+	}
+	return list
+}
+
+// fieldName maps key to the journal field name charset, A-Z, 0-9 and _.
+func fieldName(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+// lines splits the log output of a run in lines for send.
+type lines struct {
+	send    func(line string) error
+	partial []byte
+}
+
+// Write implements io.Writer.
+func (w *lines) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := string(w.partial[:i])
+		w.partial = w.partial[i+1:]
+		if err := w.send(line); err != nil {
+			return 0, fmt.Errorf("wormsyslog: %s", err)
+		}
+	}
+}
+
+// Close implements io.Closer, sending the last line if not ended.
+func (w *lines) Close() error {
+	if len(w.partial) < 1 {
+		return nil
+	}
+	line := string(w.partial)
+	w.partial = nil
+	return w.send(line)
+}
@@ -0,0 +1,113 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package wormsyslog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"log/syslog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	worm "github.com/jimmy-go/worm.io"
+)
+
+var job = &worm.JobInfo{
+	ID:       "j1",
+	Worker:   "billing",
+	Attempt:  2,
+	Metadata: worm.JobMetadata{"tenant": "ac\"me", "source-request": "r1"},
+}
+
+func TestSyslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	s, err := NewSyslog("udp", conn.LocalAddr().String(), syslog.LOG_INFO|syslog.LOG_DAEMON, "worm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	w, err := s.Open(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worm.Printf(w, "charged")
+	w.Write([]byte("ERROR: boom"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	sd := `[worm job_id="j1" worker="billing" attempt="2" meta.source-request="r1" meta.tenant="ac\"me"] `
+	table := []struct {
+		pri  string
+		line string
+	}{
+		{"<30>", "charged"},
+		{"<27>", "ERROR: boom"},
+	}
+	buf := make([]byte, 2048)
+	for _, x := range table {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := string(buf[:n])
+		if !strings.HasPrefix(msg, x.pri) || !strings.HasSuffix(strings.TrimSpace(msg), sd+x.line) {
+			t.Errorf("expected [%s...%s%s] got [%s]", x.pri, sd, x.line, msg)
+		}
+	}
+}
+
+func TestJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wormsyslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	journalSocket = filepath.Join(dir, "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: journalSocket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	j, err := NewJournal("billing-svc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	w, err := j.Open(&worm.JobInfo{ID: "j1", Worker: "billing", Attempt: 1,
+		Metadata: worm.JobMetadata{"note": "a\nb"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	worm.Printf(w, "ERROR: boom")
+
+	var note bytes.Buffer
+	note.WriteString("WORM_META_NOTE\n")
+	binary.Write(&note, binary.LittleEndian, uint64(3))
+	note.WriteString("a\nb\n")
+	expect := "MESSAGE=ERROR: boom\nPRIORITY=3\nSYSLOG_IDENTIFIER=billing-svc\n" +
+		"WORM_JOB_ID=j1\nWORM_WORKER=billing\nWORM_ATTEMPT=1\n" + note.String()
+
+	buf := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf[:n]) != expect {
+		t.Fatalf("expected [%q] got [%q]", expect, buf[:n])
+	}
+}
@@ -0,0 +1,229 @@
+// Package wormtest contains an in-memory fake of the worm hub and test
+// assertions, so application tests don't need SQLite files and sleeps.
+//
+// Application code should depend on worm.Enqueuer, tests pass a *Hub:
+//
+//	hub := wormtest.New()
+//	hub.MustRegister("mailer", &Mailer{})
+//	app := NewApp(hub)
+//	app.Signup("a@b.c")
+//	hub.AssertEnqueued(t, "mailer", wormtest.JSONEq(`{"to":"a@b.c"}`))
+//	hub.Perform()
+package wormtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	worm "github.com/jimmy-go/worm.io"
+)
+
+// Job is a job queued on the fake hub.
+type Job struct {
+	ID     string
+	Worker string
+	Data   []byte
+	// Cron is empty for jobs queued with Queue.
+	Cron string
+	// Performed is true once Perform ran the job.
+	Performed bool
+	Status    int
+	Err       error
+	Log       bytes.Buffer
+}
+
+// Hub is an in-memory fake of worm.Worm. Job and worker options are
+// accepted but ignored.
+type Hub struct {
+	mu      sync.Mutex
+	workers map[string]worm.Doer
+	jobs    []*Job
+	seq     int
+}
+
+var _ worm.Enqueuer = (*Hub)(nil)
+
+// New returns an empty fake hub.
+func New() *Hub {
+	return &Hub{
+		workers: make(map[string]worm.Doer),
+	}
+}
+
+// Register registers the worker.
+func (h *Hub) Register(workerName string, doer worm.Doer, opts ...worm.WorkerOption) error {
+	if doer == nil {
+		return errors.New("nil worker")
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.workers[workerName]; ok {
+		return errors.New("worm: worker already registered")
+	}
+	h.workers[workerName] = doer
+	return nil
+}
+
+// MustRegister registers the worker or panics.
+func (h *Hub) MustRegister(workerName string, doer worm.Doer, opts ...worm.WorkerOption) {
+	if err := h.Register(workerName, doer, opts...); err != nil {
+		panic(err)
+	}
+}
+
+// Queue implements worm.Enqueuer.
+func (h *Hub) Queue(workerName string, data []byte, opts ...worm.JobOption) (string, error) {
+	return h.add(workerName, data, "")
+}
+
+// Sched implements worm.Enqueuer.
+func (h *Hub) Sched(workerName string, data []byte, cronformat string, opts ...worm.JobOption) (string, error) {
+	if len(cronformat) < 1 {
+		return "", errors.New("wormtest: empty cron format")
+	}
+	return h.add(workerName, data, cronformat)
+}
+
+// add stores a new job.
+func (h *Hub) add(workerName string, data []byte, cronformat string) (string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.workers[workerName]; !ok {
+		return "", errors.New("worm: doer not found")
+	}
+	h.seq++
+	job := &Job{
+		ID:     fmt.Sprintf("job-%d", h.seq),
+		Worker: workerName,
+		Data:   append([]byte(nil), data...),
+		Cron:   cronformat,
+		Status: worm.StatusStart,
+	}
+	h.jobs = append(h.jobs, job)
+	return job.ID, nil
+}
+
+// Jobs returns the jobs of the worker in queue order, all jobs for an empty
+// worker name.
+func (h *Hub) Jobs(workerName string) []*Job {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var list []*Job
+	for _, job := range h.jobs {
+		if len(workerName) < 1 || job.Worker == workerName {
+			list = append(list, job)
+		}
+	}
+	return list
+}
+
+// Perform runs synchronously the queued jobs not performed yet, including
+// the ones they queue, until none is left. Scheduled jobs are not run.
+// Returns the number of jobs performed.
+func (h *Hub) Perform() int {
+	var n int
+	for {
+		job, doer := h.next()
+		if job == nil {
+			return n
+		}
+		job.Status, job.Err = doer.Run(job.Data, &job.Log)
+		n++
+	}
+}
+
+// next marks as performed and returns the first pending queued job.
+func (h *Hub) next() (*Job, worm.Doer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, job := range h.jobs {
+		if job.Performed || len(job.Cron) > 0 {
+			continue
+		}
+		job.Performed = true
+		return job, h.workers[job.Worker]
+	}
+	return nil, nil
+}
+
+// Reset drops all jobs.
+func (h *Hub) Reset() {
+	h.mu.Lock()
+	h.jobs = nil
+	h.mu.Unlock()
+}
+
+// Matcher matches job data.
+type Matcher func(data []byte) bool
+
+// Any matches any data.
+func Any() Matcher {
+	return func([]byte) bool {
+		return true
+	}
+}
+
+// Equal matches data equal to b.
+func Equal(b []byte) Matcher {
+	return func(data []byte) bool {
+		return bytes.Equal(data, b)
+	}
+}
+
+// JSONEq matches data that is JSON equivalent to s.
+func JSONEq(s string) Matcher {
+	var want interface{}
+	if err := json.Unmarshal([]byte(s), &want); err != nil {
+		panic(fmt.Sprintf("wormtest: JSONEq: %s", err))
+	}
+	return func(data []byte) bool {
+		var got interface{}
+		if err := json.Unmarshal(data, &got); err != nil {
+			return false
+		}
+		return reflect.DeepEqual(got, want)
+	}
+}
+
+// AssertEnqueued fails the test unless a job of the worker matching match
+// was queued or scheduled.
+func (h *Hub) AssertEnqueued(t testing.TB, workerName string, match Matcher) {
+	t.Helper()
+	for _, job := range h.Jobs(workerName) {
+		if match(job.Data) {
+			return
+		}
+	}
+	t.Errorf("wormtest: no job enqueued for worker %q matching, got %s", workerName, h.dump(workerName))
+}
+
+// AssertNotEnqueued fails the test if a job of the worker matching match was
+// queued or scheduled.
+func (h *Hub) AssertNotEnqueued(t testing.TB, workerName string, match Matcher) {
+	t.Helper()
+	for _, job := range h.Jobs(workerName) {
+		if match(job.Data) {
+			t.Errorf("wormtest: unexpected job %s enqueued for worker %q: %s", job.ID, workerName, job.Data)
+			return
+		}
+	}
+}
+
+// dump describes the jobs of the worker for failure messages.
+func (h *Hub) dump(workerName string) string {
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i, job := range h.Jobs(workerName) {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "%s %s", job.ID, job.Data)
+	}
+	buf.WriteString("]")
+	return buf.String()
+}
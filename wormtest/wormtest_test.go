@@ -0,0 +1,62 @@
+package wormtest
+
+import (
+	"io"
+	"testing"
+
+	worm "github.com/jimmy-go/worm.io"
+)
+
+// chainDoer queues a follow up job on the hub.
+type chainDoer struct {
+	hub  *Hub
+	next string
+}
+
+func (d *chainDoer) Name() string {
+	return "chain"
+}
+
+func (d *chainDoer) Run(data []byte, w io.Writer) (int, error) {
+	worm.Printf(w, "running %s", data)
+	if len(d.next) > 0 {
+		if _, err := d.hub.Queue(d.next, data); err != nil {
+			return 1, err
+		}
+	}
+	return worm.StatusOK, nil
+}
+
+func TestHub(t *testing.T) {
+	hub := New()
+	hub.MustRegister("first", &chainDoer{hub: hub, next: "second"})
+	hub.MustRegister("second", &chainDoer{hub: hub})
+
+	if _, err := hub.Queue("missing", nil); err == nil {
+		t.Fatalf("queue missing worker : expected error")
+	}
+	if _, err := hub.Queue("first", []byte(`{"a": 1}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hub.Sched("first", []byte(`{"a": 2}`), "0 0 * * * *"); err != nil {
+		t.Fatal(err)
+	}
+	hub.AssertEnqueued(t, "first", JSONEq(`{"a":1}`))
+	hub.AssertNotEnqueued(t, "second", Any())
+
+	if n := hub.Perform(); n != 2 {
+		t.Fatalf("perform : expected 2 jobs got [%d]", n)
+	}
+	hub.AssertEnqueued(t, "second", Equal([]byte(`{"a": 1}`)))
+	for _, job := range hub.Jobs("") {
+		if len(job.Cron) > 0 {
+			if job.Performed {
+				t.Errorf("scheduled job performed")
+			}
+			continue
+		}
+		if !job.Performed || job.Status != worm.StatusOK || job.Log.Len() < 1 {
+			t.Errorf("job %s : got [%+v]", job.ID, job)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Package wormwasm runs worm jobs compiled to WebAssembly (WASI) inside a
+// wazero sandbox. The job data is the module stdin and its stdout and stderr
+// are written to the job log.
+//
+// Experimental: the API may change.
+package wormwasm
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+
+	worm "github.com/jimmy-go/worm.io"
+)
+
+// NotStarted is the status of a job whose module failed to instantiate.
+const NotStarted = 127
+
+// Doer runs a WASI module for every job. It implements worm.Doer.
+type Doer struct {
+	name     string
+	args     []string
+	timeout  time.Duration
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+}
+
+// New compiles module and returns a Doer named workerName. args are passed
+// to the module, timeout limits each run when greater than zero. Close must
+// be called to release the runtime.
+func New(workerName string, module []byte, timeout time.Duration, args ...string) (*Doer, error) {
+	ctx := context.Background()
+	r := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCloseOnContextDone(true))
+	wasi_snapshot_preview1.MustInstantiate(ctx, r)
+	compiled, err := r.CompileModule(ctx, module)
+	if err != nil {
+		r.Close(ctx)
+		return nil, err
+	}
+	return &Doer{
+		name:     workerName,
+		args:     append([]string{workerName}, args...),
+		timeout:  timeout,
+		runtime:  r,
+		compiled: compiled,
+	}, nil
+}
+
+// Name implements worm.Doer.
+func (d *Doer) Name() string {
+	return d.name
+}
+
+// Run implements worm.Doer. The module exit code is the job status.
+func (d *Doer) Run(data []byte, logOutput io.Writer) (int, error) {
+	ctx := context.Background()
+	if d.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.timeout)
+		defer cancel()
+	}
+
+	// anonymous instances so runs don't collide on the module name.
+	config := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(d.args...).
+		WithStdin(bytes.NewReader(data)).
+		WithStdout(logOutput).
+		WithStderr(logOutput)
+	mod, err := d.runtime.InstantiateModule(ctx, d.compiled, config)
+	if mod != nil {
+		defer mod.Close(ctx)
+	}
+	if err == nil {
+		return worm.StatusOK, nil
+	}
+	if exitErr, ok := err.(*sys.ExitError); ok {
+		code := int(exitErr.ExitCode())
+		if code == 0 {
+			return worm.StatusOK, nil
+		}
+		return code, err
+	}
+	return NotStarted, err
+}
+
+// Close releases the runtime.
+func (d *Doer) Close() error {
+	return d.runtime.Close(context.Background())
+}